@@ -3,7 +3,9 @@ package apperrors
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"time"
 
 	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/logger/metrics"
@@ -26,6 +28,10 @@ func AuthenticationError(ctx interface{}, message string){
 	server_response.Responder.Respond(ctx, http.StatusUnauthorized, message, nil, nil)
 }
 
+func ForbiddenError(ctx interface{}, message string){
+	server_response.Responder.Respond(ctx, http.StatusForbidden, message, nil, nil)
+}
+
 func ExternalDependencyError(ctx interface{}, serviceName string, statusCode string, err error) {
 	logger.Error(err, logger.LoggerOptions{
 		Key: fmt.Sprintf("error with %s. status code %s", serviceName, statusCode),
@@ -68,3 +74,25 @@ func UnsupportedUserAgent(ctx interface{}){
 func ClientError(ctx interface{}, msg string, errs []error){
 	server_response.Responder.Respond(ctx, http.StatusBadRequest, msg, nil, errs)
 }
+
+// TwoFactorRequired tells the client to ask the user for a code from their authenticator app and retry.
+func TwoFactorRequired(ctx interface{}, msg string){
+	server_response.Responder.Respond(ctx, http.StatusForbidden, msg, map[string]any{
+		"twoFactorRequired": true,
+	}, nil)
+}
+
+// OTPRequired tells the client an otp has been sent to the user and to retry with it.
+func OTPRequired(ctx interface{}, msg string){
+	server_response.Responder.Respond(ctx, http.StatusForbidden, msg, map[string]any{
+		"otpRequired": true,
+	}, nil)
+}
+
+// TooManyAttempts tells the client how long to wait before trying a password or code again.
+func TooManyAttempts(ctx interface{}, msg string, retryAfter time.Duration, locked bool){
+	server_response.Responder.Respond(ctx, http.StatusTooManyRequests, msg, map[string]any{
+		"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+		"locked": locked,
+	}, nil)
+}
@@ -1,14 +1,75 @@
 package constants
 
+import "time"
+
 const (
 	SUPPORT_EMAIL string = "support@kego.com"
 	BUSINESS_WALLET_LIMIT int = 11
 	MAX_TRANSACTION_PIN_TRIES  int = 3
-	INTERNATIONAL_TRANSACTION_FEE_RATE float32 = 0.01
-	INTERNATIONAL_PROCESSOR_FEE_RATE float32 = 0.005
-	LOCAL_TRANSACTION_FEE_VAT float32 = 0.075
-	LOCAL_TRANSACTION_FEE_RATE float32 = 0.5
-	LOCAL_PROCESSOR_FEE_LT_5000 float32 = 10.00
-	LOCAL_PROCESSOR_FEE_LT_50000 float32 = 25.00
-	LOCAL_PROCESSOR_FEE_GT_50000 float32 = 50.00
+	TRANSACTION_PIN_RESET_COOLING_OFF time.Duration = 24 * time.Hour
+	FACE_MATCH_MIN_CONFIDENCE float32 = 80
+	DEFAULT_PRICING_PLAN_CODE string = "standard"
+	INTERNATIONAL_PAYMENT_QUOTE_TTL time.Duration = 2 * time.Minute
+	EXCHANGE_RATE_REFRESH_INTERVAL time.Duration = 5 * time.Minute
+	EXCHANGE_RATE_MAX_STALENESS time.Duration = 30 * time.Minute
+	EXCHANGE_RATE_HISTORY_MAX_RANGE time.Duration = 31 * 24 * time.Hour
+	RECONCILIATION_MAX_PERIOD time.Duration = 31 * 24 * time.Hour
+	OUTBOX_POLL_INTERVAL time.Duration = 2 * time.Second
+	OUTBOX_LOCK_DURATION time.Duration = time.Minute
+	OUTBOX_BASE_BACKOFF time.Duration = 10 * time.Second
+	OUTBOX_MAX_BACKOFF time.Duration = time.Hour
+	OUTBOX_MAX_ATTEMPTS int = 8
+	OUTBOX_BATCH_SIZE int64 = 50
+	NOTIFICATIONS_MAX_PAGE_SIZE int64 = 50
+	DEVICE_ACTIVE_PERIOD time.Duration = 90 * 24 * time.Hour
+	ACCESS_TOKEN_TTL time.Duration = 10 * time.Minute
+	// a session ends if its refresh token is not used for this long
+	REFRESH_TOKEN_TTL time.Duration = 30 * 24 * time.Hour
+	TWO_FACTOR_RECOVERY_CODES int = 10
+	TWO_FACTOR_CHALLENGE_TTL time.Duration = 5 * time.Minute
+	TWO_FACTOR_CHALLENGE_MAX_ATTEMPTS int = 5
+	OTP_TTL time.Duration = 10 * time.Minute
+	// wrong guesses allowed on a single otp before it is thrown away
+	OTP_MAX_VERIFY_ATTEMPTS int64 = 5
+	OTP_RESEND_COOLDOWN time.Duration = time.Minute
+	// failed sign ins and OTPs allowed before each further attempt has to wait
+	FREE_AUTH_ATTEMPTS int64 = 3
+	AUTH_ATTEMPT_MAX_BACKOFF time.Duration = 5 * time.Minute
+	// failures are forgotten once this long has passed since the first one
+	AUTH_ATTEMPT_WINDOW time.Duration = time.Hour
+	MAX_ACCOUNT_LOGIN_FAILURES int64 = 10
+	MAX_IP_LOGIN_FAILURES int64 = 50
+	MAX_ACCOUNT_OTP_FAILURES int64 = 8
+	MAX_IP_OTP_FAILURES int64 = 30
+	MAX_ACCOUNT_TWO_FACTOR_FAILURES int64 = 8
+	MAX_IP_TWO_FACTOR_FAILURES int64 = 30
+	AUTH_LOCK_DURATION time.Duration = 30 * time.Minute
+	DATA_REQUEST_POLL_INTERVAL time.Duration = 30 * time.Second
+	DATA_REQUEST_LOCK_DURATION time.Duration = 10 * time.Minute
+	// how long an export can be downloaded for
+	DATA_EXPORT_TTL time.Duration = 7 * 24 * time.Hour
+	// how long a user has to change their mind after asking for their data to be erased
+	DATA_ERASURE_GRACE_PERIOD time.Duration = 7 * 24 * time.Hour
+	// businesses a user can have that have not passed KYB
+	MAX_UNVERIFIED_BUSINESSES int64 = 2
+	// payouts of this much or more by users without two-factor need an otp sent to them
+	PAYOUT_CONFIRMATION_OTP_THRESHOLD_KOBO int64 = 100000000
+	// payout limits for businesses that have not passed KYB
+	UNVERIFIED_BUSINESS_MAX_PAYOUT_KOBO int64 = 5000000
+	UNVERIFIED_BUSINESS_DAILY_PAYOUT_KOBO int64 = 20000000
+	VERIFIED_BUSINESS_DAILY_PAYOUT_KOBO int64 = 100000000000
+	BUSINESS_INVITE_TTL time.Duration = 7 * 24 * time.Hour
+	// members and open invites a business can have, not counting its owner
+	MAX_BUSINESS_MEMBERS int64 = 20
+	PAYOUT_APPROVAL_POLL_INTERVAL time.Duration = time.Minute
+	DEFAULT_PAYOUT_APPROVAL_EXPIRY_HOURS int = 24
+	// active API keys a business can have
+	MAX_BUSINESS_API_KEYS int64 = 10
+	// how often a key's last use is written back, so busy integrations do not write on every request
+	API_KEY_LAST_USED_INTERVAL time.Duration = time.Minute
+	// how often payouts still pending are checked with the processor, and how far back
+	TRANSACTION_SETTLEMENT_POLL_INTERVAL time.Duration = 10 * time.Minute
+	TRANSACTION_SETTLEMENT_LOOKBACK time.Duration = 7 * 24 * time.Hour
+	MIN_TRANSFER_AMOUNT_KOBO int64 = 1000
+	MAX_TRANSFER_AMOUNT_KOBO int64 = 30000000000
 )
\ No newline at end of file
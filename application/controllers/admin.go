@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
+	"kego.com/application/interfaces"
+	"kego.com/application/repository"
+	"kego.com/application/services"
+	"kego.com/entities"
+	server_response "kego.com/infrastructure/serverResponse"
+)
+
+func CreatePricingPlan(ctx *interfaces.ApplicationContext[dto.PricingPlanDTO]){
+	plan := services.CreatePricingPlanVersion(ctx.Ctx, &entities.PricingPlan{
+		Code: ctx.Body.Code,
+		Name: ctx.Body.Name,
+		Description: ctx.Body.Description,
+		Rules: ctx.Body.Rules,
+	})
+	if plan == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "pricing plan created", plan, nil)
+}
+
+func FetchPricingPlans(ctx *interfaces.ApplicationContext[any]){
+	filter := map[string]interface{}{}
+	if ctx.Query["code"] != "" {
+		filter["code"] = ctx.Query["code"]
+	}
+	plans, err := repository.PricingPlanRepo().FindMany(filter, options.Find().SetSort(bson.D{{Key: "code", Value: 1}, {Key: "version", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "pricing plans fetched", plans, nil)
+}
+
+func AssignBusinessPricingPlan(ctx *interfaces.ApplicationContext[dto.AssignPricingPlanDTO]){
+	err := services.AssignPricingPlan(ctx.Ctx, ctx.GetStringParameter("businessID"), ctx.Body.Code)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "pricing plan assigned", nil, nil)
+}
+
+
+func StartReconciliation(ctx *interfaces.ApplicationContext[dto.ReconciliationDTO]){
+	run := services.StartReconciliation(ctx.Ctx, ctx.Body.Provider, ctx.Body.From, ctx.Body.To, nil, nil, ctx.GetStringContextData("UserID"))
+	if run == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "reconciliation started", run, nil)
+}
+
+func UploadReconciliationFile(ctx *interfaces.ApplicationContext[dto.ReconciliationUploadDTO]){
+	file, err := ctx.Body.File.Open()
+	if err != nil {
+		apperrors.ClientError(ctx.Ctx, "The file uploaded could not be read", nil)
+		return
+	}
+	defer file.Close()
+	records := services.ParseReconciliationFile(ctx.Ctx, file)
+	if records == nil {
+		return
+	}
+	run := services.StartReconciliation(ctx.Ctx, ctx.Body.Provider, ctx.Body.From, ctx.Body.To, records, &ctx.Body.File.Filename, ctx.GetStringContextData("UserID"))
+	if run == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "reconciliation started", run, nil)
+}
+
+func FetchReconciliationRuns(ctx *interfaces.ApplicationContext[any]){
+	filter := map[string]interface{}{}
+	if ctx.Query["provider"] != "" {
+		filter["provider"] = ctx.Query["provider"]
+	}
+	runs, err := repository.ReconciliationRunRepo().FindMany(filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(50))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "reconciliation runs fetched", runs, nil)
+}
+
+func FetchReconciliationReport(ctx *interfaces.ApplicationContext[any]){
+	run, err := repository.ReconciliationRunRepo().FindByID(ctx.GetStringParameter("runID"))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	if run == nil {
+		apperrors.NotFoundError(ctx.Ctx, "reconciliation run not found")
+		return
+	}
+	filter := map[string]interface{}{
+		"runID": run.ID,
+	}
+	if ctx.Query["resolution"] != "" {
+		filter["resolution"] = ctx.Query["resolution"]
+	}
+	if ctx.Query["discrepancy"] != "" {
+		filter["discrepancy"] = ctx.Query["discrepancy"]
+	}
+	page, err := strconv.ParseInt(ctx.Query["page"].(string), 10, 64)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	items, err := repository.ReconciliationItemRepo().FindMany(filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetSkip((page - 1) * 100).SetLimit(100))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	open, err := repository.ReconciliationItemRepo().CountDocs(map[string]interface{}{
+		"runID": run.ID,
+		"resolution": entities.ReconciliationItemOpen,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "reconciliation report fetched", map[string]any{
+		"run": run,
+		"openItems": open,
+		"items": items,
+	}, nil)
+}
+
+func ResolveReconciliationItem(ctx *interfaces.ApplicationContext[dto.ResolveReconciliationItemDTO]){
+	item := services.ResolveReconciliationItem(ctx.Ctx, ctx.GetStringParameter("itemID"), ctx.Body.Resolution, ctx.Body.Note, ctx.GetStringContextData("UserID"))
+	if item == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "reconciliation item updated", item, nil)
+}
+
+func FetchDeadLetterEvents(ctx *interfaces.ApplicationContext[any]){
+	filter := map[string]interface{}{}
+	if ctx.Query["consumer"] != "" {
+		filter["consumer"] = ctx.Query["consumer"]
+	}
+	if ctx.Query["requeued"] != "true" {
+		filter["requeued"] = false
+	}
+	deadLetters, err := repository.DeadLetterEventRepo().FindMany(filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(100))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "dead letter events fetched", deadLetters, nil)
+}
+
+func RequeueDeadLetterEvent(ctx *interfaces.ApplicationContext[any]){
+	err := events.Requeue(ctx.GetStringParameter("deadLetterID"))
+	if errors.Is(err, events.ErrDeadLetterNotFound) {
+		apperrors.NotFoundError(ctx.Ctx, err.Error())
+		return
+	}
+	if errors.Is(err, events.ErrDeadLetterRequeued) || errors.Is(err, events.ErrPayloadRemoved) {
+		apperrors.ClientError(ctx.Ctx, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "event requeued", nil, nil)
+}
+
+func FetchBusinessKYBReviews(ctx *interfaces.ApplicationContext[any]){
+	status, _ := ctx.Query["status"].(string)
+	businesses := services.FetchBusinessKYBReviews(ctx.Ctx, entities.KYBStatus(status))
+	if businesses == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "businesses fetched", businesses, nil)
+}
+
+func ReviewBusinessKYB(ctx *interfaces.ApplicationContext[dto.ReviewBusinessKYBDTO]){
+	err := services.ReviewBusinessKYB(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("businessID"), ctx.Body.Approve, ctx.Body.Reason)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "business reviewed", nil, nil)
+}
@@ -11,6 +11,7 @@ import (
 	apperrors "kego.com/application/appErrors"
 	"kego.com/application/constants"
 	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
 	"kego.com/application/interfaces"
 	"kego.com/application/repository"
 	"kego.com/application/services"
@@ -23,8 +24,8 @@ import (
 	"kego.com/infrastructure/database/repository/cache"
 	fileupload "kego.com/infrastructure/file_upload"
 	identityverification "kego.com/infrastructure/identity_verification"
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
 	"kego.com/infrastructure/logger"
-	"kego.com/infrastructure/messaging/emails"
 	server_response "kego.com/infrastructure/serverResponse"
 	"kego.com/infrastructure/validator"
 )
@@ -42,16 +43,29 @@ func CreateAccount(ctx *interfaces.ApplicationContext[dto.CreateAccountDTO]) {
 	if err != nil {
 		return
 	}
-	otp, err := auth.GenerateOTP(6, account.Email)
+	// a new account cannot have been sent an otp yet so there is no cooldown to check
+	otp, _, err := auth.GenerateOTP(auth.OTPEmailVerification, account.Email)
 	if err != nil {
 		apperrors.FatalServerError(ctx.Ctx)
 		return
 	}
+	// the device an account is opened on is the one that goes through kyc, so it starts out trusted
+	registerDevice(account.ID, account.DeviceID, ctx.Body.PushToken, account.UserAgent, account.AppVersion, true)
 	cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), 2, time.Hour * 24 * 365 ) // keep data cached for a year
-	emails.EmailService.SendEmail(account.Email, "Welcome to Kego! Verify your account to continue", "otp", map[string]interface{}{
-		"FIRSTNAME": account.FirstName,
-		"OTP":      otp,
-	},)
+	err = events.Publish(nil, events.OTPRequestedPayload{
+		UserID: account.ID,
+		Email: account.Email,
+		FirstName: account.FirstName,
+		OTP: *otp,
+		Subject: "Welcome to Kego! Verify your account to continue",
+	})
+	if err != nil {
+		// the account exists now, so the user can request another otp instead of signing up again
+		logger.Error(errors.New("could not queue account verification otp"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	}
 	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "account created", nil, nil)
 }
 
@@ -61,19 +75,79 @@ func LoginUser(ctx *interfaces.ApplicationContext[dto.LoginDTO]){
 		apperrors.UnsupportedAppVersion(ctx.Ctx)
 		return
 	}
-	account, token := authusecases.LoginAccount(ctx.Ctx, ctx.Body.Email, ctx.Body.Phone, &ctx.Body.Password, *appVersion, ctx.GetHeader("User-Agent").(string), ctx.Body.DeviceID)
-	if account == nil || token == nil {
+	result := authusecases.LoginAccount(ctx.Ctx, ctx.Body.Email, ctx.Body.Phone, &ctx.Body.Password, authusecases.LoginDevice{
+		AppVersion: *appVersion,
+		UserAgent: ctx.GetHeader("User-Agent").(string),
+		DeviceID: ctx.Body.DeviceID,
+		IPAddress: ctx.Body.IPAddress,
+		Location: ctx.Body.Location,
+	})
+	if result == nil {
+		return
+	}
+	if result.StepUp {
+		server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "enter the code we sent you to finish signing in on this device", map[string]interface{}{
+			"stepUpRequired": true,
+			"challengeToken": result.TwoFactorChallenge,
+		}, nil)
+		return
+	}
+	if result.TwoFactorChallenge != nil {
+		server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "enter the code from your authenticator app to finish signing in", map[string]interface{}{
+			"twoFactorRequired": true,
+			"challengeToken": result.TwoFactorChallenge,
+		}, nil)
+		return
+	}
+	respondLoggedIn(ctx.Ctx, result, ctx.Body.DeviceID, ctx.Body.PushToken)
+}
+
+func CompleteTwoFactorLogin(ctx *interfaces.ApplicationContext[dto.TwoFactorLoginDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
 		return
 	}
-	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "login successful", map[string]interface{}{
-		"account": account,
-		"token":   token,
+	userAgent, _ := ctx.GetHeader("User-Agent").(string)
+	appVersion := utils.ExtractAppVersionFromUserAgentHeader(userAgent)
+	if appVersion == nil {
+		apperrors.UnsupportedAppVersion(ctx.Ctx)
+		return
+	}
+	result := authusecases.CompleteTwoFactorLogin(ctx.Ctx, ctx.Body.ChallengeToken, authusecases.LoginDevice{
+		AppVersion: *appVersion,
+		UserAgent: userAgent,
+		DeviceID: ctx.Body.DeviceID,
+		IPAddress: ctx.Body.IPAddress,
+	}, func(userID string, stepUp bool) (bool, error) {
+		if stepUp {
+			_, success, _ := auth.VerifyOTP(auth.OTPLoginStepUp, userID, ctx.Body.Code, ctx.Body.IPAddress)
+			return success, nil
+		}
+		return services.VerifyTwoFactorCode(userID, ctx.Body.Code)
+	})
+	if result == nil {
+		return
+	}
+	respondLoggedIn(ctx.Ctx, result, ctx.Body.DeviceID, ctx.Body.PushToken)
+}
+
+func respondLoggedIn(ctx any, result *authusecases.LoginResult, deviceID string, pushToken *string) {
+	registerDevice(result.Account.ID, deviceID, pushToken, result.Account.UserAgent, result.Account.AppVersion, false)
+	server_response.Responder.Respond(ctx, http.StatusCreated, "login successful", map[string]interface{}{
+		"account": result.Account,
+		"token":   result.AccessToken,
+		"refreshToken": result.RefreshToken,
 	}, nil)
 }
 
 
 func ResetPassword(ctx *interfaces.ApplicationContext[dto.ResetPasswordDTO]) {
-	msg, success := auth.VerifyOTP(ctx.Body.Email, ctx.Body.Otp)
+	msg, success, blocked := auth.VerifyOTP(auth.OTPPasswordReset, ctx.Body.Email, ctx.Body.Otp, ctx.Body.IPAddress)
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx.Ctx, msg, blocked.RetryAfter, blocked.Locked)
+		return
+	}
 	if !success {
 		apperrors.ClientError(ctx.Ctx, msg, nil)
 		return
@@ -171,20 +245,37 @@ func UpdatePassword(ctx *interfaces.ApplicationContext[dto.UpdatePassword]) {
 }
 
 func ResendOTP(ctx *interfaces.ApplicationContext[any]) {
-	email := ctx.Query["email"].(string)
+	email, _ := ctx.Query["email"].(string)
 	if email == "" {
 		server_response.Responder.Respond(ctx.Ctx, http.StatusBadRequest, "pass in a valid email to recieve the otp", nil, nil)
 		return
 	}
-	otp, err := auth.GenerateOTP(6, email)
-	if err != nil {
-		apperrors.FatalServerError(ctx.Ctx)
+	delivery, _ := ctx.Query["channel"].(string)
+	if delivery != "" && delivery != string(entities.OTPByEmail) && delivery != string(entities.OTPBySMS) && delivery != string(entities.OTPByBoth) {
+		apperrors.ClientError(ctx.Ctx, "channel must be one of email, sms or both", nil)
+		return
+	}
+	purposeQuery, _ := ctx.Query["purpose"].(string)
+	purpose := auth.OTPPurpose(purposeQuery)
+	subjects := map[auth.OTPPurpose]string{
+		auth.OTPEmailVerification: "Verify your email to continue",
+		auth.OTPPasswordReset: "Reset your password",
+	}
+	if purpose == "" {
+		purpose = auth.OTPEmailVerification
+	}
+	subject, ok := subjects[purpose]
+	if !ok {
+		apperrors.ClientError(ctx.Ctx, "purpose must be one of email_verification or password_reset", nil)
+		return
 	}
 	userRepo := repository.UserRepo()
 	account, err := userRepo.FindOneByFilter(map[string]interface{}{
 		"email": email,
 	}, options.FindOne().SetProjection(map[string]any{
 		"firstName": 1,
+		"phone": 1,
+		"emailVerified": 1,
 	}))
 	if err != nil {
 		apperrors.FatalServerError(ctx.Ctx)
@@ -194,15 +285,49 @@ func ResendOTP(ctx *interfaces.ApplicationContext[any]) {
 		server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "otp sent", nil, nil)
 		return
 	}
-	emails.EmailService.SendEmail(email, "An OTP was requested for your account", "otp", map[string]interface{}{
-		"FIRSTNAME": account.FirstName,
-		"OTP":      otp,
-	},)
+	if purpose == auth.OTPEmailVerification && account.EmailVerified {
+		apperrors.ClientError(ctx.Ctx, "this email has already been verified", nil)
+		return
+	}
+	otp, blocked, err := auth.GenerateOTP(purpose, email)
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx.Ctx, blocked.Message(), blocked.RetryAfter, false)
+		return
+	}
+	if !account.EmailVerified {
+		// this otp is what proves the user owns the email address
+		delivery = string(entities.OTPByEmail)
+	}
+	channels, err := events.OTPChannels(account.ID, account.Phone.LocalNumber != "", entities.OTPDelivery(delivery))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
+	err = events.Publish(nil, events.OTPRequestedPayload{
+		UserID: account.ID,
+		Email: email,
+		FirstName: account.FirstName,
+		OTP: *otp,
+		Subject: subject,
+		Channels: channels,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
 	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "otp sent", nil, nil)
 }
 
 func VerifyEmail(ctx *interfaces.ApplicationContext[dto.VerifyEmailData]) {
-	msg, success := auth.VerifyOTP(ctx.Body.Email, ctx.Body.Otp)
+	msg, success, blocked := auth.VerifyOTP(auth.OTPEmailVerification, ctx.Body.Email, ctx.Body.Otp, ctx.Body.IPAddress)
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx.Ctx, msg, blocked.RetryAfter, blocked.Locked)
+		return
+	}
 	if !success {
 		apperrors.ClientError(ctx.Ctx, msg, nil)
 		return
@@ -249,19 +374,53 @@ func VerifyAccount(ctx *interfaces.ApplicationContext[dto.VerifyAccountData]){
 		return
 	}
 	bvnDetails, err := identityverification.IdentityVerifier.FetchBVNDetails(account.BVN)
+	if errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+		// an outage is not the user's fault so it does not use up an attempt
+		apperrors.ExternalDependencyError(ctx.Ctx, "identity verification", "", err)
+		return
+	}
 	if err != nil {
 		cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
 		apperrors.CustomError(ctx.Ctx, err.Error())
 		return
 	}
+	// users whose face does not match their BVN picture, which can be years old, can match against their NIN instead
+	// as long as it belongs to the same person
+	faceReference := bvnDetails.Base64Image
+	idName := "BVN"
+	var ninDetails *identity_verification_types.DocumentData
+	if ctx.Body.NIN != "" {
+		ninDetails, err = identityverification.IdentityVerifier.FetchNINDetails(ctx.Body.NIN)
+		if errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+			apperrors.ExternalDependencyError(ctx.Ctx, "identity verification", "", err)
+			return
+		}
+		if err != nil {
+			cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
+			apperrors.CustomError(ctx.Ctx, err.Error())
+			return
+		}
+		nameMatched, dobMatched := services.MatchesBVNRecord(bvnDetails.FirstName, bvnDetails.MiddleName, bvnDetails.LastName, bvnDetails.DateOfBirth, ninDetails)
+		if !nameMatched || !dobMatched {
+			cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
+			reason := fmt.Sprintf("The name or date of birth on your NIN does not match your BVN. If you think this is a mistake please contact support on %s", constants.SUPPORT_EMAIL)
+			services.RecordKYCEvidence(account.ID, account.BVN, bvnDetails, ctx.Body.NIN, ninDetails, nil, &reason)
+			apperrors.ClientError(ctx.Ctx, reason, nil)
+			return
+		}
+		faceReference = ninDetails.Base64Image
+		idName = "NIN"
+	}
 	url, err := fileupload.FileUploader.UploadSingleFile(ctx.Body.ProfileImage, &account.ID)
 	if err != nil {
 		apperrors.FatalServerError(ctx.Ctx)
 		return
 	}
-	result, err := identityverification.IdentityVerifier.FaceMatch(*url, bvnDetails.Base64Image)
+	result, err := identityverification.IdentityVerifier.FaceMatch(*url, faceReference)
 	if err != nil {
-		cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
+		if !errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+			cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
+		}
 		cldErr := fileupload.FileUploader.DeleteSingleFile(account.ID)
 		if cldErr != nil {
 			apperrors.FatalServerError(ctx.Ctx)
@@ -270,16 +429,23 @@ func VerifyAccount(ctx *interfaces.ApplicationContext[dto.VerifyAccountData]){
 		apperrors.ClientError(ctx.Ctx, err.Error(), nil)
 		return
 	}
-	if *result < 80 {
+	if *result < constants.FACE_MATCH_MIN_CONFIDENCE {
 		cache.Cache.CreateEntry(fmt.Sprintf("%s-kyc-attempts-left", account.Email), parsedAttemptsLeft - 1 , time.Hour * 24 * 365 ) // keep data cached for a year
 		err = fileupload.FileUploader.DeleteSingleFile(account.ID)
 		if err != nil {
 			apperrors.FatalServerError(ctx.Ctx)
 			return
 		}
-		apperrors.ClientError(ctx.Ctx, fmt.Sprintf("Your picture does not match with your Image on the BVN provided. If you think this is a mistake please contact support on %s", constants.SUPPORT_EMAIL), nil)
+		reason := fmt.Sprintf("Your picture does not match with your Image on the %s provided. If you think this is a mistake please contact support on %s", idName, constants.SUPPORT_EMAIL)
+		services.RecordKYCEvidence(account.ID, account.BVN, bvnDetails, ctx.Body.NIN, ninDetails, result, &reason)
+		apperrors.ClientError(ctx.Ctx, reason, nil)
 		return
 	}
+	services.RecordKYCEvidence(account.ID, account.BVN, bvnDetails, ctx.Body.NIN, ninDetails, result, nil)
+	kycTier := entities.KYCTierBasic
+	if ninDetails != nil {
+		kycTier = entities.KYCTierFull
+	}
 	userUpdatedInfo := map[string]any{
 		"gender": bvnDetails.Gender,
 		"dob": bvnDetails.DateOfBirth,
@@ -295,6 +461,7 @@ func VerifyAccount(ctx *interfaces.ApplicationContext[dto.VerifyAccountData]){
 		},
 		"profileImage": *url,
 		"kycCompleted": true,
+		"kycTier": kycTier,
 	}
 	userRepo.UpdatePartialByFilter(map[string]interface{}{
 		"email": ctx.Body.Email,
@@ -374,4 +541,101 @@ func DeactivateAccount(ctx *interfaces.ApplicationContext[dto.ConfirmPin]){
 		apperrors.FatalServerError(ctx.Ctx)
 	}
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "deactivated", nil, nil)
-}
\ No newline at end of file
+}
+
+// registerDevice keeps the device registry up to date without failing the sign in when it cannot be.
+func registerDevice(userID string, deviceID string, pushToken *string, userAgent string, appVersion string, trusted bool) {
+	if pushToken == nil || *pushToken == "" {
+		// older versions of the app register the device id with FCM in place of a separate push token
+		pushToken = &deviceID
+	}
+	err := services.RegisterDevice(userID, deviceID, pushToken, userAgent, appVersion, trusted)
+	if err != nil {
+		logger.Error(errors.New("could not register device"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		})
+	}
+}
+
+func RefreshAuthToken(ctx *interfaces.ApplicationContext[dto.RefreshTokenDTO]){
+	if ctx.Body.RefreshToken == "" {
+		apperrors.ClientError(ctx.Ctx, "provide a refresh token", nil)
+		return
+	}
+	userAgent, _ := ctx.GetHeader("User-Agent").(string)
+	appVersion := utils.ExtractAppVersionFromUserAgentHeader(userAgent)
+	if appVersion == nil {
+		apperrors.UnsupportedAppVersion(ctx.Ctx)
+		return
+	}
+	token, refreshToken := authusecases.RefreshSession(ctx.Ctx, ctx.Body.RefreshToken, ctx.Body.DeviceID, userAgent, *appVersion)
+	if token == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "token refreshed", map[string]interface{}{
+		"token": token,
+		"refreshToken": refreshToken,
+	}, nil)
+}
+
+func UpdateTransactionPin(ctx *interfaces.ApplicationContext[dto.UpdateTransactionPinDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	err := services.ChangeTransactionPin(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.CurrentPin, ctx.Body.Password, ctx.Body.NewPin)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "transaction pin updated", nil, nil)
+}
+
+func RequestTransactionPinReset(ctx *interfaces.ApplicationContext[any]){
+	err := services.RequestTransactionPinReset(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "otp sent", nil, nil)
+}
+
+func ResetTransactionPin(ctx *interfaces.ApplicationContext[dto.ResetTransactionPinDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	err := services.ResetTransactionPin(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Otp, ctx.Body.Selfie, ctx.Body.NewPin, ctx.Body.IPAddress)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, fmt.Sprintf("transaction pin reset, you can send money again in %d hours", int(constants.TRANSACTION_PIN_RESET_COOLING_OFF.Hours())), nil, nil)
+}
+
+func RequestAccountUnlock(ctx *interfaces.ApplicationContext[dto.RequestAccountUnlockDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	if !authusecases.RequestAccountUnlock(ctx.Ctx, ctx.Body.Email) {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "otp sent", nil, nil)
+}
+
+func UnlockAccount(ctx *interfaces.ApplicationContext[dto.UnlockAccountDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	if !authusecases.UnlockAccount(ctx.Ctx, ctx.Body.Email, ctx.Body.Otp, ctx.Body.IPAddress) {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "account unlocked", nil, nil)
+}
@@ -1,15 +1,18 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 
 	apperrors "kego.com/application/appErrors"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
 	"kego.com/application/repository"
+	"kego.com/application/services"
 	"kego.com/application/usecases/business"
 	"kego.com/entities"
 	server_response "kego.com/infrastructure/serverResponse"
+	"kego.com/infrastructure/validator"
 )
 
 
@@ -45,13 +48,233 @@ func DeleteBusiness(ctx *interfaces.ApplicationContext[any]){
 }
 
 func FetchBusinesses(ctx *interfaces.ApplicationContext[any]){
+	memberOf, err := services.FetchMemberBusinessIDs(ctx.GetStringContextData("UserID"))
+	if err != nil {
+		apperrors.FatalServerError(ctx.Ctx)
+		return
+	}
 	businessRepo := repository.BusinessRepo()
 	businesses, err := businessRepo.FindMany(map[string]interface{}{
-		"userID": ctx.GetStringContextData("UserID"),
+		"$or": []map[string]any{
+			{"userID": ctx.GetStringContextData("UserID")},
+			{"_id": map[string]any{"$in": memberOf}},
+		},
 	})
 	if err != nil {
 		apperrors.FatalServerError(ctx.Ctx)
 		return
 	}
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "businesses fetched", businesses, nil)
-}
\ No newline at end of file
+}
+func SaveBusinessKYBDetails(ctx *interfaces.ApplicationContext[dto.BusinessKYBDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	business := services.SaveBusinessKYBDetails(ctx.Ctx, ctx.GetStringParameter("businessID"), ctx.Body)
+	if business == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "business details saved", business, nil)
+}
+
+func UploadBusinessDocument(ctx *interfaces.ApplicationContext[dto.BusinessDocumentDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	document := services.UploadBusinessDocument(ctx.Ctx, ctx.GetStringParameter("businessID"), ctx.Body.Type, ctx.Body.File)
+	if document == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "document uploaded", document, nil)
+}
+
+func SubmitBusinessKYB(ctx *interfaces.ApplicationContext[any]){
+	business := services.SubmitBusinessKYB(ctx.Ctx, ctx.GetStringParameter("businessID"))
+	if business == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "your business has been submitted for review", business, nil)
+}
+
+func FetchBusinessMembers(ctx *interfaces.ApplicationContext[any]){
+	owner, members := services.FetchBusinessMembers(ctx.Ctx, ctx.GetStringParameter("businessID"))
+	if members == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "members fetched", map[string]any{
+		"owner": map[string]any{
+			"userID": owner.ID,
+			"email": owner.Email,
+			"firstName": owner.FirstName,
+			"lastName": owner.LastName,
+			"role": entities.BusinessOwner,
+		},
+		"members": members,
+	}, nil)
+}
+
+func InviteBusinessMember(ctx *interfaces.ApplicationContext[dto.InviteBusinessMemberDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	member := services.InviteBusinessMember(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"), ctx.Body.Email, ctx.Body.Role)
+	if member == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "invite sent", member, nil)
+}
+
+func UpdateBusinessMember(ctx *interfaces.ApplicationContext[dto.UpdateBusinessMemberDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	member := services.UpdateBusinessMemberRole(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"), ctx.GetStringParameter("memberID"), ctx.Body.Role)
+	if member == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "member updated", member, nil)
+}
+
+func RemoveBusinessMember(ctx *interfaces.ApplicationContext[any]){
+	err := services.RemoveBusinessMember(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"), ctx.GetStringParameter("memberID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "member removed", nil, nil)
+}
+
+func LeaveBusiness(ctx *interfaces.ApplicationContext[any]){
+	err := services.LeaveBusiness(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "you have left the business", nil, nil)
+}
+
+func FetchBusinessInvites(ctx *interfaces.ApplicationContext[any]){
+	invites := services.FetchBusinessInvites(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if invites == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "invites fetched", invites, nil)
+}
+
+func AcceptBusinessInvite(ctx *interfaces.ApplicationContext[any]){
+	member := services.AcceptBusinessInvite(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("inviteID"))
+	if member == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, fmt.Sprintf("you have joined %s", member.BusinessName), member, nil)
+}
+
+func DeclineBusinessInvite(ctx *interfaces.ApplicationContext[any]){
+	err := services.DeclineBusinessInvite(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("inviteID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "invite declined", nil, nil)
+}
+
+func FetchPayoutApprovalPolicy(ctx *interfaces.ApplicationContext[any]){
+	policy, ok := services.FetchPayoutApprovalPolicy(ctx.Ctx, ctx.GetStringParameter("businessID"))
+	if !ok {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "payout approval policy fetched", policy, nil)
+}
+
+func SetPayoutApprovalPolicy(ctx *interfaces.ApplicationContext[dto.PayoutApprovalPolicyDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	business := services.SetPayoutApprovalPolicy(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("businessID"), ctx.Body)
+	if business == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "payout approval policy updated", business.PayoutApprovalPolicy, nil)
+}
+
+func FetchPayoutApprovals(ctx *interfaces.ApplicationContext[any]){
+	status, _ := ctx.Query["status"].(string)
+	approvals := services.FetchPayoutApprovals(ctx.Ctx, ctx.GetStringParameter("businessID"), entities.PayoutApprovalStatus(status))
+	if approvals == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "payouts fetched", approvals, nil)
+}
+
+func ApprovePayout(ctx *interfaces.ApplicationContext[dto.PayoutApprovalDecisionDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	approval := services.ApprovePayout(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"), ctx.GetStringParameter("approvalID"), ctx.Body.Pin, services.PayoutConfirmation{
+		TOTPCode: ctx.Body.TOTPCode,
+		OTP: ctx.Body.OTP,
+		IPAddress: ctx.Body.IPAddress,
+	})
+	if approval == nil {
+		return
+	}
+	message := "your approval has been recorded"
+	if approval.Status == entities.PayoutApprovalSent {
+		message = "payment approved and sent"
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, message, approval, nil)
+}
+
+func RejectPayout(ctx *interfaces.ApplicationContext[dto.PayoutApprovalDecisionDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	approval := services.RejectPayout(ctx.Ctx, ctx.GetStringContextData("UserID"), entities.BusinessRole(ctx.GetStringContextData("BusinessRole")), ctx.GetStringParameter("businessID"), ctx.GetStringParameter("approvalID"), ctx.Body.Pin, ctx.Body.Reason)
+	if approval == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "payment rejected and its funds returned", approval, nil)
+}
+
+func FetchBusinessAPIKeys(ctx *interfaces.ApplicationContext[any]){
+	keys := services.FetchBusinessAPIKeys(ctx.Ctx, ctx.GetStringParameter("businessID"))
+	if keys == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "api keys fetched", keys, nil)
+}
+
+func CreateBusinessAPIKey(ctx *interfaces.ApplicationContext[dto.CreateBusinessAPIKeyDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	key, secret := services.CreateBusinessAPIKey(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("businessID"), ctx.Body)
+	if key == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "api key created. Copy it now, it will not be shown again.", map[string]any{
+		"apiKey": key,
+		"key": secret,
+	}, nil)
+}
+
+func RevokeBusinessAPIKey(ctx *interfaces.ApplicationContext[any]){
+	err := services.RevokeBusinessAPIKey(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("businessID"), ctx.GetStringParameter("keyID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "api key revoked", nil, nil)
+}
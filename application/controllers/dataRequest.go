@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+	"path/filepath"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+)
+
+func RequestDataExport(ctx *interfaces.ApplicationContext[dto.VerifyPassword]){
+	if ctx.Body.Password == "" {
+		apperrors.ClientError(ctx.Ctx, "enter your password to continue", nil)
+		return
+	}
+	request := services.RequestDataExport(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Password)
+	if request == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "we are preparing your data and will notify you when it is ready", request, nil)
+}
+
+func RequestAccountErasure(ctx *interfaces.ApplicationContext[dto.VerifyPassword]){
+	if ctx.Body.Password == "" {
+		apperrors.ClientError(ctx.Ctx, "enter your password to continue", nil)
+		return
+	}
+	request := services.RequestAccountErasure(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Password)
+	if request == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusAccepted, "your account is scheduled to be erased", request, nil)
+}
+
+func FetchDataRequests(ctx *interfaces.ApplicationContext[any]){
+	requests := services.FetchDataRequests(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if requests == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "data requests fetched", requests, nil)
+}
+
+func CancelDataRequest(ctx *interfaces.ApplicationContext[any]){
+	err := services.CancelDataRequest(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("requestID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "request cancelled", nil, nil)
+}
+
+func DownloadDataExport(ctx *interfaces.ApplicationContext[any]){
+	path := services.OpenDataExport(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("requestID"))
+	if path == nil {
+		return
+	}
+	server_response.Responder.RespondWithFile(ctx.Ctx, *path, filepath.Base(*path))
+}
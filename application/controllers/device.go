@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+	"kego.com/infrastructure/validator"
+)
+
+func FetchDevices(ctx *interfaces.ApplicationContext[any]){
+	devices := services.FetchDevices(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if devices == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "devices fetched", devices, nil)
+}
+
+func UpdateDevicePushToken(ctx *interfaces.ApplicationContext[dto.UpdatePushTokenDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	err := services.UpdateDevicePushToken(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringContextData("DeviceID"), ctx.Body.PushToken, ctx.GetStringContextData("UserAgent"), ctx.GetStringContextData("AppVersion"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "push token updated", nil, nil)
+}
+
+func RemoveDevice(ctx *interfaces.ApplicationContext[any]){
+	err := services.RemoveDevice(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("deviceID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "device removed", nil, nil)
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"mime/multipart"
+	"time"
+
+	"kego.com/entities"
+)
+
+type PricingPlanDTO struct {
+	Code 		 string 			 `json:"code"`
+	Name 		 string 			 `json:"name"`
+	Description  string 			 `json:"description"`
+	Rules 		 []entities.FeeRule  `json:"rules"`
+}
+
+type AssignPricingPlanDTO struct {
+	Code string `json:"code"`
+}
+
+
+type ReconciliationDTO struct {
+	Provider  string 	 `json:"provider"`
+	From 	  time.Time  `json:"from"`
+	To 		  time.Time  `json:"to"`
+}
+
+type ReconciliationUploadDTO struct {
+	Provider  string
+	From 	  time.Time
+	To 		  time.Time
+	File 	  *multipart.FileHeader
+}
+
+type ResolveReconciliationItemDTO struct {
+	Resolution  entities.ReconciliationResolution  `json:"resolution"`
+	Note 		string 							   `json:"note"`
+}
+
+type ReviewBusinessKYBDTO struct {
+	Approve 	bool 	 `json:"approve"`
+	Reason 		*string  `json:"reason"` // required when rejecting, shown to the business owner
+}
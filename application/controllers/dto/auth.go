@@ -1,6 +1,10 @@
 package dto
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+
+	"kego.com/entities"
+)
 
 type CreateAccountDTO struct {
 	Email      		  string                 `json:"email"`
@@ -10,6 +14,7 @@ type CreateAccountDTO struct {
 	TransactionPin    string           		 `json:"transactionPin"`
 	AppVersion        string       			 `json:"appVersion"`
 	BVN    			  string           		 `json:"bvn"`
+	PushToken 		  *string 				 `json:"pushToken"`
 }
 
 type LoginDTO struct {
@@ -17,16 +22,34 @@ type LoginDTO struct {
 	Phone      *string  			  `json:"phone,omitempty"`
 	Password   string                 `json:"password"`
 	DeviceID   string                 `json:"deviceID"`
+	PushToken  *string 				  `json:"pushToken"`
+	IPAddress  string 				  `json:"ipAddress"`
+	Location   entities.SessionLocation `json:"-"`
+}
+
+type TwoFactorLoginDTO struct {
+	ChallengeToken string  `json:"challengeToken" validate:"required"`
+	Code           string  `json:"code" validate:"required"`
+	DeviceID       string  `json:"deviceID"`
+	PushToken      *string `json:"pushToken"`
+	IPAddress      string  `json:"-"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refreshToken"`
+	DeviceID     string `json:"deviceID"`
 }
 
 type VerifyEmailData struct {
-	Otp     string `json:"otp"`
-	Email	string `json:"email"`
+	Otp     	string `json:"otp"`
+	Email		string `json:"email"`
+	IPAddress 	string `json:"-"`
 }
 
 type VerifyAccountData struct {
 	ProfileImage     *multipart.FileHeader
 	Email 			 string
+	NIN 			 string // optional, to match the picture against the NIN instead of the BVN
 }
 
 type VerifyPassword struct {
@@ -37,6 +60,17 @@ type ResetPasswordDTO struct {
 	Otp         string `json:"otp"`
 	NewPassword string `json:"newPassword"`
 	Email       string `json:"email"`
+	IPAddress   string `json:"-"`
+}
+
+type RequestAccountUnlockDTO struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type UnlockAccountDTO struct {
+	Email     string `json:"email" validate:"required,email"`
+	Otp       string `json:"otp" validate:"required"`
+	IPAddress string `json:"-"`
 }
 
 type UpdatePassword struct {
@@ -44,6 +78,19 @@ type UpdatePassword struct {
 	NewPassword     string `json:"newPassword"`
 }
 
+type UpdateTransactionPinDTO struct {
+	CurrentPin string `json:"currentPin" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+	NewPin     string `json:"newPin" validate:"required,password"`
+}
+
+type ResetTransactionPinDTO struct {
+	Otp    	  string 				`validate:"required"`
+	NewPin 	  string 				`validate:"required,password"`
+	Selfie 	  *multipart.FileHeader `validate:"required"`
+	IPAddress string
+}
+
 type ConfirmPin struct {
 	Pin    string           		 `json:"pin"`
 }
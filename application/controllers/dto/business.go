@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"mime/multipart"
+
+	"kego.com/entities"
+)
+
 type BusinessDTO struct {
 	Name string `json:"name"`
 }
@@ -7,4 +13,47 @@ type BusinessDTO struct {
 type UpdateBusinessDTO struct {
 	Name string `json:"name" validate:"required"`
 	ID 	 string `json:"id" validate:"required"`
-}
\ No newline at end of file
+}
+
+type BusinessKYBDTO struct {
+	RegistrationNumber 	string 						`json:"registrationNumber" validate:"required"`
+	TIN 				string 						`json:"tin" validate:"required"`
+	RegisteredAddress 	entities.BusinessAddress 	`json:"registeredAddress" validate:"required"`
+	Directors 			[]entities.BusinessPerson 	`json:"directors" validate:"required,min=1,dive"`
+	BeneficialOwners 	[]entities.BusinessPerson 	`json:"beneficialOwners" validate:"required,min=1,dive"`
+}
+
+type BusinessDocumentDTO struct {
+	Type 	entities.BusinessDocumentType 	`validate:"required,oneof=certificate_of_incorporation memorandum_and_articles status_report proof_of_address tin_certificate"`
+	File 	*multipart.FileHeader 			`validate:"required"`
+}
+
+type InviteBusinessMemberDTO struct {
+	Email 	string 					`json:"email" validate:"required,email"`
+	Role 	entities.BusinessRole 	`json:"role" validate:"required,oneof=admin finance viewer"`
+}
+
+type UpdateBusinessMemberDTO struct {
+	Role 	entities.BusinessRole 	`json:"role" validate:"required,oneof=admin finance viewer"`
+}
+
+type PayoutApprovalPolicyDTO struct {
+	// no rules turns approvals off
+	Rules 				[]entities.PayoutApprovalRule 	`json:"rules" validate:"max=10,dive"`
+	ExpiresAfterHours 	int 							`json:"expiresAfterHours" validate:"omitempty,min=1,max=168"`
+}
+
+type PayoutApprovalDecisionDTO struct {
+	Pin 		string 	`json:"pin" validate:"required"`
+	TOTPCode 	*string `json:"totpCode"`
+	OTP 		*string `json:"otp"`
+	IPAddress 	string 	`json:"-"`
+	Reason 		*string `json:"reason"` // shown to whoever made the payout when it is rejected
+}
+
+type CreateBusinessAPIKeyDTO struct {
+	Name 		string 							`json:"name" validate:"required,max=50"`
+	Scopes 		[]entities.BusinessPermission 	`json:"scopes" validate:"required,min=1,dive,oneof=view_balances send_payouts"`
+	AllowedIPs 	[]string 						`json:"allowedIPs" validate:"max=20,dive,ip|cidr"`
+	Pin 		string 							`json:"pin" validate:"required"`
+}
@@ -1,10 +1,53 @@
 package dto
 
-import "kego.com/entities"
+import (
+	"mime/multipart"
+
+	"kego.com/entities"
+	"kego.com/application/money"
+)
 
 type UpdateUserDTO struct {
 	FirstName         *string       			`bson:"firstName" json:"firstName"`
 	LastName          *string       			`bson:"lastName" json:"lastName"`
 	Phone             *entities.PhoneNumber		`bson:"phone" json:"phone,omitempty"`
 	BankDetails		  *entities.BankDetails  	`bson:"bankDetails" json:"bankDetails"`
-}
\ No newline at end of file
+}
+type ChannelPreferencesDTO struct {
+	Security 	 *bool `json:"security"`
+	Transactions *bool `json:"transactions"`
+	Marketing 	 *bool `json:"marketing"`
+	Product 	 *bool `json:"product"`
+}
+
+type NotificationPreferencesDTO struct {
+	Email  *ChannelPreferencesDTO `json:"email"`
+	Push   *ChannelPreferencesDTO `json:"push"`
+	SMS    *ChannelPreferencesDTO `json:"sms"`
+	OTPDelivery *entities.OTPDelivery `json:"otpDelivery" validate:"omitempty,oneof=email sms both"`
+}
+
+type UpdatePushTokenDTO struct {
+	PushToken string `json:"pushToken" validate:"required"`
+}
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorDTO struct {
+	Password string `json:"password" validate:"required"`
+	Code 	 string `json:"code" validate:"required"`
+}
+
+type PayoutTwoFactorThresholdDTO struct {
+	// nil asks for a code on every payout once two-factor authentication is on
+	Threshold *money.Money `json:"threshold"`
+	Code 	  string 	   `json:"code" validate:"required"`
+}
+
+type VerifyIdentityDocumentDTO struct {
+	DocumentType 	entities.IdentityDocumentType 	`validate:"required,oneof=nin passport drivers_licence"`
+	Number 			string 							`validate:"required"`
+	Image 			*multipart.FileHeader 			`validate:"required_unless=DocumentType nin"` // a picture of the document. Not needed for a NIN
+}
@@ -1,15 +1,48 @@
 package dto
 
+import (
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
 type SendPaymentDTO struct {
 	Pin         			string       	 `json:"pin"`
+	QuoteID         		string       	 `json:"quoteID"`
 	FullName         		*string      	 `json:"fullName"`
-	Amount      			uint64      	 `json:"amount"`
+	Amount      			money.Money      `json:"amount"`
 	DestinationCountryCode  string 		 	 `json:"destinationCountryCode"`
 	BankCode				string 		 	 `json:"bankCode"`
 	BranchCode				*string 		 `json:"branchCode"`
 	AccountNumber 			string 			 `json:"accountNumber"`
 	Description 			*string 		 `json:"description"`
 	IPAddress 				string 			 `json:"ipAddress"`
+	TOTPCode 				*string 		 `json:"totpCode"`
+	OTP 					*string 		 `json:"otp"` // asked for on large payouts when two-factor is off
+}
+
+// Older clients send the amount as a bare number of minor units, which was always in naira for local payments
+// and in the destination's currency for international ones.
+func (payload *SendPaymentDTO) DefaultLocalCurrency() {
+	if payload.Amount.Currency == "" {
+		payload.Amount.Currency = "NGN"
+	}
+}
+
+func (payload *SendPaymentDTO) DefaultDestinationCurrency() {
+	if payload.Amount.Currency == "" {
+		payload.Amount.Currency = utils.CountryCodeToCurrencyCode(payload.DestinationCountryCode)
+	}
+}
+
+type InternationalPaymentQuoteDTO struct {
+	Amount      			money.Money      `json:"amount"`
+	DestinationCountryCode  string 		 	 `json:"destinationCountryCode"`
+}
+
+func (payload *InternationalPaymentQuoteDTO) DefaultDestinationCurrency() {
+	if payload.Amount.Currency == "" {
+		payload.Amount.Currency = utils.CountryCodeToCurrencyCode(payload.DestinationCountryCode)
+	}
 }
 
 type NameVerificationDTO struct {
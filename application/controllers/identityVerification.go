@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+	"kego.com/infrastructure/validator"
+)
+
+func VerifyIdentityDocument(ctx *interfaces.ApplicationContext[dto.VerifyIdentityDocumentDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	verification := services.VerifyIdentityDocument(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.DocumentType, ctx.Body.Number, ctx.Body.Image)
+	if verification == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "document verified", verification, nil)
+}
+
+func FetchIdentityVerifications(ctx *interfaces.ApplicationContext[any]){
+	verifications := services.FetchIdentityVerifications(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if verifications == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "identity documents fetched", verifications, nil)
+}
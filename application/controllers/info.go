@@ -4,15 +4,18 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	apperrors "kego.com/application/appErrors"
 	bankssupported "kego.com/application/banksSupported"
 	"kego.com/application/controllers/dto"
 	countriessupported "kego.com/application/countriesSupported"
 	"kego.com/application/interfaces"
+	"kego.com/application/money"
 	"kego.com/application/services"
+	"kego.com/application/utils"
 	"kego.com/entities"
-	international_payment_processor "kego.com/infrastructure/payment_processor/chimoney"
 	server_response "kego.com/infrastructure/serverResponse"
 )
 
@@ -41,20 +44,67 @@ func FetchInternationalBanks(ctx *interfaces.ApplicationContext[dto.CountryCode]
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "banks fetched", banks, nil)
 }
 
+// The amount is in minor units of the currency. Currency accepts an ISO 4217 code or the country code it is used in.
 func FetchExchangeRates(ctx *interfaces.ApplicationContext[any]){
-	amountAsInt, err := strconv.Atoi(ctx.Query["amount"].(string))
+	currency := ctx.Query["currency"].(string)
+	if currency == "" {
+		rates, _ := services.GetExchangeRates(ctx.Ctx)
+		if rates == nil {
+			return
+		}
+		server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "rates fetched", rates, nil)
+		return
+	}
+	amount, err := strconv.ParseInt(ctx.Query["amount"].(string), 10, 64)
+	if err != nil || amount < 0 {
+		apperrors.ClientError(ctx.Ctx, fmt.Sprintf("The amount %s is not a valid amount. Put in a valid amount", ctx.Query["amount"]), nil)
+		return
+	}
+	if len(currency) == 2 {
+		currency = utils.CountryCodeToCurrencyCode(strings.ToUpper(currency))
+	}
+	conversion, _ := services.ConvertToNGN(ctx.Ctx, money.New(amount, currency))
+	if conversion == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "rates fetched", conversion, nil)
+}
+
+func FetchExchangeRatesAtDate(ctx *interfaces.ApplicationContext[any]){
+	date, err := time.Parse(time.RFC3339, ctx.Query["date"].(string))
 	if err != nil {
-		apperrors.ClientError(ctx, fmt.Sprintf("The amount %d is not a valid amount. Put in a valid amount", amountAsInt), nil)
+		apperrors.ClientError(ctx.Ctx, "pass in a valid date in the RFC3339 format", nil)
 		return
 	}
-	rates, statusCode, err := international_payment_processor.InternationalPaymentProcessor.GetExchangeRates(ctx.Query["currency"], uint64(amountAsInt))
+	snapshot := services.FetchExchangeRateAt(ctx.Ctx, date)
+	if snapshot == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "rates fetched", map[string]any{
+		"fetchedAt": snapshot.FetchedAt,
+		"provider": snapshot.Provider,
+		"rates": snapshot.Rates.FormatAllRates(),
+	}, nil)
+}
+
+func FetchExchangeRateHistory(ctx *interfaces.ApplicationContext[any]){
+	from, err := time.Parse(time.RFC3339, ctx.Query["from"].(string))
 	if err != nil {
-		apperrors.ExternalDependencyError(ctx.Ctx, "chimoney", fmt.Sprintf("%d", statusCode), err)
+		apperrors.ClientError(ctx.Ctx, "pass in a valid from date in the RFC3339 format", nil)
+		return
+	}
+	to, err := time.Parse(time.RFC3339, ctx.Query["to"].(string))
+	if err != nil {
+		apperrors.ClientError(ctx.Ctx, "pass in a valid to date in the RFC3339 format", nil)
+		return
+	}
+	if ctx.Query["currency"] == "" {
+		apperrors.ClientError(ctx.Ctx, "pass in a currency", nil)
 		return
 	}
-	if statusCode != 200 {
-		apperrors.UnknownError(ctx.Ctx)
+	history := services.FetchExchangeRateHistory(ctx.Ctx, ctx.Query["currency"].(string), from, to)
+	if history == nil {
 		return
 	}
-	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "rates fetched", rates, nil)
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "rate history fetched", history, nil)
 }
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+)
+
+func FetchNotifications(ctx *interfaces.ApplicationContext[any]){
+	page, _ := strconv.ParseInt(ctx.Query["page"].(string), 10, 64)
+	limit, _ := strconv.ParseInt(ctx.Query["limit"].(string), 10, 64)
+	notifications, total := services.FetchNotifications(ctx.Ctx, ctx.GetStringContextData("UserID"), page, limit, ctx.Query["unread"] == "true")
+	if notifications == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "notifications fetched", map[string]any{
+		"notifications": notifications,
+		"total": total,
+	}, nil)
+}
+
+func CountUnreadNotifications(ctx *interfaces.ApplicationContext[any]){
+	count := services.CountUnreadNotifications(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if count == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "unread notifications counted", map[string]any{
+		"unread": *count,
+	}, nil)
+}
+
+func MarkNotificationRead(ctx *interfaces.ApplicationContext[any]){
+	err := services.MarkNotificationRead(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("notificationID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "notification marked as read", nil, nil)
+}
+
+func MarkAllNotificationsRead(ctx *interfaces.ApplicationContext[any]){
+	updated := services.MarkAllNotificationsRead(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if updated == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "notifications marked as read", map[string]any{
+		"updated": *updated,
+	}, nil)
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+)
+
+func FetchActiveSessions(ctx *interfaces.ApplicationContext[any]){
+	sessions := services.FetchActiveSessions(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if sessions == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "sessions fetched", map[string]any{
+		"sessions": sessions,
+		"currentSessionID": ctx.GetStringContextData("SessionID"),
+	}, nil)
+}
+
+func RevokeSession(ctx *interfaces.ApplicationContext[any]){
+	err := services.RevokeSession(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringParameter("sessionID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "session revoked", nil, nil)
+}
+
+func RevokeOtherSessions(ctx *interfaces.ApplicationContext[any]){
+	revoked, err := services.RevokeOtherSessions(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringContextData("SessionID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "other sessions revoked", map[string]any{
+		"revoked": *revoked,
+	}, nil)
+}
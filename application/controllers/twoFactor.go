@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	server_response "kego.com/infrastructure/serverResponse"
+	"kego.com/infrastructure/validator"
+)
+
+func FetchTwoFactorStatus(ctx *interfaces.ApplicationContext[any]){
+	status := services.FetchTwoFactorStatus(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if status == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "two-factor authentication status fetched", status, nil)
+}
+
+func EnrollTwoFactor(ctx *interfaces.ApplicationContext[any]){
+	enrollment := services.EnrollTwoFactor(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.GetStringContextData("Email"))
+	if enrollment == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "scan the qr code with your authenticator app then confirm with a code from it", enrollment, nil)
+}
+
+func ConfirmTwoFactor(ctx *interfaces.ApplicationContext[dto.TwoFactorCodeDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	recoveryCodes := services.ConfirmTwoFactor(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Code)
+	if recoveryCodes == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "two-factor authentication turned on, keep your recovery codes somewhere safe", map[string]any{
+		"recoveryCodes": recoveryCodes,
+	}, nil)
+}
+
+func DisableTwoFactor(ctx *interfaces.ApplicationContext[dto.DisableTwoFactorDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	err := services.DisableTwoFactor(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Password, ctx.Body.Code)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "two-factor authentication turned off", nil, nil)
+}
+
+func RegenerateRecoveryCodes(ctx *interfaces.ApplicationContext[dto.TwoFactorCodeDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	recoveryCodes := services.RegenerateRecoveryCodes(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Code)
+	if recoveryCodes == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "new recovery codes generated, the old ones no longer work", map[string]any{
+		"recoveryCodes": recoveryCodes,
+	}, nil)
+}
+
+func SetPayoutTwoFactorThreshold(ctx *interfaces.ApplicationContext[dto.PayoutTwoFactorThresholdDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	err := services.SetPayoutTwoFactorThreshold(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body.Threshold, ctx.Body.Code)
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "payout threshold updated", nil, nil)
+}
@@ -10,6 +10,7 @@ import (
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
 	"kego.com/application/repository"
+	"kego.com/application/services"
 	userusecases "kego.com/application/usecases/userUseCases"
 	"kego.com/infrastructure/logger"
 	server_response "kego.com/infrastructure/serverResponse"
@@ -65,4 +66,25 @@ func SetPaymentTag(ctx *interfaces.ApplicationContext[dto.SetPaymentTagDTO]){
 		return
 	}
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "Your payment tag has been set successfully", nil, nil)
-}
\ No newline at end of file
+}
+
+func FetchNotificationPreferences(ctx *interfaces.ApplicationContext[any]){
+	preferences := services.FetchNotificationPreferences(ctx.Ctx, ctx.GetStringContextData("UserID"))
+	if preferences == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "notification preferences fetched", preferences, nil)
+}
+
+func UpdateNotificationPreferences(ctx *interfaces.ApplicationContext[dto.NotificationPreferencesDTO]){
+	validationErr := validator.ValidatorInstance.ValidateStruct(ctx.Body)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx.Ctx, validationErr)
+		return
+	}
+	preferences := services.UpdateNotificationPreferences(ctx.Ctx, ctx.GetStringContextData("UserID"), ctx.Body)
+	if preferences == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "notification preferences updated", preferences, nil)
+}
@@ -1,79 +1,60 @@
 package controllers
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"time"
 
 	apperrors "kego.com/application/appErrors"
 	bankssupported "kego.com/application/banksSupported"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
-	"kego.com/application/repository"
+	"kego.com/application/money"
 	"kego.com/application/services"
 	"kego.com/application/utils"
 	"kego.com/entities"
-	"kego.com/infrastructure/messaging/emails"
-	pushnotification "kego.com/infrastructure/messaging/push_notifications"
-	international_payment_processor "kego.com/infrastructure/payment_processor/chimoney"
-	"kego.com/infrastructure/payment_processor/types"
 	server_response "kego.com/infrastructure/serverResponse"
 )
 
 func InitiateBusinessInternationalPayment(ctx *interfaces.ApplicationContext[dto.SendPaymentDTO]){
-	if ctx.Body.Amount < 1000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send less than ₦10", nil)
-		return
-	}
-	if ctx.Body.Amount >= 30000000000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send more than ₦300,000,000 at a time", nil)
+	ctx.Body.DefaultDestinationCurrency()
+	if ctx.Body.QuoteID == "" {
+		apperrors.ClientError(ctx.Ctx, "Request a quote for this payment before sending it", nil)
 		return
 	}
 	businessID := ctx.GetStringParameter("businessID") 
-	rates, statusCode, err := international_payment_processor.InternationalPaymentProcessor.GetExchangeRates(ctx.Body.DestinationCountryCode, ctx.Body.Amount)
-	if err != nil {
-		apperrors.ExternalDependencyError(ctx.Ctx, "chimoney", fmt.Sprintf("%d", statusCode), err)
+	quote := services.FindInternationalPaymentQuote(ctx.Ctx, ctx.Body.QuoteID, ctx.GetStringContextData("UserID"), businessID, ctx.Body.DestinationCountryCode, ctx.Body.Amount)
+	if quote == nil {
 		return
 	}
-	if statusCode != 200 {
-		apperrors.UnknownError(ctx.Ctx)
+	totalAmount := quote.TotalAmount
+	wallet := authorizeBusinessPayout(ctx, businessID, totalAmount)
+	if wallet == nil {
 		return
 	}
-	amountInNGN := utils.Float32ToUint64Currency((*rates)["convertedValue"])
-	internationalProcessorFee, transactionFee  := utils.GetInternationalTransactionFee(amountInNGN)
-	totalAmount := internationalProcessorFee + transactionFee + amountInNGN
-	wallet , err := services.InitiatePreAuth(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), totalAmount, ctx.Body.Pin)
-	if err != nil {
+	approvalRule, ok := services.PayoutApprovalRuleFor(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), totalAmount)
+	if !ok {
 		return
 	}
-	err = services.LockFunds(ctx.Ctx, wallet, totalAmount, entities.ChimoneyDebitInternational)
+	lockedFunds, err := services.LockFunds(ctx.Ctx, wallet, totalAmount, entities.ChimoneyDebitInternational)
 	if err != nil {
 		return
 	}
-	destinationCountry := utils.CountryCodeToCountryName(ctx.Body.DestinationCountryCode)
-	if os.Getenv("GIN_MODE") != "release" {
-		destinationCountry = utils.CountryCodeToCountryName("NG")
-	}
-	response := services.InitiateInternationalPayment(ctx.Ctx, &international_payment_processor.InternationalPaymentRequestPayload{
-		DestinationCountry: destinationCountry,
-		AccountNumber: ctx.Body.AccountNumber,
-		BankCode: ctx.Body.BankCode,
-		ValueInUSD: (*rates)["convertToUSD"],
-	})
-	if response == nil {
+	// only used up once the payout has been authorised and its funds locked, so the quote can be tried again if either fails
+	if services.ConsumeInternationalPaymentQuote(ctx.Ctx, quote) == nil {
+		services.UnlockFunds(wallet.ID, *lockedFunds)
 		return
 	}
 	transaction := entities.Transaction{
-		TransactionReference: response.Chimoneys[0].ChiRef,
-		MetaData: response.Chimoneys[0],
-		AmountInUSD: utils.GetUInt64Pointer(utils.Float32ToUint64Currency(response.Chimoneys[0].ValueInUSD)),
+		AmountInUSD: &quote.ValueInUSD,
 		AmountInNGN: totalAmount,
-		Fee: transactionFee,
-		ProcessorFeeCurrency: "USD",
-		ProcessorFee: internationalProcessorFee,
-		Amount: ctx.Body.Amount,
-		Currency: utils.CurrencyCodeToCurrencySymbol(ctx.Body.DestinationCountryCode),
+		Fee: quote.Fee,
+		ProcessorFee: quote.ProcessorFee,
+		PricingPlan: &quote.PricingPlan,
+		Amount: quote.Amount,
+		ExchangeRate: &quote.Rate,
+		ExchangeRateSnapshotID: &quote.ExchangeRateSnapshotID,
 		WalletID: wallet.ID,
 		UserID: wallet.UserID,
 		BusinessID: wallet.BusinessID,
@@ -88,6 +69,7 @@ func InitiateBusinessInternationalPayment(ctx *interfaces.ApplicationContext[dto
 			IPAddress: ctx.Body.IPAddress,
 		},
 		Intent: entities.ChimoneyDebitInternational,
+		Status: entities.TransactionPending,
 		DeviceInfo: entities.DeviceInfo{
 			IPAddress: ctx.Body.IPAddress,
 			DeviceID: ctx.GetStringContextData("DeviceID"),
@@ -106,43 +88,26 @@ func InitiateBusinessInternationalPayment(ctx *interfaces.ApplicationContext[dto
 			BranchCode: ctx.Body.BranchCode,
 			Country: ctx.Body.DestinationCountryCode,
 		},
+		APIKeyID: apiKeyID(ctx.Keys),
+		LockedFundsID: &lockedFunds.LockedFundsID,
+	}
+	if approvalRule != nil {
+		holdPayoutForApproval(ctx.Ctx, ctx.GetStringContextData("UserID"), approvalRule, lockedFunds, &transaction, &quote.ExpiresAt)
+		return
+	}
+	trx, err := services.SendInternationalPayout(ctx.Ctx, &transaction)
+	if errors.Is(err, services.ErrPayoutNotSent) {
+		services.UnlockFunds(wallet.ID, *lockedFunds)
 	}
-	trxRepository := repository.TransactionRepo()
-	trx, err := trxRepository.CreateOne(context.TODO(), transaction)
 	if err != nil {
-		apperrors.FatalServerError(ctx.Ctx)
 		return
 	}
-	pushnotification.PushNotificationService.PushOne(ctx.GetStringContextData("DeviceID"), "Your payment is on its way! 🚀",
-		fmt.Sprintf("Your payment of %s%d to %s in %s is currently being processed.", utils.CurrencyCodeToCurrencySymbol(transaction.Currency), transaction.Amount, transaction.Recepient.Name, utils.CountryCodeToCountryName(transaction.Recepient.Country)))
-	emails.EmailService.SendEmail(ctx.GetStringContextData("Email"), "Your payment is on its way! 🚀", "payment_sent", map[string]any{
-		"FIRSTNAME": transaction.Sender.FirstName,
-		"CURRENCY_CODE": utils.CurrencyCodeToCurrencySymbol(ctx.Body.DestinationCountryCode),
-		"AMOUNT": utils.UInt64ToFloat32Currency(ctx.Body.Amount),
-		"RECEPIENT_NAME": transaction.Recepient.Name,
-		"RECEPIENT_COUNTRY": utils.CountryCodeToCountryName(transaction.Recepient.Country),
-	})
 	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "Your payment is on its way! 🚀", trx, nil)
 }
 
 func InitiateBusinessLocalPayment(ctx *interfaces.ApplicationContext[dto.SendPaymentDTO]){
-	if ctx.Body.Amount < 1000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send less than ₦10", nil)
-		return
-	}
-	if ctx.Body.Amount >= 30000000000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send more than ₦300,000,000 at a time", nil)
-		return
-	}
-	businessID := ctx.GetStringParameter("businessID") 
-	localProcessorFee, polymerFee := utils.GetLocalTransactionFee(ctx.Body.Amount)
-	totalAmount := ctx.Body.Amount + utils.Float32ToUint64Currency(localProcessorFee) + utils.Float32ToUint64Currency(polymerFee)
-	wallet , err := services.InitiatePreAuth(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), totalAmount, ctx.Body.Pin)
-	if err != nil {
-		return
-	}
-	err = services.LockFunds(ctx.Ctx, wallet, totalAmount, entities.FlutterwaveDebitLocal)
-	if err != nil {
+	ctx.Body.DefaultLocalCurrency()
+	if !verifyLocalPaymentAmount(ctx.Ctx, ctx.Body.Amount) {
 		return
 	}
 	bankName := ""
@@ -156,44 +121,49 @@ func InitiateBusinessLocalPayment(ctx *interfaces.ApplicationContext[dto.SendPay
 		apperrors.NotFoundError(ctx.Ctx, "Selected bank is not currently supported")
 		return
 	}
-	narration := func () string {
-		if	ctx.Body.Description == nil {
-			des := fmt.Sprintf("NGN Transfer from %s %s to %s", ctx.GetStringContextData("FirstName"), ctx.GetStringContextData("LastName"), bankName)
-			return des
-		}
-		return *ctx.Body.Description
-	}()
-	reference := utils.GenerateUUIDString()
-	response := services.InitiateLocalPayment(ctx.Ctx, &types.InitiateLocalTransferPayload{
-		AccountNumber: ctx.Body.AccountNumber,
-		AccountBank: ctx.Body.BankCode,
-		Currency: "NGN",
-		Amount: totalAmount,
-		Narration: narration ,
-		Reference: reference,
-		DebitCurrency: "NGN",
-		CallbackURL: "https://webhook.site/e5cf956e-63a9-4beb-8960-1b45f61fc42c",
-	})
-	if response == nil {
+	businessID := ctx.GetStringParameter("businessID") 
+	fees := services.CalculateTransactionFees(ctx.Ctx, businessID, entities.FlutterwaveDebitLocal, "NG", ctx.Body.Amount)
+	if fees == nil {
+		return
+	}
+	totalAmount, err := fees.Total(ctx.Body.Amount)
+	if err != nil {
+		apperrors.ClientError(ctx.Ctx, err.Error(), nil)
+		return
+	}
+	wallet := authorizeBusinessPayout(ctx, businessID, totalAmount)
+	if wallet == nil {
+		return
+	}
+	approvalRule, ok := services.PayoutApprovalRuleFor(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), totalAmount)
+	if !ok {
+		return
+	}
+	lockedFunds, err := services.LockFunds(ctx.Ctx, wallet, totalAmount, entities.FlutterwaveDebitLocal)
+	if err != nil {
 		return
 	}
 	transaction := entities.Transaction{
-		TransactionReference: reference,
-		MetaData: response,
 		AmountInNGN: totalAmount,
-		Fee: utils.Float32ToUint64Currency(polymerFee),
-		ProcessorFeeCurrency: "NGN",
-		ProcessorFee: utils.Float32ToUint64Currency(localProcessorFee),
-		Amount: totalAmount,
-		Currency: "NGN",
+		Fee: fees.PlatformFee,
+		ProcessorFee: fees.ProcessorFee,
+		PricingPlan: &fees.PricingPlan,
+		Amount: ctx.Body.Amount,
 		WalletID: wallet.ID,
 		UserID: wallet.UserID,
 		BusinessID: wallet.BusinessID,
-		Description: narration,
+		Description: func () string {
+			if	ctx.Body.Description == nil {
+				des := fmt.Sprintf("NGN Transfer from %s %s to %s", ctx.GetStringContextData("FirstName"), ctx.GetStringContextData("LastName"), bankName)
+				return des
+			}
+			return *ctx.Body.Description
+		}(),
 		Location: entities.Location{
 			IPAddress: ctx.Body.IPAddress,
 		},
 		Intent: entities.FlutterwaveDebitLocal,
+		Status: entities.TransactionPending,
 		DeviceInfo: entities.DeviceInfo{
 			IPAddress: ctx.Body.IPAddress,
 			DeviceID: ctx.GetStringContextData("DeviceID"),
@@ -206,64 +176,146 @@ func InitiateBusinessLocalPayment(ctx *interfaces.ApplicationContext[dto.SendPay
 			Email: ctx.GetStringContextData("Email"),
 		},
 		Recepient: entities.TransactionRecepient{
-			Name: response.FullName,
+			Name: func () string {
+				if ctx.Body.FullName == nil {
+					return ""
+				}
+				return *ctx.Body.FullName
+			}(),
 			BankCode: ctx.Body.BankCode,
 			AccountNumber: ctx.Body.AccountNumber,
 			BranchCode: ctx.Body.BranchCode,
 			BankName: bankName,
 			Country: "Nigeria",
 		},
+		APIKeyID: apiKeyID(ctx.Keys),
+		LockedFundsID: &lockedFunds.LockedFundsID,
+	}
+	if approvalRule != nil {
+		holdPayoutForApproval(ctx.Ctx, ctx.GetStringContextData("UserID"), approvalRule, lockedFunds, &transaction, nil)
+		return
+	}
+	trx, err := services.SendLocalPayout(ctx.Ctx, &transaction)
+	if errors.Is(err, services.ErrPayoutNotSent) {
+		services.UnlockFunds(wallet.ID, *lockedFunds)
 	}
-	trxRepository := repository.TransactionRepo()
-	trx, err := trxRepository.CreateOne(context.TODO(), transaction)
 	if err != nil {
-		apperrors.FatalServerError(ctx.Ctx)
 		return
 	}
-	pushnotification.PushNotificationService.PushOne(ctx.GetStringContextData("DeviceID"), "Your payment is on its way! 🚀",
-		fmt.Sprintf("Your payment of %s%d to %s in %s is currently being processed.", utils.CurrencyCodeToCurrencySymbol(transaction.Currency), transaction.Amount, transaction.Recepient.Name, utils.CountryCodeToCountryName(transaction.Recepient.Country)))
-	emails.EmailService.SendEmail(ctx.GetStringContextData("Email"), "Your payment is on its way! 🚀", "payment_sent", map[string]any{
-		"FIRSTNAME": transaction.Sender.FirstName,
-		"CURRENCY_CODE": utils.CurrencyCodeToCurrencySymbol("NGN"),
-		"AMOUNT": utils.UInt64ToFloat32Currency(ctx.Body.Amount),
-		"RECEPIENT_NAME": transaction.Recepient.Name,
-		"RECEPIENT_COUNTRY": utils.CountryCodeToCountryName(transaction.Recepient.Country),
-	})
 	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "Your payment is on its way! 🚀", trx, nil)
 }
 
+// authorizeBusinessPayout checks the payout can be made from the business's wallet. Payouts made with an API key
+// have no pin, two-factor code or otp, the key's IP allowlist stands in for them.
+func authorizeBusinessPayout(ctx *interfaces.ApplicationContext[dto.SendPaymentDTO], businessID string, amount money.Money) *entities.Wallet {
+	if apiKeyID(ctx.Keys) != nil {
+		wallet, err := services.InitiateAPIKeyPreAuth(ctx.Ctx, businessID, amount)
+		if err != nil {
+			return nil
+		}
+		return wallet
+	}
+	wallet , err := services.InitiatePreAuth(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), amount, ctx.Body.Pin)
+	if err != nil {
+		return nil
+	}
+	if !services.VerifyPayoutConfirmation(ctx.Ctx, ctx.GetStringContextData("UserID"), amount, services.PayoutConfirmation{
+		TOTPCode: ctx.Body.TOTPCode,
+		OTP: ctx.Body.OTP,
+		IPAddress: ctx.Body.IPAddress,
+	}) {
+		return nil
+	}
+	return wallet
+}
+
+func apiKeyID(keys map[string]any) *string {
+	id, _ := keys["APIKeyID"].(string)
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+// holdPayoutForApproval keeps the payout's funds locked until enough members of the business approve it.
+func holdPayoutForApproval(ctx any, userID string, rule *entities.PayoutApprovalRule, lockedFunds *entities.LockedFunds, transaction *entities.Transaction, quoteExpiresAt *time.Time) {
+	approval := services.HoldPayoutForApproval(ctx, userID, *rule, *lockedFunds, *transaction, quoteExpiresAt)
+	if approval == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx, http.StatusAccepted, "Your payment is waiting to be approved", approval, nil)
+}
+
 func BusinessLocalPaymentFee(ctx *interfaces.ApplicationContext[dto.SendPaymentDTO]){
-	if ctx.Body.Amount < 1000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send less than ₦10", nil)
+	ctx.Body.DefaultLocalCurrency()
+	if !verifyLocalPaymentAmount(ctx.Ctx, ctx.Body.Amount) {
 		return
 	}
-	if ctx.Body.Amount >= 30000000000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send more than ₦300,000,000 at a time", nil)
+	fees := services.CalculateTransactionFees(ctx.Ctx, ctx.GetStringParameter("businessID"), entities.FlutterwaveDebitLocal, "NG", ctx.Body.Amount)
+	if fees == nil {
 		return
 	}
-	localProcessorFee, polymerFee := utils.GetLocalTransactionFee(ctx.Body.Amount)
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "fee calculated", map[string]any{
-		"processorFee": utils.Float32ToUint64Currency(localProcessorFee),
-		"polymerFee": utils.Float32ToUint64Currency(polymerFee),
+		"processorFee": fees.ProcessorFee,
+		"polymerFee": fees.PlatformFee,
+		"vat": fees.VAT,
 	}, nil)
 }
 
 func BusinessInternationalPaymentFee(ctx *interfaces.ApplicationContext[dto.SendPaymentDTO]){
-	if ctx.Body.Amount < 1000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send less than ₦10", nil)
+	ctx.Body.DefaultDestinationCurrency()
+	if currency := utils.CountryCodeToCurrencyCode(ctx.Body.DestinationCountryCode); currency == "" || currency != ctx.Body.Amount.Currency {
+		apperrors.ClientError(ctx.Ctx, fmt.Sprintf("Payments to %s must be made in the local currency", ctx.Body.DestinationCountryCode), nil)
+		return
+	}
+	conversion, _ := services.ConvertToNGN(ctx.Ctx, ctx.Body.Amount)
+	if conversion == nil {
+		return
+	}
+	if !services.VerifyTransferAmountLimits(ctx.Ctx, conversion.ValueInNGN) {
 		return
 	}
-	if ctx.Body.Amount >= 30000000000 {
-		apperrors.ClientError(ctx.Ctx, "You cannot send more than ₦300,000,000 at a time", nil)
+	fees := services.CalculateTransactionFees(ctx.Ctx, ctx.GetStringParameter("businessID"), entities.ChimoneyDebitInternational, ctx.Body.DestinationCountryCode, conversion.ValueInNGN)
+	if fees == nil {
 		return
 	}
-	internationalProcessorFee, polymerFee := utils.GetInternationalTransactionFee(ctx.Body.Amount)
 	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "fee calculated", map[string]any{
-		"processorFee": internationalProcessorFee,
-		"polymerFee": polymerFee,
+		"processorFee": fees.ProcessorFee,
+		"polymerFee": fees.PlatformFee,
+		"vat": fees.VAT,
 	}, nil)
 }
 
+func BusinessInternationalPaymentQuote(ctx *interfaces.ApplicationContext[dto.InternationalPaymentQuoteDTO]){
+	ctx.Body.DefaultDestinationCurrency()
+	businessID := ctx.GetStringParameter("businessID")
+	_, err := services.GetWalletByBusinessID(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"))
+	if err != nil {
+		return
+	}
+	quote := services.CreateInternationalPaymentQuote(ctx.Ctx, ctx.GetStringContextData("UserID"), businessID, ctx.Body.DestinationCountryCode, ctx.Body.Amount)
+	if quote == nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusCreated, "quote created", quote, nil)
+}
+
+func FetchBusinessWallet(ctx *interfaces.ApplicationContext[any]){
+	wallet, err := services.GetWalletByBusinessID(ctx.Ctx, ctx.GetStringParameter("businessID"), ctx.GetStringContextData("UserID"))
+	if err != nil {
+		return
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "wallet fetched", wallet, nil)
+}
+
+func verifyLocalPaymentAmount(ctx any, amount money.Money) bool {
+	if amount.Currency != "NGN" {
+		apperrors.ClientError(ctx, "Local payments must be made in naira", nil)
+		return false
+	}
+	return services.VerifyTransferAmountLimits(ctx, amount)
+}
+
 func VerifyLocalAccountName(ctx *interfaces.ApplicationContext[dto.NameVerificationDTO]){
 	bankCode := ""
 	for _, bank := range bankssupported.SupportedLocalBanks {
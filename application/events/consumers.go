@@ -0,0 +1,420 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/messaging/emails"
+	pushnotification "kego.com/infrastructure/messaging/push_notifications"
+	"kego.com/infrastructure/messaging/sms"
+)
+
+// A consumer handles the events it subscribes to. Handle may be called more than once
+// for the same event if an earlier attempt failed, so consumers should tolerate repeats.
+type Consumer interface {
+	Name() string
+	Subscribes(eventType string) bool
+	Handle(event *entities.OutboxEvent) error
+}
+
+// Consumers that reach the user on a channel they can opt out of implement this
+// so the worker can check the user's preferences before handing them an event.
+type channelConsumer interface {
+	Channel() entities.NotificationChannel
+}
+
+var consumers = []Consumer{
+	&emailConsumer{},
+	&pushConsumer{},
+	&smsConsumer{},
+	&inboxConsumer{},
+}
+
+func findConsumer(name string) Consumer {
+	for _, consumer := range consumers {
+		if consumer.Name() == name {
+			return consumer
+		}
+	}
+	return nil
+}
+
+type emailConsumer struct{}
+
+func (c *emailConsumer) Name() string {
+	return "email"
+}
+
+func (c *emailConsumer) Channel() entities.NotificationChannel {
+	return entities.EmailChannel
+}
+
+func (c *emailConsumer) Subscribes(eventType string) bool {
+	return eventType == PaymentSent || eventType == OTPRequested || eventType == SecurityAlert || eventType == BusinessKYBReviewed || eventType == BusinessMemberInvited || eventType == PayoutApprovalRequested || eventType == PayoutApprovalDecided
+}
+
+func (c *emailConsumer) Handle(event *entities.OutboxEvent) error {
+	switch event.Type {
+	case PaymentSent:
+		payload, err := DecodePayload[PaymentSentPayload](event)
+		if err != nil {
+			return err
+		}
+		title, _ := payload.Message()
+		return emails.EmailService.SendEmail(payload.Email, title, "payment_sent", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"AMOUNT": payload.Amount.Format(),
+			"RECEPIENT_NAME": payload.RecipientName,
+			"RECEPIENT_COUNTRY": utils.CountryCodeToCountryName(payload.RecipientCountry),
+		})
+	case OTPRequested:
+		payload, err := DecodePayload[OTPRequestedPayload](event)
+		if err != nil {
+			return err
+		}
+		return emails.EmailService.SendEmail(payload.Email, payload.Subject, "otp", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"OTP": payload.OTP,
+		})
+	case SecurityAlert:
+		payload, err := DecodePayload[SecurityAlertPayload](event)
+		if err != nil {
+			return err
+		}
+		return emails.EmailService.SendEmail(payload.Email, payload.Title, "security_alert", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"TITLE": payload.Title,
+			"BODY": payload.Body,
+			"SUPPORT_EMAIL": constants.SUPPORT_EMAIL,
+		})
+	case BusinessKYBReviewed:
+		payload, err := DecodePayload[BusinessKYBReviewedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return emails.EmailService.SendEmail(payload.Email, title, "business_kyb_reviewed", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"TITLE": title,
+			"BODY": body,
+			"SUPPORT_EMAIL": constants.SUPPORT_EMAIL,
+		})
+	case BusinessMemberInvited:
+		payload, err := DecodePayload[BusinessMemberInvitedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return emails.EmailService.SendEmail(payload.Email, title, "business_member_invited", map[string]any{
+			"TITLE": title,
+			"BODY": body,
+			"SUPPORT_EMAIL": constants.SUPPORT_EMAIL,
+		})
+	case PayoutApprovalRequested:
+		payload, err := DecodePayload[PayoutApprovalRequestedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return emails.EmailService.SendEmail(payload.Email, title, "payout_approval", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"TITLE": title,
+			"BODY": body,
+			"SUPPORT_EMAIL": constants.SUPPORT_EMAIL,
+		})
+	case PayoutApprovalDecided:
+		payload, err := DecodePayload[PayoutApprovalDecidedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return emails.EmailService.SendEmail(payload.Email, title, "payout_approval", map[string]any{
+			"FIRSTNAME": payload.FirstName,
+			"TITLE": title,
+			"BODY": body,
+			"SUPPORT_EMAIL": constants.SUPPORT_EMAIL,
+		})
+	}
+	return fmt.Errorf("email consumer cannot handle %s events", event.Type)
+}
+
+type pushConsumer struct{}
+
+func (c *pushConsumer) Name() string {
+	return "push"
+}
+
+func (c *pushConsumer) Channel() entities.NotificationChannel {
+	return entities.PushChannel
+}
+
+func (c *pushConsumer) Subscribes(eventType string) bool {
+	return eventType == PaymentSent || eventType == SecurityAlert || eventType == BusinessKYBReviewed || eventType == BusinessMemberInvited || eventType == PayoutApprovalRequested || eventType == PayoutApprovalDecided
+}
+
+func (c *pushConsumer) Handle(event *entities.OutboxEvent) error {
+	switch event.Type {
+	case PaymentSent:
+		payload, err := DecodePayload[PaymentSentPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	case SecurityAlert:
+		payload, err := DecodePayload[SecurityAlertPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	case BusinessKYBReviewed:
+		payload, err := DecodePayload[BusinessKYBReviewedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	case BusinessMemberInvited:
+		payload, err := DecodePayload[BusinessMemberInvitedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	case PayoutApprovalRequested:
+		payload, err := DecodePayload[PayoutApprovalRequestedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	case PayoutApprovalDecided:
+		payload, err := DecodePayload[PayoutApprovalDecidedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		return pushToDevices(event.UserID, title, body)
+	}
+	return fmt.Errorf("push consumer cannot handle %s events", event.Type)
+}
+
+// pushToDevices fans the notification out to every device the user has used recently
+// and forgets the push tokens FCM says are no longer valid.
+func pushToDevices(userID string, title string, body string) error {
+	deviceRepository := repository.DeviceRepo()
+	devices, err := deviceRepository.FindMany(map[string]interface{}{
+		"userID": userID,
+		"pushToken": map[string]any{
+			"$ne": nil,
+		},
+		"lastSeenAt": map[string]any{
+			"$gte": nowFunc().Add(-constants.DEVICE_ACTIVE_PERIOD),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	tokens := []string{}
+	for _, device := range *devices {
+		tokens = append(tokens, *device.PushToken)
+	}
+	if len(tokens) == 0 {
+		return ErrNoRecipient
+	}
+	invalidTokens, err := pushnotification.PushNotificationService.PushMany(tokens, title, body)
+	if len(invalidTokens) != 0 {
+		deviceRepository.UpdateManyWithOperator(map[string]interface{}{
+			"userID": userID,
+			"pushToken": map[string]any{
+				"$in": invalidTokens,
+			},
+		}, map[string]any{
+			"$set": map[string]any{
+				"pushToken": nil,
+			},
+		})
+	}
+	if err == nil && len(invalidTokens) == len(tokens) {
+		return ErrNoRecipient
+	}
+	return err
+}
+
+type smsConsumer struct{}
+
+func (c *smsConsumer) Name() string {
+	return "sms"
+}
+
+func (c *smsConsumer) Channel() entities.NotificationChannel {
+	return entities.SMSChannel
+}
+
+func (c *smsConsumer) Subscribes(eventType string) bool {
+	return eventType == PaymentSent || eventType == OTPRequested || eventType == SecurityAlert
+}
+
+func (c *smsConsumer) Handle(event *entities.OutboxEvent) error {
+	var message string
+	switch event.Type {
+	case PaymentSent:
+		payload, err := DecodePayload[PaymentSentPayload](event)
+		if err != nil {
+			return err
+		}
+		_, message = payload.Message()
+	case OTPRequested:
+		payload, err := DecodePayload[OTPRequestedPayload](event)
+		if err != nil {
+			return err
+		}
+		message = payload.Message()
+	case SecurityAlert:
+		payload, err := DecodePayload[SecurityAlertPayload](event)
+		if err != nil {
+			return err
+		}
+		_, message = payload.Message()
+	default:
+		return fmt.Errorf("sms consumer cannot handle %s events", event.Type)
+	}
+	// the number is looked up when sending so it is not kept in the outbox and changes to it are respected
+	user, err := repository.UserRepo().FindByID(event.UserID, options.FindOne().SetProjection(map[string]any{
+		"phone": 1,
+	}))
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Phone.LocalNumber == "" {
+		return ErrNoRecipient
+	}
+	return sms.SMSService.SendSMS(user.Phone.ParsePhoneNumber(), message)
+}
+
+// Keeps a copy of every notification in the user's inbox.
+type inboxConsumer struct{}
+
+func (c *inboxConsumer) Name() string {
+	return "inbox"
+}
+
+func (c *inboxConsumer) Subscribes(eventType string) bool {
+	return eventType == PaymentSent || eventType == SecurityAlert || eventType == BusinessKYBReviewed || eventType == BusinessMemberInvited || eventType == PayoutApprovalRequested || eventType == PayoutApprovalDecided
+}
+
+func (c *inboxConsumer) Handle(event *entities.OutboxEvent) error {
+	var notification entities.Notification
+	switch event.Type {
+	case PaymentSent:
+		payload, err := DecodePayload[PaymentSentPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+			Link: &entities.NotificationLink{
+				Type: entities.TransactionNotificationLink,
+				ID: payload.TransactionID,
+				BusinessID: payload.BusinessID,
+			},
+		}
+	case SecurityAlert:
+		payload, err := DecodePayload[SecurityAlertPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+		}
+	case BusinessKYBReviewed:
+		payload, err := DecodePayload[BusinessKYBReviewedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+			Link: &entities.NotificationLink{
+				Type: entities.BusinessNotificationLink,
+				ID: payload.BusinessID,
+				BusinessID: &payload.BusinessID,
+			},
+		}
+	case BusinessMemberInvited:
+		payload, err := DecodePayload[BusinessMemberInvitedPayload](event)
+		if err != nil {
+			return err
+		}
+		if payload.UserID == "" {
+			return ErrNoRecipient
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+			Link: &entities.NotificationLink{
+				Type: entities.BusinessInviteNotificationLink,
+				ID: payload.InviteID,
+				BusinessID: &payload.BusinessID,
+			},
+		}
+	case PayoutApprovalRequested:
+		payload, err := DecodePayload[PayoutApprovalRequestedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+			Link: &entities.NotificationLink{
+				Type: entities.PayoutApprovalNotificationLink,
+				ID: payload.ApprovalID,
+				BusinessID: &payload.BusinessID,
+			},
+		}
+	case PayoutApprovalDecided:
+		payload, err := DecodePayload[PayoutApprovalDecidedPayload](event)
+		if err != nil {
+			return err
+		}
+		title, body := payload.Message()
+		notification = entities.Notification{
+			UserID: payload.UserID,
+			Title: title,
+			Body: body,
+			Link: &entities.NotificationLink{
+				Type: entities.PayoutApprovalNotificationLink,
+				ID: payload.ApprovalID,
+				BusinessID: &payload.BusinessID,
+			},
+		}
+	default:
+		return fmt.Errorf("inbox consumer cannot handle %s events", event.Type)
+	}
+	notification.EventID = event.EventID
+	notification.Type = event.Type
+	_, err := repository.NotificationRepo().CreateOne(nil, notification)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		// delivered on an earlier attempt
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+)
+
+const (
+	PaymentSent  = "payment.sent"
+	OTPRequested = "otp.requested"
+	SecurityAlert = "security.alert"
+	BusinessKYBReviewed = "business.kyb_reviewed"
+	BusinessMemberInvited = "business.member_invited"
+	PayoutApprovalRequested = "payout.approval_requested"
+	PayoutApprovalDecided = "payout.approval_decided"
+)
+
+// An event payload. Payloads are stored as BSON in the outbox until every consumer has handled them.
+type Event interface {
+	EventType() string
+	// the user the event is about, used to honour their notification preferences
+	Recipient() string
+	Category() entities.NotificationCategory
+}
+
+// Payloads that should only reach the user on some channels implement this.
+// Consumers on other channels are not given the event at all.
+type targetedEvent interface {
+	Delivers(channel entities.NotificationChannel) bool
+}
+
+// Payloads holding secrets implement this so they are removed from the outbox once handled.
+type sensitiveEvent interface {
+	Sensitive() bool
+}
+
+type PaymentSentPayload struct {
+	TransactionID 	 string 		`bson:"transactionID"`
+	UserID 			 string 		`bson:"userID"`
+	BusinessID 		 *string 		`bson:"businessID"`
+	Email 			 string 		`bson:"email"`
+	FirstName 		 string 		`bson:"firstName"`
+	Amount 			 money.Money 	`bson:"amount"`
+	RecipientName 	 string 		`bson:"recipientName"`
+	RecipientCountry string 		`bson:"recipientCountry"`
+}
+
+func (PaymentSentPayload) EventType() string {
+	return PaymentSent
+}
+
+func (payload PaymentSentPayload) Recipient() string {
+	return payload.UserID
+}
+
+func (PaymentSentPayload) Category() entities.NotificationCategory {
+	return entities.TransactionsNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload PaymentSentPayload) Message() (string, string) {
+	return "Your payment is on its way! 🚀", fmt.Sprintf("Your payment of %s to %s in %s is currently being processed.", payload.Amount.Format(), payload.RecipientName, utils.CountryCodeToCountryName(payload.RecipientCountry))
+}
+
+type OTPRequestedPayload struct {
+	UserID 		string `bson:"userID"`
+	Email 		string `bson:"email"`
+	FirstName 	string `bson:"firstName"`
+	OTP 		string `bson:"otp"`
+	Subject 	string `bson:"subject"`
+	// empty means email only
+	Channels 	[]entities.NotificationChannel `bson:"channels"`
+}
+
+func (OTPRequestedPayload) EventType() string {
+	return OTPRequested
+}
+
+func (payload OTPRequestedPayload) Recipient() string {
+	return payload.UserID
+}
+
+func (OTPRequestedPayload) Category() entities.NotificationCategory {
+	return entities.SecurityNotification
+}
+
+func (payload OTPRequestedPayload) Delivers(channel entities.NotificationChannel) bool {
+	if len(payload.Channels) == 0 {
+		return channel == entities.EmailChannel
+	}
+	for _, c := range payload.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is the text sent to the user by SMS.
+func (payload OTPRequestedPayload) Message() string {
+	return fmt.Sprintf("Your Kego verification code is %s. Do not share it with anyone.", payload.OTP)
+}
+
+func (OTPRequestedPayload) Sensitive() bool {
+	return true
+}
+
+// Tells the user about a change to how their account is secured, e.g. a new transaction pin.
+type SecurityAlertPayload struct {
+	UserID 		string `bson:"userID"`
+	Email 		string `bson:"email"`
+	FirstName 	string `bson:"firstName"`
+	Title 		string `bson:"title"`
+	Body 		string `bson:"body"`
+}
+
+func (SecurityAlertPayload) EventType() string {
+	return SecurityAlert
+}
+
+func (payload SecurityAlertPayload) Recipient() string {
+	return payload.UserID
+}
+
+func (SecurityAlertPayload) Category() entities.NotificationCategory {
+	return entities.SecurityNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload SecurityAlertPayload) Message() (string, string) {
+	return payload.Title, payload.Body
+}
+
+// Tells the owner of a business how the review of its KYB went.
+type BusinessKYBReviewedPayload struct {
+	UserID 			string 		`bson:"userID"`
+	Email 			string 		`bson:"email"`
+	FirstName 		string 		`bson:"firstName"`
+	BusinessID 		string 		`bson:"businessID"`
+	BusinessName 	string 		`bson:"businessName"`
+	Approved 		bool 		`bson:"approved"`
+	Reason 			*string 	`bson:"reason"`
+}
+
+func (BusinessKYBReviewedPayload) EventType() string {
+	return BusinessKYBReviewed
+}
+
+func (payload BusinessKYBReviewedPayload) Recipient() string {
+	return payload.UserID
+}
+
+// KYB decides how much a business can pay out so it is sent with transaction notifications.
+func (BusinessKYBReviewedPayload) Category() entities.NotificationCategory {
+	return entities.TransactionsNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload BusinessKYBReviewedPayload) Message() (string, string) {
+	if payload.Approved {
+		return fmt.Sprintf("%s has been verified", payload.BusinessName), fmt.Sprintf("%s has passed our business checks and its payout limits have been raised.", payload.BusinessName)
+	}
+	body := fmt.Sprintf("We could not verify %s.", payload.BusinessName)
+	if payload.Reason != nil {
+		body = fmt.Sprintf("%s %s", body, *payload.Reason)
+	}
+	return fmt.Sprintf("%s could not be verified", payload.BusinessName), fmt.Sprintf("%s You can update its details and submit it again.", body)
+}
+
+// Sent to someone who has been invited to a business. They may not have a Kego account yet.
+type BusinessMemberInvitedPayload struct {
+	// empty when no account uses the invited email yet, so only the email is sent
+	UserID 			string 					`bson:"userID"`
+	Email 			string 					`bson:"email"`
+	InviteID 		string 					`bson:"inviteID"`
+	BusinessID 		string 					`bson:"businessID"`
+	BusinessName 	string 					`bson:"businessName"`
+	InvitedBy 		string 					`bson:"invitedBy"` // the inviter's name
+	Role 			entities.BusinessRole 	`bson:"role"`
+}
+
+func (BusinessMemberInvitedPayload) EventType() string {
+	return BusinessMemberInvited
+}
+
+func (payload BusinessMemberInvitedPayload) Recipient() string {
+	return payload.UserID
+}
+
+// access to a business's money is sent whatever the user's preferences are
+func (BusinessMemberInvitedPayload) Category() entities.NotificationCategory {
+	return entities.SecurityNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload BusinessMemberInvitedPayload) Message() (string, string) {
+	return fmt.Sprintf("You have been invited to %s", payload.BusinessName), fmt.Sprintf("%s has invited you to join %s on Kego as %s %s. Sign in to the app with this email address to accept.", payload.InvitedBy, payload.BusinessName, article(string(payload.Role)), payload.Role)
+}
+
+// Asks a member of a business to approve a payout someone else has made.
+type PayoutApprovalRequestedPayload struct {
+	UserID 			string 		 `bson:"userID"`
+	Email 			string 		 `bson:"email"`
+	FirstName 		string 		 `bson:"firstName"`
+	ApprovalID 		string 		 `bson:"approvalID"`
+	BusinessID 		string 		 `bson:"businessID"`
+	BusinessName 	string 		 `bson:"businessName"`
+	RequesterName 	string 		 `bson:"requesterName"`
+	Amount 			money.Money  `bson:"amount"`
+	RecipientName 	string 		 `bson:"recipientName"`
+	ExpiresAt 		time.Time 	 `bson:"expiresAt"`
+}
+
+func (PayoutApprovalRequestedPayload) EventType() string {
+	return PayoutApprovalRequested
+}
+
+func (payload PayoutApprovalRequestedPayload) Recipient() string {
+	return payload.UserID
+}
+
+func (PayoutApprovalRequestedPayload) Category() entities.NotificationCategory {
+	return entities.TransactionsNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload PayoutApprovalRequestedPayload) Message() (string, string) {
+	recipient := ""
+	if payload.RecipientName != "" {
+		recipient = fmt.Sprintf(" to %s", payload.RecipientName)
+	}
+	return fmt.Sprintf("A payment from %s needs your approval", payload.BusinessName), fmt.Sprintf("%s wants to send %s%s from %s. Approve or reject it in the app before %s.", payload.RequesterName, payload.Amount.Format(), recipient, payload.BusinessName, payload.ExpiresAt.Format(time.RFC1123))
+}
+
+// Tells the member who made a payout what became of it once it no longer needs approving.
+type PayoutApprovalDecidedPayload struct {
+	UserID 			string 						 `bson:"userID"`
+	Email 			string 						 `bson:"email"`
+	FirstName 		string 						 `bson:"firstName"`
+	ApprovalID 		string 						 `bson:"approvalID"`
+	BusinessID 		string 						 `bson:"businessID"`
+	BusinessName 	string 						 `bson:"businessName"`
+	Amount 			money.Money 				 `bson:"amount"`
+	Status 			entities.PayoutApprovalStatus `bson:"status"`
+	Reason 			*string 					 `bson:"reason"`
+}
+
+func (PayoutApprovalDecidedPayload) EventType() string {
+	return PayoutApprovalDecided
+}
+
+func (payload PayoutApprovalDecidedPayload) Recipient() string {
+	return payload.UserID
+}
+
+func (PayoutApprovalDecidedPayload) Category() entities.NotificationCategory {
+	return entities.TransactionsNotification
+}
+
+// Message is the title and body shown to the user for the event.
+func (payload PayoutApprovalDecidedPayload) Message() (string, string) {
+	switch payload.Status {
+	case entities.PayoutApprovalSent:
+		return "Your payment has been approved", fmt.Sprintf("Your payment of %s from %s has been approved and is on its way.", payload.Amount.Format(), payload.BusinessName)
+	case entities.PayoutRejected:
+		body := fmt.Sprintf("Your payment of %s from %s was rejected and the money is back in the wallet.", payload.Amount.Format(), payload.BusinessName)
+		if payload.Reason != nil && *payload.Reason != "" {
+			body = fmt.Sprintf("%s Reason: %s", body, *payload.Reason)
+		}
+		return "Your payment was rejected", body
+	case entities.PayoutApprovalExpired:
+		return "Your payment was not approved in time", fmt.Sprintf("Your payment of %s from %s was not approved in time and the money is back in the wallet.", payload.Amount.Format(), payload.BusinessName)
+	}
+	return "Your payment could not be sent", fmt.Sprintf("Your payment of %s from %s was approved but could not be sent. The money is back in the wallet.", payload.Amount.Format(), payload.BusinessName)
+}
+
+func article(word string) string {
+	if strings.ContainsAny(word[:1], "aeiouAEIOU") {
+		return "an"
+	}
+	return "a"
+}
+
+// Publish writes the event to the outbox for every consumer subscribed to it.
+// Pass the session context of a Mongo transaction to publish the event only if the transaction commits.
+// A nil context publishes the event on its own.
+func Publish(ctx context.Context, event Event) error {
+	payload, err := bson.Marshal(event)
+	if err != nil {
+		return err
+	}
+	sensitive := false
+	if s, ok := event.(sensitiveEvent); ok {
+		sensitive = s.Sensitive()
+	}
+	targeted, isTargeted := event.(targetedEvent)
+	eventID := utils.GenerateUUIDString()
+	outboxRepository := repository.OutboxEventRepo()
+	for _, consumer := range consumers {
+		if !consumer.Subscribes(event.EventType()) {
+			continue
+		}
+		if channel, ok := consumer.(channelConsumer); ok && isTargeted && !targeted.Delivers(channel.Channel()) {
+			continue
+		}
+		_, err = outboxRepository.CreateOne(ctx, entities.OutboxEvent{
+			EventID: eventID,
+			Type: event.EventType(),
+			UserID: event.Recipient(),
+			Category: event.Category(),
+			Consumer: consumer.Name(),
+			Payload: payload,
+			Sensitive: sensitive,
+			Status: entities.OutboxEventPending,
+			NextAttemptAt: nowFunc(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodePayload reads an outbox event's payload into the type it was published as.
+func DecodePayload[T Event](event *entities.OutboxEvent) (*T, error) {
+	var payload T
+	if err := bson.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"kego.com/application/repository"
+	"kego.com/entities"
+)
+
+// FindNotificationPreferences returns the user's preferences, or the defaults if they have never changed them.
+func FindNotificationPreferences(userID string) (*entities.NotificationPreferences, error) {
+	preferences, err := repository.NotificationPreferencesRepo().FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		defaults := entities.DefaultNotificationPreferences(userID)
+		return &defaults, nil
+	}
+	return preferences, nil
+}
+
+// OTPChannels resolves where an OTP should be sent. delivery is what the user asked for on this request,
+// and when it is empty their saved preference is used. SMS is dropped for users without a phone number.
+func OTPChannels(userID string, hasPhone bool, delivery entities.OTPDelivery) ([]entities.NotificationChannel, error) {
+	if delivery == "" && userID != "" {
+		preferences, err := FindNotificationPreferences(userID)
+		if err != nil {
+			return nil, err
+		}
+		delivery = preferences.OTPDelivery
+	}
+	channels := []entities.NotificationChannel{}
+	for _, channel := range delivery.Channels() {
+		if channel == entities.SMSChannel && !hasPhone {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	if len(channels) == 0 {
+		channels = append(channels, entities.EmailChannel)
+	}
+	return channels, nil
+}
+
+// allowed checks the event against the preferences of the user it is for. Every email, push and SMS
+// notification goes through the outbox, so this is the one place preferences are enforced.
+func allowed(consumer Consumer, event *entities.OutboxEvent) (bool, error) {
+	channel, ok := consumer.(channelConsumer)
+	if !ok || event.Category == "" || event.Category == entities.SecurityNotification || event.UserID == "" {
+		return true, nil
+	}
+	preferences, err := FindNotificationPreferences(event.UserID)
+	if err != nil {
+		return false, err
+	}
+	return preferences.Allows(channel.Channel(), event.Category), nil
+}
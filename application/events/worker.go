@@ -0,0 +1,208 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+var nowFunc = time.Now
+
+// StartWorker delivers due outbox events to their consumers in the background.
+func StartWorker() {
+	go func() {
+		ticker := time.NewTicker(constants.OUTBOX_POLL_INTERVAL)
+		defer ticker.Stop()
+		for range ticker.C {
+			deliverDueEvents()
+		}
+	}()
+}
+
+func deliverDueEvents() {
+	now := nowFunc()
+	due, err := repository.OutboxEventRepo().FindMany(map[string]interface{}{
+		"status": entities.OutboxEventPending,
+		"nextAttemptAt": map[string]any{
+			"$lte": now,
+		},
+		"$or": []map[string]any{
+			{"lockedUntil": nil},
+			{"lockedUntil": map[string]any{"$lt": now}},
+		},
+	}, options.Find().SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).SetLimit(constants.OUTBOX_BATCH_SIZE))
+	if err != nil {
+		return
+	}
+	for _, event := range *due {
+		if !claim(&event) {
+			continue
+		}
+		deliver(&event)
+	}
+}
+
+// claim locks the event so other instances of the worker skip it while it is being delivered.
+func claim(event *entities.OutboxEvent) bool {
+	now := nowFunc()
+	affected, err := repository.OutboxEventRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": event.ID,
+		"status": entities.OutboxEventPending,
+		"$or": []map[string]any{
+			{"lockedUntil": nil},
+			{"lockedUntil": map[string]any{"$lt": now}},
+		},
+	}, map[string]any{
+		"$set": map[string]any{
+			"lockedUntil": now.Add(constants.OUTBOX_LOCK_DURATION),
+		},
+	})
+	return err == nil && affected == 1
+}
+
+func deliver(event *entities.OutboxEvent) {
+	var err error
+	skipped := false
+	consumer := findConsumer(event.Consumer)
+	if consumer == nil {
+		err = fmt.Errorf("no consumer named %s is registered", event.Consumer)
+	} else {
+		var ok bool
+		ok, err = allowed(consumer, event)
+		if err == nil && !ok {
+			skipped = true
+		} else if err == nil {
+			err = handle(consumer, event)
+			if errors.Is(err, ErrNoRecipient) {
+				skipped = true
+				err = nil
+			}
+		}
+	}
+	attempts := event.Attempts + 1
+	update := map[string]any{
+		"$set": map[string]any{
+			"attempts": attempts,
+			"lockedUntil": nil,
+		},
+	}
+	set := update["$set"].(map[string]any)
+	if skipped {
+		set["status"] = entities.OutboxEventSkipped
+	} else if err == nil {
+		set["status"] = entities.OutboxEventDelivered
+		set["deliveredAt"] = nowFunc()
+	} else if attempts >= constants.OUTBOX_MAX_ATTEMPTS {
+		set["status"] = entities.OutboxEventDead
+		lastError := err.Error()
+		set["lastError"] = lastError
+		_, dlqErr := repository.DeadLetterEventRepo().CreateOne(nil, entities.DeadLetterEvent{
+			OutboxEventID: event.ID,
+			EventID: event.EventID,
+			Type: event.Type,
+			Consumer: event.Consumer,
+			Attempts: attempts,
+			LastError: &lastError,
+		})
+		if dlqErr != nil {
+			// leave the event pending so it is dead lettered on the next attempt
+			delete(set, "status")
+		}
+		logger.Error(errors.New("outbox event moved to the dead letter store"), logger.LoggerOptions{
+			Key: "eventID",
+			Data: event.EventID,
+		}, logger.LoggerOptions{
+			Key: "consumer",
+			Data: event.Consumer,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	} else {
+		set["lastError"] = err.Error()
+		set["nextAttemptAt"] = nowFunc().Add(backoff(attempts))
+	}
+	if event.Sensitive && set["status"] != nil {
+		update["$unset"] = map[string]any{
+			"payload": "",
+		}
+	}
+	repository.OutboxEventRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": event.ID,
+	}, update)
+}
+
+// handle stops a panicking consumer from taking the worker down with it.
+func handle(consumer Consumer, event *entities.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer %s panicked: %v", consumer.Name(), r)
+		}
+	}()
+	return consumer.Handle(event)
+}
+
+// backoff doubles the wait after every failed attempt up to the maximum.
+func backoff(attempts int) time.Duration {
+	wait := constants.OUTBOX_BASE_BACKOFF
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= constants.OUTBOX_MAX_BACKOFF {
+			return constants.OUTBOX_MAX_BACKOFF
+		}
+	}
+	return wait
+}
+
+// Requeue sends a dead lettered event back to its consumer.
+func Requeue(deadLetterID string) error {
+	deadLetter, err := repository.DeadLetterEventRepo().FindByID(deadLetterID)
+	if err != nil {
+		return err
+	}
+	if deadLetter == nil {
+		return ErrDeadLetterNotFound
+	}
+	if deadLetter.Requeued {
+		return ErrDeadLetterRequeued
+	}
+	event, err := repository.OutboxEventRepo().FindByID(deadLetter.OutboxEventID)
+	if err != nil {
+		return err
+	}
+	if event == nil || event.Payload == nil {
+		return ErrPayloadRemoved
+	}
+	_, err = repository.OutboxEventRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": event.ID,
+	}, map[string]any{
+		"$set": map[string]any{
+			"status": entities.OutboxEventPending,
+			"attempts": 0,
+			"nextAttemptAt": nowFunc(),
+			"lockedUntil": nil,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = repository.DeadLetterEventRepo().UpdatePartialByID(deadLetter.ID, map[string]any{
+		"requeued": true,
+	})
+	return err
+}
+
+var (
+	// returned by consumers when the user has no address on their channel, e.g. no phone number for SMS
+	ErrNoRecipient        = errors.New("the user cannot be reached on this channel")
+	ErrDeadLetterNotFound = errors.New("dead letter event not found")
+	ErrDeadLetterRequeued = errors.New("this event has already been requeued")
+	ErrPayloadRemoved     = errors.New("this event held sensitive data that has been removed so it cannot be requeued")
+)
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"strings"
+
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	"kego.com/entities"
+)
+
+// APIKeyMiddleware authenticates a business's own systems by the API key in the Authorization header.
+// It stands in for the user agent and authentication middlewares, so it sets the same context the routes behind it expect.
+func APIKeyMiddleware(ctx *interfaces.ApplicationContext[any], businessID string, ipAddress string, permission entities.BusinessPermission) (*interfaces.ApplicationContext[any], bool) {
+	secret := ""
+	if header, ok := ctx.GetHeader("Authorization").(string); ok {
+		secret = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	key, creator := services.AuthenticateBusinessAPIKey(ctx.Ctx, businessID, secret, ipAddress, permission)
+	if key == nil {
+		return nil, false
+	}
+	userAgent, _ := ctx.GetHeader("User-Agent").(string)
+	ctx.SetContextData("UserID", creator.ID)
+	ctx.SetContextData("APIKeyID", key.ID)
+	ctx.SetContextData("LastName", creator.LastName)
+	ctx.SetContextData("FirstName", creator.FirstName)
+	ctx.SetContextData("Email", creator.Email)
+	ctx.SetContextData("DeviceID", "")
+	ctx.SetContextData("UserAgent", userAgent)
+	return ctx, true
+}
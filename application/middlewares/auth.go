@@ -16,7 +16,7 @@ import (
 )
 
 
-func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfaces.ApplicationContext[any], bool) {
+func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any], adminRoute bool) (*interfaces.ApplicationContext[any], bool) {
 	auth_token_header := ctx.GetHeader("Authorization")
 		if auth_token_header == "" || auth_token_header == nil {
 			apperrors.AuthenticationError(ctx.Ctx, "provide an auth token")
@@ -38,8 +38,14 @@ func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfa
 			apperrors.AuthenticationError(ctx.Ctx, "this is not an authorized access token")
 			return nil, false
 		}
-		valid_token := cache.Cache.FindOne(auth_token_claims["userID"].(string))
-		if valid_token == nil {
+		sessionID, _ := auth_token_claims["sessionID"].(string)
+		if sessionID == "" {
+			// issued before sessions were introduced
+			apperrors.AuthenticationError(ctx.Ctx, "this session has expired")
+			return nil, false
+		}
+		valid_token := cache.Cache.FindOne(auth.AccessTokenCacheKey(sessionID))
+		if valid_token == nil || *valid_token != auth_token {
 			apperrors.AuthenticationError(ctx.Ctx, "this session has expired")
 			return nil, false
 		}
@@ -49,6 +55,7 @@ func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfa
 			"userAgent": 1,
 			"deviceID": 1,
 			"appVersion": 1,
+			"admin": 1,
 		}))
 		if account == nil {
 			apperrors.NotFoundError(ctx.Ctx, "this account no longer exists")
@@ -58,9 +65,19 @@ func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfa
 			apperrors.AuthenticationError(ctx.Ctx, "account has been deactivated")
 			return nil, false
 		}
+		if adminRoute && !account.Admin {
+			logger.Warning("non admin user attempted to access an admin route", logger.LoggerOptions{
+				Key: "userID",
+				Data: account.ID,
+			})
+			apperrors.ForbiddenError(ctx.Ctx, "you do not have access to this resource")
+			return nil, false
+		}
 
 		userAgent := ctx.GetHeader("User-Agent").(string)
-		if auth_token_claims["appVersion"] != account.AppVersion || account.AppVersion != *utils.ExtractAppVersionFromUserAgentHeader(userAgent) ||  auth_token_claims["appVersion"] != *utils.ExtractAppVersionFromUserAgentHeader(userAgent) {
+		// the account only holds the details of the last device signed in, so requests are checked against the session's token
+		requestAppVersion := utils.ExtractAppVersionFromUserAgentHeader(userAgent)
+		if requestAppVersion == nil || auth_token_claims["appVersion"] != *requestAppVersion {
 			logger.Warning("client made request using app version different from that in access token", logger.LoggerOptions{
 				Key: "token appVersion",
 				Data: auth_token_claims["appVersion"],
@@ -69,19 +86,19 @@ func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfa
 				Data: account.AppVersion,
 			}, logger.LoggerOptions{
 				Key: "request appVersion",
-				Data: *utils.ExtractAppVersionFromUserAgentHeader(userAgent),
+				Data: requestAppVersion,
 			})
-			auth.SignOutUser(ctx.Ctx, account.ID, "client made request using app version different from that in access token")
+			auth.SignOutUser(ctx.Ctx, sessionID, "client made request using app version different from that in access token")
 			apperrors.AuthenticationError(ctx.Ctx, "unauthorized access")
 			return nil, false
 		}
 		deviceID := ctx.GetHeader("Polymer-Device-Id")
 		if deviceID == nil {
-			auth.SignOutUser(ctx.Ctx, account.ID, "client made request without a device id")
+			auth.SignOutUser(ctx.Ctx, sessionID, "client made request without a device id")
 			apperrors.AuthenticationError(ctx.Ctx, "unauthorized access")
 			return nil, false
 		}
-		if auth_token_claims["deviceID"] != account.DeviceID || account.DeviceID != deviceID.(string) ||  auth_token_claims["deviceID"] != deviceID.(string) {
+		if auth_token_claims["deviceID"] != deviceID.(string) {
 			logger.Warning("client made request using device id different from that in access token",logger.LoggerOptions{
 				Key: "token appVersion",
 				Data: auth_token_claims["appVersion"],
@@ -90,14 +107,15 @@ func AuthenticationMiddleware(ctx *interfaces.ApplicationContext[any]) (*interfa
 				Data: account.AppVersion,
 			}, logger.LoggerOptions{
 				Key: "request appVersion",
-				Data: *utils.ExtractAppVersionFromUserAgentHeader(userAgent),
+				Data: requestAppVersion,
 			})
-			auth.SignOutUser(ctx.Ctx, account.ID, "client made request using device id different from that in access token")
+			auth.SignOutUser(ctx.Ctx, sessionID, "client made request using device id different from that in access token")
 			apperrors.AuthenticationError(ctx.Ctx, "unauthorized access")
 			return nil, false
 		}
 
 		ctx.SetContextData("UserID", auth_token_claims["userID"])
+		ctx.SetContextData("SessionID", sessionID)
 		ctx.SetContextData("LastName", auth_token_claims["lastName"])
 		ctx.SetContextData("FirstName", auth_token_claims["firstName"])
 		ctx.SetContextData("Email", auth_token_claims["email"])
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	"kego.com/entities"
+)
+
+// BusinessMemberMiddleware lets the request through if the signed in user's role in the business allows the permission.
+// Their role is kept on the context as BusinessRole.
+func BusinessMemberMiddleware(ctx *interfaces.ApplicationContext[any], businessID string, permission entities.BusinessPermission) (*interfaces.ApplicationContext[any], bool) {
+	role, ok := services.AuthorizeBusinessMember(ctx.Ctx, businessID, ctx.GetStringContextData("UserID"), permission)
+	if !ok {
+		return nil, false
+	}
+	ctx.SetContextData("BusinessRole", string(*role))
+	return ctx, true
+}
@@ -0,0 +1,46 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ISO 4217 metadata for the currencies we hold or pay out in.
+type Currency struct {
+	Code       string
+	Numeric    string
+	Name       string
+	Symbol     string
+	MinorUnits uint8
+}
+
+var currencies = map[string]Currency{
+	"NGN": {Code: "NGN", Numeric: "566", Name: "Nigerian Naira", Symbol: "₦", MinorUnits: 2},
+	"USD": {Code: "USD", Numeric: "840", Name: "US Dollar", Symbol: "$", MinorUnits: 2},
+	"GBP": {Code: "GBP", Numeric: "826", Name: "Pound Sterling", Symbol: "£", MinorUnits: 2},
+	"EUR": {Code: "EUR", Numeric: "978", Name: "Euro", Symbol: "€", MinorUnits: 2},
+	"CAD": {Code: "CAD", Numeric: "124", Name: "Canadian Dollar", Symbol: "CA$", MinorUnits: 2},
+	"GHS": {Code: "GHS", Numeric: "936", Name: "Ghana Cedi", Symbol: "GH₵", MinorUnits: 2},
+	"INR": {Code: "INR", Numeric: "356", Name: "Indian Rupee", Symbol: "₹", MinorUnits: 2},
+	"KES": {Code: "KES", Numeric: "404", Name: "Kenyan Shilling", Symbol: "KSh", MinorUnits: 2},
+	"ZAR": {Code: "ZAR", Numeric: "710", Name: "South African Rand", Symbol: "R", MinorUnits: 2},
+	"MXN": {Code: "MXN", Numeric: "484", Name: "Mexican Peso", Symbol: "MX$", MinorUnits: 2},
+	"RWF": {Code: "RWF", Numeric: "646", Name: "Rwanda Franc", Symbol: "FRw", MinorUnits: 0},
+}
+
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// Returns 10^MinorUnits, the number of minor units in one major unit.
+func (c Currency) scale() int64 {
+	scale := int64(1)
+	for i := uint8(0); i < c.MinorUnits; i++ {
+		scale *= 10
+	}
+	return scale
+}
@@ -0,0 +1,243 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount is too large")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// An amount held as an integer number of minor units (kobo, cents) of an ISO 4217 currency.
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency" validate:"iso4217"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func NGN(amount int64) Money {
+	return New(amount, "NGN")
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// ParseDecimal reads a major unit amount such as "1234.565" and rounds it to the
+// currency's minor units using banker's rounding.
+func ParseDecimal(value string, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrInvalidAmount, value)
+	}
+	rat.Mul(rat, new(big.Rat).SetInt64(c.scale()))
+	amount, err := roundHalfEven(rat)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, c.Code), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return strings.EqualFold(m.Currency, other.Currency)
+}
+
+func (m Money) assertSameCurrency(other Money) error {
+	if !m.SameCurrency(other) {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) || (other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(New(-other.Amount, other.Currency))
+}
+
+// Sum adds every amount passed to m. All amounts must share m's currency.
+func (m Money) Sum(others ...Money) (Money, error) {
+	total := m
+	for _, other := range others {
+		var err error
+		total, err = total.Add(other)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func (m Money) Multiply(multiplier int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(multiplier))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return New(product.Int64(), m.Currency), nil
+}
+
+// Cmp returns -1, 0 or 1 if m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) LessThan(other Money) (bool, error) {
+	cmp, err := m.Cmp(other)
+	return cmp < 0, err
+}
+
+// ApplyBasisPoints returns the share of m given by a rate in basis points (100 is 1%), using banker's rounding.
+func (m Money) ApplyBasisPoints(basisPoints int64) (Money, error) {
+	share := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(basisPoints)), big.NewInt(10000))
+	amount, err := roundHalfEven(share)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Convert applies a rate quoted as units of the target currency per one major unit of m's currency.
+// The result is rounded to the target currency's minor units using banker's rounding.
+func (m Money) Convert(rate Rate, currency string) (Money, error) {
+	if rate.IsZero() {
+		return Money{}, ErrInvalidRate
+	}
+	source, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	target, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Mul(value, rate.value)
+	value.Mul(value, new(big.Rat).SetFrac64(target.scale(), source.scale()))
+	amount, err := roundHalfEven(value)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, target.Code), nil
+}
+
+// Decimal writes the amount in major units, e.g. "1234.50".
+func (m Money) Decimal() string {
+	currency, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+	return new(big.Rat).SetFrac64(m.Amount, currency.scale()).FloatString(int(currency.MinorUnits))
+}
+
+// Format writes the amount for people to read, e.g. "₦1,234.50".
+func (m Money) Format() string {
+	currency, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return m.String()
+	}
+	decimal := m.Decimal()
+	sign := ""
+	if strings.HasPrefix(decimal, "-") {
+		sign = "-"
+		decimal = decimal[1:]
+	}
+	whole, fraction, _ := strings.Cut(decimal, ".")
+	grouped := []string{}
+	for len(whole) > 3 {
+		grouped = append([]string{whole[len(whole)-3:]}, grouped...)
+		whole = whole[:len(whole)-3]
+	}
+	grouped = append([]string{whole}, grouped...)
+	formatted := sign + currency.Symbol + strings.Join(grouped, ",")
+	if fraction != "" {
+		formatted += "." + fraction
+	}
+	return formatted
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Currency, m.Decimal())
+}
+
+// UnmarshalJSON also accepts a bare number of minor units, which is how amounts were sent before they carried
+// their currency. The currency is left empty for the caller to fill in from context.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var minorUnits int64
+	if err := json.Unmarshal(data, &minorUnits); err == nil {
+		*m = Money{Amount: minorUnits}
+		return nil
+	}
+	type plain Money
+	var value plain
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*m = Money(value)
+	return nil
+}
+
+// roundHalfEven rounds to the nearest integer, sending ties to the even neighbour.
+func roundHalfEven(value *big.Rat) (int64, error) {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	switch twiceRemainder.Cmp(value.Denom()) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
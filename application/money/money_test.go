@@ -0,0 +1,269 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     Money
+		err      error
+	}{
+		{name: "whole amount", value: "1234", currency: "NGN", want: NGN(123400)},
+		{name: "minor units", value: "1234.56", currency: "NGN", want: NGN(123456)},
+		{name: "surrounding space", value: " 10.5 ", currency: "usd", want: New(1050, "USD")},
+		{name: "tie rounds down to even", value: "1.245", currency: "NGN", want: NGN(124)},
+		{name: "tie rounds up to even", value: "1.235", currency: "NGN", want: NGN(124)},
+		{name: "above tie rounds up", value: "1.2451", currency: "NGN", want: NGN(125)},
+		{name: "below tie rounds down", value: "1.2449", currency: "NGN", want: NGN(124)},
+		{name: "negative tie rounds to even", value: "-1.245", currency: "NGN", want: NGN(-124)},
+		{name: "negative rounds away from zero", value: "-1.2451", currency: "NGN", want: NGN(-125)},
+		{name: "negative amount", value: "-20.00", currency: "NGN", want: NGN(-2000)},
+		{name: "currency without minor units", value: "1500.5", currency: "RWF", want: New(1500, "RWF")},
+		{name: "currency without minor units rounds up", value: "1501.5", currency: "RWF", want: New(1502, "RWF")},
+		{name: "unknown currency", value: "10", currency: "XYZ", err: ErrUnknownCurrency},
+		{name: "not a number", value: "ten", currency: "NGN", err: ErrInvalidAmount},
+		{name: "empty", value: "", currency: "NGN", err: ErrInvalidAmount},
+		{name: "too large", value: "100000000000000000000", currency: "NGN", err: ErrOverflow},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseDecimal(test.value, test.currency)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("ParseDecimal(%q, %q) error = %v, want %v", test.value, test.currency, err, test.err)
+			}
+			if got != test.want {
+				t.Errorf("ParseDecimal(%q, %q) = %v, want %v", test.value, test.currency, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddAndSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Money
+		b       Money
+		wantAdd Money
+		wantSub Money
+		err     error
+	}{
+		{name: "same currency", a: NGN(1000), b: NGN(250), wantAdd: NGN(1250), wantSub: NGN(750)},
+		{name: "currency is case insensitive", a: NGN(1000), b: Money{Amount: 1, Currency: "ngn"}, wantAdd: NGN(1001), wantSub: NGN(999)},
+		{name: "negative amounts", a: NGN(-500), b: NGN(-250), wantAdd: NGN(-750), wantSub: NGN(-250)},
+		{name: "result below zero", a: NGN(100), b: NGN(300), wantAdd: NGN(400), wantSub: NGN(-200)},
+		{name: "mismatched currencies", a: NGN(100), b: New(100, "USD"), err: ErrCurrencyMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sum, err := test.a.Add(test.b)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("%v.Add(%v) error = %v, want %v", test.a, test.b, err, test.err)
+			}
+			if sum != test.wantAdd {
+				t.Errorf("%v.Add(%v) = %v, want %v", test.a, test.b, sum, test.wantAdd)
+			}
+			difference, err := test.a.Sub(test.b)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("%v.Sub(%v) error = %v, want %v", test.a, test.b, err, test.err)
+			}
+			if difference != test.wantSub {
+				t.Errorf("%v.Sub(%v) = %v, want %v", test.a, test.b, difference, test.wantSub)
+			}
+		})
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Money, error)
+	}{
+		{name: "add past max", op: func() (Money, error) { return NGN(math.MaxInt64).Add(NGN(1)) }},
+		{name: "add past min", op: func() (Money, error) { return NGN(math.MinInt64).Add(NGN(-1)) }},
+		{name: "sub min", op: func() (Money, error) { return NGN(0).Sub(NGN(math.MinInt64)) }},
+		{name: "multiply past max", op: func() (Money, error) { return NGN(math.MaxInt64 / 2).Multiply(3) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.op(); !errors.Is(err, ErrOverflow) {
+				t.Errorf("error = %v, want %v", err, ErrOverflow)
+			}
+		})
+	}
+}
+
+func TestSum(t *testing.T) {
+	total, err := NGN(100).Sum(NGN(200), NGN(-50))
+	if err != nil || total != NGN(250) {
+		t.Errorf("Sum = %v, %v, want %v", total, err, NGN(250))
+	}
+	if _, err := NGN(100).Sum(NGN(200), New(1, "GBP")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum with mismatched currencies error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Money
+		b    Money
+		want int
+		err  error
+	}{
+		{name: "less", a: NGN(1), b: NGN(2), want: -1},
+		{name: "equal", a: NGN(2), b: NGN(2), want: 0},
+		{name: "greater", a: NGN(3), b: NGN(2), want: 1},
+		{name: "negative is less than zero", a: NGN(-1), b: NGN(0), want: -1},
+		{name: "mismatched currencies", a: NGN(1), b: New(1, "USD"), err: ErrCurrencyMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.a.Cmp(test.b)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("%v.Cmp(%v) error = %v, want %v", test.a, test.b, err, test.err)
+			}
+			if got != test.want {
+				t.Errorf("%v.Cmp(%v) = %d, want %d", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyBasisPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      Money
+		basisPoints int64
+		want        Money
+	}{
+		{name: "one percent", amount: NGN(100000), basisPoints: 100, want: NGN(1000)},
+		{name: "tie rounds down to even", amount: NGN(250), basisPoints: 100, want: NGN(2)},
+		{name: "tie rounds up to even", amount: NGN(350), basisPoints: 100, want: NGN(4)},
+		{name: "vat on a fee", amount: NGN(1075), basisPoints: 750, want: NGN(81)},
+		{name: "negative amount", amount: NGN(-350), basisPoints: 100, want: NGN(-4)},
+		{name: "zero rate", amount: NGN(1000), basisPoints: 0, want: NGN(0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.amount.ApplyBasisPoints(test.basisPoints)
+			if err != nil {
+				t.Fatalf("ApplyBasisPoints error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("%v.ApplyBasisPoints(%d) = %v, want %v", test.amount, test.basisPoints, got, test.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		rate     string
+		currency string
+		want     Money
+		err      error
+	}{
+		{name: "dollars to naira", amount: New(1000, "USD"), rate: "1523.45", currency: "NGN", want: NGN(1523450)},
+		{name: "tie rounds to even", amount: New(1050, "USD"), rate: "1523.45", currency: "NGN", want: NGN(1599622)},
+		{name: "into a currency without minor units", amount: New(100, "USD"), rate: "1300.5", currency: "RWF", want: New(1300, "RWF")},
+		{name: "from a currency without minor units", amount: New(1000, "RWF"), rate: "1.1", currency: "NGN", want: NGN(110000)},
+		{name: "negative amount", amount: New(-1050, "USD"), rate: "1523.45", currency: "NGN", want: NGN(-1599622)},
+		{name: "unknown target currency", amount: New(100, "USD"), rate: "1", currency: "XYZ", err: ErrUnknownCurrency},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rate, err := ParseRate(test.rate)
+			if err != nil {
+				t.Fatalf("ParseRate(%q) error = %v", test.rate, err)
+			}
+			got, err := test.amount.Convert(rate, test.currency)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Convert error = %v, want %v", err, test.err)
+			}
+			if got != test.want {
+				t.Errorf("%v.Convert(%s, %s) = %v, want %v", test.amount, test.rate, test.currency, got, test.want)
+			}
+		})
+	}
+	if _, err := New(100, "USD").Convert(Rate{}, "NGN"); !errors.Is(err, ErrInvalidRate) {
+		t.Errorf("Convert with a zero rate error = %v, want %v", err, ErrInvalidRate)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, value := range []string{"0", "-1.5", "abc", ""} {
+		if _, err := ParseRate(value); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("ParseRate(%q) error = %v, want %v", value, err, ErrInvalidRate)
+		}
+	}
+	rate, err := RateFromFloat(1523.45)
+	if err != nil || rate.String() != "1523.45" {
+		t.Errorf("RateFromFloat(1523.45) = %s, %v, want 1523.45", rate, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount  Money
+		decimal string
+		format  string
+	}{
+		{amount: NGN(0), decimal: "0.00", format: "₦0.00"},
+		{amount: NGN(5), decimal: "0.05", format: "₦0.05"},
+		{amount: NGN(123456789), decimal: "1234567.89", format: "₦1,234,567.89"},
+		{amount: NGN(-123456), decimal: "-1234.56", format: "-₦1,234.56"},
+		{amount: NGN(-5), decimal: "-0.05", format: "-₦0.05"},
+		{amount: New(1500000, "RWF"), decimal: "1500000", format: "FRw1,500,000"},
+		{amount: New(100, "XYZ"), decimal: "100", format: "XYZ 100"},
+	}
+	for _, test := range tests {
+		if got := test.amount.Decimal(); got != test.decimal {
+			t.Errorf("%d %s Decimal() = %q, want %q", test.amount.Amount, test.amount.Currency, got, test.decimal)
+		}
+		if got := test.amount.Format(); got != test.format {
+			t.Errorf("%d %s Format() = %q, want %q", test.amount.Amount, test.amount.Currency, got, test.format)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		want  Money
+		fails bool
+	}{
+		{name: "money document", body: `{"amount": 1050, "currency": "USD"}`, want: New(1050, "USD")},
+		{name: "bare minor units", body: `1050`, want: Money{Amount: 1050}},
+		{name: "negative minor units", body: `-1050`, want: Money{Amount: -1050}},
+		{name: "fractional minor units", body: `10.5`, fails: true},
+		{name: "string", body: `"10.50"`, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(test.body), &got)
+			if test.fails {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) returned no error", test.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", test.body, err)
+			}
+			if got != test.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", test.body, got, test.want)
+			}
+		})
+	}
+}
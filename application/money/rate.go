@@ -0,0 +1,69 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// The number of decimal places a rate keeps when it is written out as a string.
+const RatePrecision = 10
+
+// An exact decimal exchange rate. The zero value is not a valid rate.
+type Rate struct {
+	value *big.Rat
+}
+
+func ParseRate(value string) (Rate, error) {
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || rat.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+	return Rate{value: rat}, nil
+}
+
+// RateFromFloat reads a rate a provider sent as a JSON number using its shortest decimal form,
+// so 1523.45 is held as exactly 1523.45 rather than the closest binary float.
+func RateFromFloat(value float64) (Rate, error) {
+	return ParseRate(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (r Rate) IsZero() bool {
+	return r.value == nil || r.value.Sign() == 0
+}
+
+func (r Rate) Multiply(other Rate) (Rate, error) {
+	if r.IsZero() || other.IsZero() {
+		return Rate{}, ErrInvalidRate
+	}
+	return Rate{value: new(big.Rat).Mul(r.value, other.value)}, nil
+}
+
+func (r Rate) Divide(other Rate) (Rate, error) {
+	if r.IsZero() || other.IsZero() {
+		return Rate{}, ErrInvalidRate
+	}
+	return Rate{value: new(big.Rat).Quo(r.value, other.value)}, nil
+}
+
+func (r Rate) Float64() float64 {
+	if r.value == nil {
+		return 0
+	}
+	f, _ := r.value.Float64()
+	return f
+}
+
+func (r Rate) String() string {
+	if r.value == nil {
+		return "0"
+	}
+	s := r.value.FloatString(RatePrecision)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
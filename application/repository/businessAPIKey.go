@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var businessAPIKeyOnce = sync.Once{}
+
+var businessAPIKeyRepository mongo.MongoRepository[entities.BusinessAPIKey]
+
+func BusinessAPIKeyRepo() *mongo.MongoRepository[entities.BusinessAPIKey] {
+	businessAPIKeyOnce.Do(func() {
+		businessAPIKeyRepository = mongo.MongoRepository[entities.BusinessAPIKey]{Model: datastore.BusinessAPIKeyModel}
+	})
+	return &businessAPIKeyRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var businessMemberOnce = sync.Once{}
+
+var businessMemberRepository mongo.MongoRepository[entities.BusinessMember]
+
+func BusinessMemberRepo() *mongo.MongoRepository[entities.BusinessMember] {
+	businessMemberOnce.Do(func() {
+		businessMemberRepository = mongo.MongoRepository[entities.BusinessMember]{Model: datastore.BusinessMemberModel}
+	})
+	return &businessMemberRepository
+}
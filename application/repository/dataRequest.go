@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var dataRequestOnce = sync.Once{}
+
+var dataRequestRepository mongo.MongoRepository[entities.DataRequest]
+
+func DataRequestRepo() *mongo.MongoRepository[entities.DataRequest] {
+	dataRequestOnce.Do(func() {
+		dataRequestRepository = mongo.MongoRepository[entities.DataRequest]{Model: datastore.DataRequestModel}
+	})
+	return &dataRequestRepository
+}
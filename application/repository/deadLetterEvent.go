@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var deadLetterEventOnce = sync.Once{}
+
+var deadLetterEventRepository mongo.MongoRepository[entities.DeadLetterEvent]
+
+func DeadLetterEventRepo() *mongo.MongoRepository[entities.DeadLetterEvent] {
+	deadLetterEventOnce.Do(func() {
+		deadLetterEventRepository = mongo.MongoRepository[entities.DeadLetterEvent]{Model: datastore.DeadLetterEventModel}
+	})
+	return &deadLetterEventRepository
+}
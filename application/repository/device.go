@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var deviceOnce = sync.Once{}
+
+var deviceRepository mongo.MongoRepository[entities.Device]
+
+func DeviceRepo() *mongo.MongoRepository[entities.Device] {
+	deviceOnce.Do(func() {
+		deviceRepository = mongo.MongoRepository[entities.Device]{Model: datastore.DeviceModel}
+	})
+	return &deviceRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var exchangeRateSnapshotOnce = sync.Once{}
+
+var exchangeRateSnapshotRepository mongo.MongoRepository[entities.ExchangeRateSnapshot]
+
+func ExchangeRateSnapshotRepo() *mongo.MongoRepository[entities.ExchangeRateSnapshot] {
+	exchangeRateSnapshotOnce.Do(func() {
+		exchangeRateSnapshotRepository = mongo.MongoRepository[entities.ExchangeRateSnapshot]{Model: datastore.ExchangeRateSnapshotModel}
+	})
+	return &exchangeRateSnapshotRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var identityVerificationOnce = sync.Once{}
+
+var identityVerificationRepository mongo.MongoRepository[entities.IdentityVerification]
+
+func IdentityVerificationRepo() *mongo.MongoRepository[entities.IdentityVerification] {
+	identityVerificationOnce.Do(func() {
+		identityVerificationRepository = mongo.MongoRepository[entities.IdentityVerification]{Model: datastore.IdentityVerificationModel}
+	})
+	return &identityVerificationRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var internationalPaymentQuoteOnce = sync.Once{}
+
+var internationalPaymentQuoteRepository mongo.MongoRepository[entities.InternationalPaymentQuote]
+
+func InternationalPaymentQuoteRepo() *mongo.MongoRepository[entities.InternationalPaymentQuote] {
+	internationalPaymentQuoteOnce.Do(func() {
+		internationalPaymentQuoteRepository = mongo.MongoRepository[entities.InternationalPaymentQuote]{Model: datastore.InternationalPaymentQuoteModel}
+	})
+	return &internationalPaymentQuoteRepository
+}
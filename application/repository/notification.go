@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var notificationOnce = sync.Once{}
+
+var notificationRepository mongo.MongoRepository[entities.Notification]
+
+func NotificationRepo() *mongo.MongoRepository[entities.Notification] {
+	notificationOnce.Do(func() {
+		notificationRepository = mongo.MongoRepository[entities.Notification]{Model: datastore.NotificationModel}
+	})
+	return &notificationRepository
+}
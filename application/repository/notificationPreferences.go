@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var notificationPreferencesOnce = sync.Once{}
+
+var notificationPreferencesRepository mongo.MongoRepository[entities.NotificationPreferences]
+
+func NotificationPreferencesRepo() *mongo.MongoRepository[entities.NotificationPreferences] {
+	notificationPreferencesOnce.Do(func() {
+		notificationPreferencesRepository = mongo.MongoRepository[entities.NotificationPreferences]{Model: datastore.NotificationPreferencesModel}
+	})
+	return &notificationPreferencesRepository
+}
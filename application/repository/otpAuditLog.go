@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var otpAuditLogOnce = sync.Once{}
+
+var otpAuditLogRepository mongo.MongoRepository[entities.OTPAuditLog]
+
+func OTPAuditLogRepo() *mongo.MongoRepository[entities.OTPAuditLog] {
+	otpAuditLogOnce.Do(func() {
+		otpAuditLogRepository = mongo.MongoRepository[entities.OTPAuditLog]{Model: datastore.OTPAuditLogModel}
+	})
+	return &otpAuditLogRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var outboxEventOnce = sync.Once{}
+
+var outboxEventRepository mongo.MongoRepository[entities.OutboxEvent]
+
+func OutboxEventRepo() *mongo.MongoRepository[entities.OutboxEvent] {
+	outboxEventOnce.Do(func() {
+		outboxEventRepository = mongo.MongoRepository[entities.OutboxEvent]{Model: datastore.OutboxEventModel}
+	})
+	return &outboxEventRepository
+}
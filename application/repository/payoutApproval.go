@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var payoutApprovalOnce = sync.Once{}
+
+var payoutApprovalRepository mongo.MongoRepository[entities.PayoutApproval]
+
+func PayoutApprovalRepo() *mongo.MongoRepository[entities.PayoutApproval] {
+	payoutApprovalOnce.Do(func() {
+		payoutApprovalRepository = mongo.MongoRepository[entities.PayoutApproval]{Model: datastore.PayoutApprovalModel}
+	})
+	return &payoutApprovalRepository
+}
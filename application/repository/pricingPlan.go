@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var pricingPlanOnce = sync.Once{}
+
+var pricingPlanRepository mongo.MongoRepository[entities.PricingPlan]
+
+func PricingPlanRepo() *mongo.MongoRepository[entities.PricingPlan] {
+	pricingPlanOnce.Do(func() {
+		pricingPlanRepository = mongo.MongoRepository[entities.PricingPlan]{Model: datastore.PricingPlanModel}
+	})
+	return &pricingPlanRepository
+}
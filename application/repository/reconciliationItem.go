@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var reconciliationItemOnce = sync.Once{}
+
+var reconciliationItemRepository mongo.MongoRepository[entities.ReconciliationItem]
+
+func ReconciliationItemRepo() *mongo.MongoRepository[entities.ReconciliationItem] {
+	reconciliationItemOnce.Do(func() {
+		reconciliationItemRepository = mongo.MongoRepository[entities.ReconciliationItem]{Model: datastore.ReconciliationItemModel}
+	})
+	return &reconciliationItemRepository
+}
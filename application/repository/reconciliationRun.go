@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var reconciliationRunOnce = sync.Once{}
+
+var reconciliationRunRepository mongo.MongoRepository[entities.ReconciliationRun]
+
+func ReconciliationRunRepo() *mongo.MongoRepository[entities.ReconciliationRun] {
+	reconciliationRunOnce.Do(func() {
+		reconciliationRunRepository = mongo.MongoRepository[entities.ReconciliationRun]{Model: datastore.ReconciliationRunModel}
+	})
+	return &reconciliationRunRepository
+}
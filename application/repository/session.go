@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var sessionOnce = sync.Once{}
+
+var sessionRepository mongo.MongoRepository[entities.Session]
+
+func SessionRepo() *mongo.MongoRepository[entities.Session] {
+	sessionOnce.Do(func() {
+		sessionRepository = mongo.MongoRepository[entities.Session]{Model: datastore.SessionModel}
+	})
+	return &sessionRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"sync"
+
+	"kego.com/entities"
+	"kego.com/infrastructure/database/connection/datastore"
+	"kego.com/infrastructure/database/repository/mongo"
+)
+
+
+var twoFactorOnce = sync.Once{}
+
+var twoFactorRepository mongo.MongoRepository[entities.TwoFactor]
+
+func TwoFactorRepo() *mongo.MongoRepository[entities.TwoFactor] {
+	twoFactorOnce.Do(func() {
+		twoFactorRepository = mongo.MongoRepository[entities.TwoFactor]{Model: datastore.TwoFactorModel}
+	})
+	return &twoFactorRepository
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+	fileupload "kego.com/infrastructure/file_upload"
+	"kego.com/infrastructure/logger"
+)
+
+var (
+	ErrWalletNotEmpty 		= errors.New("Your account cannot be erased while there is money in any of your wallets. Send it out and try again.")
+	ErrPendingTransactions 	= errors.New("Your account cannot be erased while you have payments in progress. Try again once they are done.")
+)
+
+// RequestAccountErasure schedules the user's personal data to be erased once the grace period is over,
+// giving them time to change their mind.
+func RequestAccountErasure(ctx any, userID string, password string) *entities.DataRequest {
+	err := accountErasable(userID)
+	if errors.Is(err, ErrWalletNotEmpty) || errors.Is(err, ErrPendingTransactions) {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil
+	}
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	scheduledFor := time.Now().Add(constants.DATA_ERASURE_GRACE_PERIOD)
+	account, request := createDataRequest(ctx, userID, password, entities.DataErasureRequest, scheduledFor)
+	if request == nil {
+		return nil
+	}
+	notifyDataRequest(account, "Your account is scheduled to be erased", fmt.Sprintf("Your Kego account and personal data will be erased on %s. You can cancel this from the app until then.", scheduledFor.Format(time.RFC1123)))
+	return request
+}
+
+// accountErasable checks that erasing the user would not leave money or payments without an owner.
+func accountErasable(userID string) error {
+	wallets, err := repository.WalletRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	// payouts still pending after this long are no longer being settled, so they are not waited on
+	since := now.Add(-constants.TRANSACTION_SETTLEMENT_LOOKBACK)
+	pending, err := repository.TransactionRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+		"status": entities.TransactionPending,
+		"createdAt": map[string]any{
+			"$gte": since,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	walletIDs := []string{}
+	for _, wallet := range *wallets {
+		walletIDs = append(walletIDs, wallet.ID)
+	}
+	awaitingApproval, err := repository.PayoutApprovalRepo().CountDocs(map[string]interface{}{
+		"walletID": map[string]any{
+			"$in": walletIDs,
+		},
+		"status": map[string]any{
+			"$in": []entities.PayoutApprovalStatus{entities.PayoutAwaitingApproval, entities.PayoutApproved},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return erasureBlockedBy(*wallets, *pending, awaitingApproval, now)
+}
+
+// erasureBlockedBy returns why the wallets and payouts passed in stop an account being erased, if anything does.
+// Only payouts that can still move money count: funds locked for a payout until it settles, payouts the
+// processor has not finished within the settlement window and payouts waiting on a business's approvers.
+// Settled payouts never block erasure.
+func erasureBlockedBy(wallets []entities.Wallet, transactions []entities.Transaction, awaitingApproval int64, now time.Time) error {
+	since := now.Add(-constants.TRANSACTION_SETTLEMENT_LOOKBACK)
+	for _, wallet := range wallets {
+		if !wallet.Balance.IsZero() || !wallet.LedgerBalance.IsZero() {
+			return ErrWalletNotEmpty
+		}
+	}
+	for _, wallet := range wallets {
+		for _, lock := range wallet.LockedFundsLog {
+			// older locks were left by payouts recorded before payouts were settled
+			if lock.LockedAt.After(since) {
+				return ErrPendingTransactions
+			}
+		}
+	}
+	for _, transaction := range transactions {
+		if ledgerStatus(transaction) == entities.TransactionPending && transaction.CreatedAt.After(since) {
+			return ErrPendingTransactions
+		}
+	}
+	if awaitingApproval != 0 {
+		return ErrPendingTransactions
+	}
+	return nil
+}
+
+func processAccountErasure(request *entities.DataRequest) {
+	account, err := repository.UserRepo().FindByID(request.UserID)
+	if err == nil && account == nil {
+		err = errors.New("user not found")
+	}
+	if err != nil {
+		failAccountErasure(request, err, "Something went wrong while erasing your account. Please contact support.")
+		return
+	}
+	if account.ErasedAt != nil {
+		finishDataRequest(request, entities.DataRequestCompleted, nil)
+		return
+	}
+	err = accountErasable(account.ID)
+	if errors.Is(err, ErrWalletNotEmpty) || errors.Is(err, ErrPendingTransactions) {
+		failAccountErasure(request, err, err.Error())
+		notifyDataRequest(account, "We could not erase your account", err.Error())
+		return
+	}
+	if err != nil {
+		// tried again on the next run
+		repository.DataRequestRepo().UpdatePartialByID(request.ID, map[string]any{
+			"lockedUntil": nil,
+		})
+		return
+	}
+	// sent before the email address is erased
+	notifyDataRequest(account, "Your account has been erased", "Your Kego account has been closed and your personal data erased. Records of your payments are kept for as long as the law requires.")
+	err = eraseAccount(account)
+	if err != nil {
+		failAccountErasure(request, err, "Something went wrong while erasing your account. Please contact support.")
+		return
+	}
+	finishDataRequest(request, entities.DataRequestCompleted, nil)
+}
+
+func failAccountErasure(request *entities.DataRequest, err error, reason string) {
+	logger.Error(errors.New("could not erase user account"), logger.LoggerOptions{
+		Key: "error",
+		Data: err,
+	}, logger.LoggerOptions{
+		Key: "requestID",
+		Data: request.ID,
+	})
+	finishDataRequest(request, entities.DataRequestFailed, map[string]any{
+		"failureReason": reason,
+	})
+}
+
+// eraseAccount replaces the user's personal data with placeholders and deletes what we do not have to keep.
+// Wallets, businesses, transactions and identity verification evidence are kept because the law requires
+// financial and KYC records to be retained.
+// They still point at the user's id, but the id no longer leads to anyone.
+func eraseAccount(account *entities.User) error {
+	now := time.Now()
+	bvnHash := sha256.Sum256([]byte(account.BVN))
+	_, err := repository.UserRepo().UpdatePartialByID(account.ID, map[string]any{
+		"firstName": "Erased",
+		"lastName": "User",
+		"middleName": nil,
+		"email": fmt.Sprintf("erased-%s@erased.invalid", account.ID),
+		"phone": entities.PhoneNumber{},
+		"password": "",
+		"transactionPin": "",
+		"userAgent": "",
+		"deviceID": "",
+		// hashed so the same bvn can open a new account without the old one being readable
+		"bvn": hex.EncodeToString(bvnHash[:]),
+		"gender": "",
+		"dob": "",
+		"nationality": "",
+		"profileImage": "",
+		"tag": "",
+		"deactivated": true,
+		"erasedAt": now,
+	})
+	if err != nil {
+		return err
+	}
+	if account.ProfileImage != "" {
+		if err := fileupload.FileUploader.DeleteSingleFile(account.ID); err != nil {
+			logger.Error(errors.New("could not delete erased user's profile image"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "userID",
+				Data: account.ID,
+			})
+		}
+	}
+	sessions, err := repository.SessionRepo().FindMany(activeSessionsFilter(account.ID))
+	if err != nil {
+		return err
+	}
+	for _, session := range *sessions {
+		auth.SignOutUser(nil, session.ID, "account erased")
+	}
+	cleanUp := []func() (int64, error){
+		func() (int64, error) {
+			return repository.SessionRepo().DeleteMany(map[string]interface{}{"userID": account.ID})
+		},
+		func() (int64, error) {
+			return repository.DeviceRepo().DeleteMany(map[string]interface{}{"userID": account.ID})
+		},
+		func() (int64, error) {
+			return repository.NotificationRepo().DeleteMany(map[string]interface{}{"userID": account.ID})
+		},
+		func() (int64, error) {
+			return repository.NotificationPreferencesRepo().DeleteMany(map[string]interface{}{"userID": account.ID})
+		},
+		func() (int64, error) {
+			return repository.TwoFactorRepo().DeleteMany(map[string]interface{}{"userID": account.ID})
+		},
+		func() (int64, error) {
+			return repository.BusinessMemberRepo().DeleteMany(map[string]interface{}{
+				"$or": []map[string]any{
+					{"userID": account.ID},
+					{"email": strings.ToLower(account.Email)},
+				},
+			})
+		},
+		func() (int64, error) {
+			return repository.BusinessAPIKeyRepo().DeleteMany(map[string]interface{}{"createdBy": account.ID})
+		},
+		func() (int64, error) {
+			return repository.OTPAuditLogRepo().DeleteMany(map[string]interface{}{
+				"subject": map[string]any{
+					"$in": []string{account.ID, account.Email},
+				},
+			})
+		},
+	}
+	for _, deleteMany := range cleanUp {
+		if _, err := deleteMany(); err != nil {
+			return err
+		}
+	}
+	exports, err := repository.DataRequestRepo().FindMany(map[string]interface{}{
+		"userID": account.ID,
+		"type": entities.DataExportRequest,
+		"fileName": map[string]any{
+			"$ne": nil,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, export := range *exports {
+		removeDataExportFile(&export)
+	}
+	return nil
+}
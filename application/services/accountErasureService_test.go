@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kego.com/application/constants"
+	"kego.com/application/money"
+	"kego.com/entities"
+)
+
+func TestErasureBlockedBy(t *testing.T) {
+	now := time.Now()
+	recently := now.Add(-time.Hour)
+	longAgo := now.Add(-constants.TRANSACTION_SETTLEMENT_LOOKBACK - time.Hour)
+	emptyWallet := func(locks ...entities.LockedFunds) entities.Wallet {
+		return entities.Wallet{
+			ID: "wallet",
+			UserID: "user",
+			Balance: money.Zero("NGN"),
+			LedgerBalance: money.Zero("NGN"),
+			LockedFundsLog: locks,
+		}
+	}
+	payout := func(status entities.TransactionStatus, createdAt time.Time) entities.Transaction {
+		return entities.Transaction{
+			TransactionReference: "reference",
+			Amount: money.NGN(500000),
+			AmountInNGN: money.NGN(510750),
+			WalletID: "wallet",
+			UserID: "user",
+			Intent: entities.FlutterwaveDebitLocal,
+			Status: status,
+			CreatedAt: createdAt,
+		}
+	}
+	lock := func(lockedAt time.Time) entities.LockedFunds {
+		return entities.LockedFunds{
+			Amount: money.NGN(510750),
+			Reason: entities.FlutterwaveDebitLocal,
+			LockedFundsID: "lock",
+			LockedAt: lockedAt,
+		}
+	}
+	tests := []struct {
+		name             string
+		wallets          []entities.Wallet
+		transactions     []entities.Transaction
+		awaitingApproval int64
+		want             error
+	}{
+		{
+			name: "no wallets or payouts",
+		},
+		{
+			name: "settled payouts",
+			wallets: []entities.Wallet{emptyWallet()},
+			transactions: []entities.Transaction{
+				payout(entities.TransactionSuccessful, recently),
+				payout(entities.TransactionFailed, recently),
+				payout(entities.TransactionSuccessful, longAgo),
+			},
+		},
+		{
+			name: "payout left pending from before payouts were settled",
+			wallets: []entities.Wallet{emptyWallet(lock(longAgo))},
+			transactions: []entities.Transaction{payout(entities.TransactionPending, longAgo)},
+		},
+		{
+			name: "money in the wallet",
+			wallets: []entities.Wallet{{Balance: money.NGN(1), LedgerBalance: money.Zero("NGN")}},
+			transactions: []entities.Transaction{payout(entities.TransactionSuccessful, recently)},
+			want: ErrWalletNotEmpty,
+		},
+		{
+			name: "payout still with the processor",
+			wallets: []entities.Wallet{emptyWallet(lock(recently))},
+			transactions: []entities.Transaction{payout(entities.TransactionPending, recently)},
+			want: ErrPendingTransactions,
+		},
+		{
+			name: "funds locked for a payout",
+			wallets: []entities.Wallet{emptyWallet(lock(recently))},
+			want: ErrPendingTransactions,
+		},
+		{
+			name: "payout waiting for approval",
+			wallets: []entities.Wallet{emptyWallet()},
+			awaitingApproval: 1,
+			want: ErrPendingTransactions,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := erasureBlockedBy(test.wallets, test.transactions, test.awaitingApproval, now)
+			if !errors.Is(err, test.want) {
+				t.Errorf("erasureBlockedBy() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
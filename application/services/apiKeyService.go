@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+	"kego.com/infrastructure/logger"
+)
+
+// CreateBusinessAPIKey returns the new key's record and the key itself, which is not kept and cannot be shown again.
+func CreateBusinessAPIKey(ctx any, userID string, businessID string, payload *dto.CreateBusinessAPIKeyDTO) (*entities.BusinessAPIKey, *string) {
+	if !verifyTransactionPin(ctx, userID, payload.Pin) {
+		return nil, nil
+	}
+	scopes := []entities.BusinessPermission{}
+	for _, scope := range payload.Scopes {
+		if !(entities.BusinessAPIKey{Scopes: scopes}).Can(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	key := entities.BusinessAPIKey{
+		BusinessID: businessID,
+		Name: strings.TrimSpace(payload.Name),
+		Scopes: scopes,
+		AllowedIPs: payload.AllowedIPs,
+		CreatedBy: userID,
+	}
+	if key.AllowedIPs == nil {
+		key.AllowedIPs = []string{}
+	}
+	if key.Can(entities.SendPayouts) && len(key.AllowedIPs) == 0 {
+		apperrors.ClientError(ctx, "Keys that can send payments must be limited to the IP addresses of your servers", nil)
+		return nil, nil
+	}
+	apiKeyRepository := repository.BusinessAPIKeyRepo()
+	activeKeys, err := apiKeyRepository.CountDocs(map[string]interface{}{
+		"businessID": businessID,
+		"revokedAt": nil,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if activeKeys >= constants.MAX_BUSINESS_API_KEYS {
+		apperrors.ClientError(ctx, fmt.Sprintf("A business cannot have more than %d API keys. Revoke one you no longer use to create another.", constants.MAX_BUSINESS_API_KEYS), nil)
+		return nil, nil
+	}
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		logger.Error(errors.New("could not generate api key"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	key.Prefix = prefix
+	key.KeyHash = hash
+	created, err := apiKeyRepository.CreateOne(nil, key)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	notifyAPIKeyChanged(userID, "New API key created", fmt.Sprintf("An API key named %s (%s...) was created for your business. If this was not you, revoke it and change your password immediately.", created.Name, created.Prefix))
+	return created, &secret
+}
+
+func FetchBusinessAPIKeys(ctx any, businessID string) *[]entities.BusinessAPIKey {
+	keys, err := repository.BusinessAPIKeyRepo().FindMany(map[string]interface{}{
+		"businessID": businessID,
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return keys
+}
+
+// RevokeBusinessAPIKey stops the key working straight away. Revoked keys are kept so their use can still be traced.
+func RevokeBusinessAPIKey(ctx any, userID string, businessID string, keyID string) error {
+	apiKeyRepository := repository.BusinessAPIKeyRepo()
+	key, err := apiKeyRepository.FindOneByFilter(map[string]interface{}{
+		"_id": keyID,
+		"businessID": businessID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if key == nil {
+		err = errors.New("This API key was not found")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	now := time.Now()
+	affected, err := apiKeyRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": key.ID,
+		"revokedAt": nil,
+	}, map[string]any{
+		"$set": map[string]any{
+			"revokedAt": now,
+			"revokedBy": userID,
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if affected == 0 {
+		err = errors.New("This API key has already been revoked")
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	notifyAPIKeyChanged(userID, "API key revoked", fmt.Sprintf("The API key named %s (%s...) was revoked and can no longer be used.", key.Name, key.Prefix))
+	return nil
+}
+
+// AuthenticateBusinessAPIKey checks the key belongs to the business, is being used from one of its allowed
+// addresses and has the permission. Requests made with it act as whoever created it.
+func AuthenticateBusinessAPIKey(ctx any, businessID string, secret string, ipAddress string, permission entities.BusinessPermission) (*entities.BusinessAPIKey, *entities.User) {
+	if secret == "" {
+		apperrors.AuthenticationError(ctx, "provide an API key")
+		return nil, nil
+	}
+	apiKeyRepository := repository.BusinessAPIKeyRepo()
+	key, err := apiKeyRepository.FindOneByFilter(map[string]interface{}{
+		"keyHash": auth.HashAPIKey(secret),
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if key == nil || key.BusinessID != businessID {
+		apperrors.AuthenticationError(ctx, "invalid API key used")
+		return nil, nil
+	}
+	if key.RevokedAt != nil {
+		apperrors.AuthenticationError(ctx, "this API key has been revoked")
+		return nil, nil
+	}
+	if !apiKeyAllowsIP(key, ipAddress) {
+		logger.Warning("api key used from an address it is not allowed from", logger.LoggerOptions{
+			Key: "apiKeyID",
+			Data: key.ID,
+		}, logger.LoggerOptions{
+			Key: "ipAddress",
+			Data: ipAddress,
+		})
+		apperrors.ForbiddenError(ctx, fmt.Sprintf("this API key cannot be used from %s", ipAddress))
+		return nil, nil
+	}
+	if !key.Can(permission) {
+		apperrors.ForbiddenError(ctx, fmt.Sprintf("this API key does not have the %s scope", permission))
+		return nil, nil
+	}
+	creator, err := repository.UserRepo().FindByID(key.CreatedBy, options.FindOne().SetProjection(map[string]any{
+		"firstName": 1,
+		"lastName": 1,
+		"email": 1,
+		"deactivated": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if creator == nil || creator.Deactivated {
+		apperrors.AuthenticationError(ctx, "the account that created this API key is no longer active")
+		return nil, nil
+	}
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) >= constants.API_KEY_LAST_USED_INTERVAL {
+		_, err = apiKeyRepository.UpdatePartialByID(key.ID, map[string]any{
+			"lastUsedAt": time.Now(),
+			"lastUsedIP": ipAddress,
+		})
+		if err != nil {
+			logger.Error(errors.New("could not record api key use"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "apiKeyID",
+				Data: key.ID,
+			})
+		}
+	}
+	return key, creator
+}
+
+func apiKeyAllowsIP(key *entities.BusinessAPIKey, ipAddress string) bool {
+	if len(key.AllowedIPs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range key.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			_, network, err := net.ParseCIDR(allowed)
+			if err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func notifyAPIKeyChanged(userID string, title string, body string) {
+	account, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"email": 1,
+		"firstName": 1,
+	}))
+	if err == nil && account != nil {
+		err = events.Publish(nil, events.SecurityAlertPayload{
+			UserID: account.ID,
+			Email: account.Email,
+			FirstName: account.FirstName,
+			Title: title,
+			Body: body,
+		})
+	}
+	if err != nil {
+		logger.Error(errors.New("could not publish api key alert"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// AuthorizeBusinessMember returns the user's role in the business if it allows the permission.
+// Users who are not part of the business are told it does not exist so they cannot find out which businesses do.
+func AuthorizeBusinessMember(ctx any, businessID string, userID string, permission entities.BusinessPermission) (*entities.BusinessRole, bool) {
+	business, err := repository.BusinessRepo().FindByID(businessID, options.FindOne().SetProjection(map[string]any{
+		"userID": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, false
+	}
+	if business == nil {
+		apperrors.NotFoundError(ctx, "business does not exist")
+		return nil, false
+	}
+	role := entities.BusinessOwner
+	if business.UserID != userID {
+		member, err := repository.BusinessMemberRepo().FindOneByFilter(map[string]interface{}{
+			"businessID": businessID,
+			"userID": userID,
+			"status": entities.BusinessMemberActive,
+		})
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil, false
+		}
+		if member == nil {
+			apperrors.NotFoundError(ctx, "business does not exist")
+			return nil, false
+		}
+		role = member.Role
+	}
+	if !role.Can(permission) {
+		logger.Warning("business member attempted an action their role does not allow", logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		}, logger.LoggerOptions{
+			Key: "permission",
+			Data: permission,
+		})
+		apperrors.ForbiddenError(ctx, fmt.Sprintf("Your role in this business does not allow you to %s", strings.ReplaceAll(string(permission), "_", " ")))
+		return nil, false
+	}
+	return &role, true
+}
+
+// FetchMemberBusinessIDs returns the businesses the user has joined as a member, not counting ones they own.
+func FetchMemberBusinessIDs(userID string) ([]string, error) {
+	memberships, err := repository.BusinessMemberRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+		"status": entities.BusinessMemberActive,
+	}, options.Find().SetProjection(map[string]any{
+		"businessID": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, membership := range *memberships {
+		ids = append(ids, membership.BusinessID)
+	}
+	return ids, nil
+}
+
+// FetchBusinessMembers returns the business's owner along with everyone who has joined or been invited.
+func FetchBusinessMembers(ctx any, businessID string) (*entities.User, *[]entities.BusinessMember) {
+	business, err := repository.BusinessRepo().FindByID(businessID)
+	if err != nil || business == nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	owner, err := repository.UserRepo().FindByID(business.UserID, options.FindOne().SetProjection(map[string]any{
+		"firstName": 1,
+		"lastName": 1,
+		"email": 1,
+	}))
+	if err != nil || owner == nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	members, err := repository.BusinessMemberRepo().FindMany(map[string]interface{}{
+		"businessID": businessID,
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	return owner, members
+}
+
+// InviteBusinessMember emails an invite to join the business. Inviting someone who already has an open invite
+// sends it again with the new role.
+func InviteBusinessMember(ctx any, inviterID string, inviterRole entities.BusinessRole, businessID string, email string, role entities.BusinessRole) *entities.BusinessMember {
+	if !canAssignBusinessRole(ctx, inviterRole, role) {
+		return nil
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	business, err := repository.BusinessRepo().FindByID(businessID)
+	if err != nil || business == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	userRepository := repository.UserRepo()
+	invitee, err := userRepository.FindOneByFilter(map[string]interface{}{
+		"email": email,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if invitee != nil && invitee.ID == business.UserID {
+		apperrors.ClientError(ctx, "This is the owner of the business", nil)
+		return nil
+	}
+	inviter, err := userRepository.FindByID(inviterID)
+	if err != nil || inviter == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	memberRepository := repository.BusinessMemberRepo()
+	member, err := memberRepository.FindOneByFilter(map[string]interface{}{
+		"businessID": businessID,
+		"email": email,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if member != nil && member.Status == entities.BusinessMemberActive {
+		apperrors.EntityAlreadyExistsError(ctx, fmt.Sprintf("%s is already a member of this business", email))
+		return nil
+	}
+	expiresAt := time.Now().Add(constants.BUSINESS_INVITE_TTL)
+	if member != nil {
+		_, err = memberRepository.UpdatePartialByID(member.ID, map[string]any{
+			"role": role,
+			"invitedBy": inviterID,
+			"inviteExpiresAt": expiresAt,
+			"businessName": business.Name,
+		})
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		member.Role = role
+		member.InvitedBy = inviterID
+		member.InviteExpiresAt = &expiresAt
+		member.BusinessName = business.Name
+	} else {
+		members, err := memberRepository.CountDocs(map[string]interface{}{
+			"businessID": businessID,
+		})
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		if members >= constants.MAX_BUSINESS_MEMBERS {
+			apperrors.ClientError(ctx, fmt.Sprintf("A business can have at most %d members. Remove someone to invite another.", constants.MAX_BUSINESS_MEMBERS), nil)
+			return nil
+		}
+		member, err = memberRepository.CreateOne(nil, entities.BusinessMember{
+			BusinessID: businessID,
+			BusinessName: business.Name,
+			Email: email,
+			Role: role,
+			Status: entities.BusinessMemberInvited,
+			InvitedBy: inviterID,
+			InviteExpiresAt: &expiresAt,
+		})
+		if err != nil && strings.Contains(err.Error(), "already exists") {
+			apperrors.EntityAlreadyExistsError(ctx, fmt.Sprintf("%s has already been invited to this business", email))
+			return nil
+		}
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+	}
+	payload := events.BusinessMemberInvitedPayload{
+		Email: email,
+		InviteID: member.ID,
+		BusinessID: businessID,
+		BusinessName: business.Name,
+		InvitedBy: fmt.Sprintf("%s %s", inviter.FirstName, inviter.LastName),
+		Role: role,
+	}
+	if invitee != nil {
+		payload.UserID = invitee.ID
+	}
+	err = events.Publish(nil, payload)
+	if err != nil {
+		logger.Error(errors.New("could not publish business invite"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "memberID",
+			Data: member.ID,
+		})
+	}
+	return member
+}
+
+// FetchBusinessInvites returns the open invites sent to the user's email address.
+func FetchBusinessInvites(ctx any, userID string) *[]entities.BusinessMember {
+	account, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"email": 1,
+	}))
+	if err != nil || account == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	invites, err := repository.BusinessMemberRepo().FindMany(map[string]interface{}{
+		"email": strings.ToLower(account.Email),
+		"status": entities.BusinessMemberInvited,
+		"inviteExpiresAt": map[string]any{
+			"$gt": time.Now(),
+		},
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return invites
+}
+
+// AcceptBusinessInvite makes the user a member of the business they were invited to.
+func AcceptBusinessInvite(ctx any, userID string, inviteID string) *entities.BusinessMember {
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil || account == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	memberRepository := repository.BusinessMemberRepo()
+	invite := findBusinessInvite(ctx, account, inviteID)
+	if invite == nil {
+		return nil
+	}
+	now := time.Now()
+	affected, err := memberRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": invite.ID,
+		"status": entities.BusinessMemberInvited,
+	}, map[string]any{
+		"$set": map[string]any{
+			"userID": userID,
+			"firstName": account.FirstName,
+			"lastName": account.LastName,
+			"status": entities.BusinessMemberActive,
+			"joinedAt": now,
+			"inviteExpiresAt": nil,
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		apperrors.NotFoundError(ctx, "This invite was not found or has expired")
+		return nil
+	}
+	invite.UserID = &userID
+	invite.FirstName = &account.FirstName
+	invite.LastName = &account.LastName
+	invite.Status = entities.BusinessMemberActive
+	invite.JoinedAt = &now
+	invite.InviteExpiresAt = nil
+	return invite
+}
+
+func DeclineBusinessInvite(ctx any, userID string, inviteID string) error {
+	account, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"email": 1,
+	}))
+	if err != nil || account == nil {
+		apperrors.FatalServerError(ctx)
+		return errors.New("could not find user")
+	}
+	deleted, err := repository.BusinessMemberRepo().DeleteMany(map[string]interface{}{
+		"_id": inviteID,
+		"email": strings.ToLower(account.Email),
+		"status": entities.BusinessMemberInvited,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if deleted == 0 {
+		err = errors.New("This invite was not found")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
+
+func UpdateBusinessMemberRole(ctx any, userID string, userRole entities.BusinessRole, businessID string, memberID string, role entities.BusinessRole) *entities.BusinessMember {
+	member := findManageableBusinessMember(ctx, userID, userRole, businessID, memberID)
+	if member == nil || !canAssignBusinessRole(ctx, userRole, role) {
+		return nil
+	}
+	_, err := repository.BusinessMemberRepo().UpdatePartialByID(member.ID, map[string]any{
+		"role": role,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	member.Role = role
+	return member
+}
+
+// RemoveBusinessMember takes away a member's access or withdraws their invite.
+func RemoveBusinessMember(ctx any, userID string, userRole entities.BusinessRole, businessID string, memberID string) error {
+	member := findManageableBusinessMember(ctx, userID, userRole, businessID, memberID)
+	if member == nil {
+		return errors.New("member cannot be removed")
+	}
+	_, err := repository.BusinessMemberRepo().DeleteMany(map[string]interface{}{
+		"_id": member.ID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+func LeaveBusiness(ctx any, userID string, role entities.BusinessRole, businessID string) error {
+	if role == entities.BusinessOwner {
+		err := errors.New("The owner cannot leave a business. Close it instead.")
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	_, err := repository.BusinessMemberRepo().DeleteMany(map[string]interface{}{
+		"businessID": businessID,
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+// canAssignBusinessRole stops admins from making other admins, so only the owner decides who runs the business.
+func canAssignBusinessRole(ctx any, assignerRole entities.BusinessRole, role entities.BusinessRole) bool {
+	if role == entities.BusinessOwner {
+		apperrors.ClientError(ctx, "A business can only have one owner", nil)
+		return false
+	}
+	if role == entities.BusinessAdmin && assignerRole != entities.BusinessOwner {
+		apperrors.ForbiddenError(ctx, "Only the owner can make someone an admin")
+		return false
+	}
+	return true
+}
+
+func findManageableBusinessMember(ctx any, userID string, userRole entities.BusinessRole, businessID string, memberID string) *entities.BusinessMember {
+	member, err := repository.BusinessMemberRepo().FindOneByFilter(map[string]interface{}{
+		"_id": memberID,
+		"businessID": businessID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if member == nil {
+		apperrors.NotFoundError(ctx, "This member was not found")
+		return nil
+	}
+	if member.UserID != nil && *member.UserID == userID {
+		apperrors.ClientError(ctx, "You cannot change your own role. Leave the business instead.", nil)
+		return nil
+	}
+	if member.Role == entities.BusinessAdmin && userRole != entities.BusinessOwner {
+		apperrors.ForbiddenError(ctx, "Only the owner can change or remove an admin")
+		return nil
+	}
+	return member
+}
+
+func findBusinessInvite(ctx any, account *entities.User, inviteID string) *entities.BusinessMember {
+	invite, err := repository.BusinessMemberRepo().FindOneByFilter(map[string]interface{}{
+		"_id": inviteID,
+		"email": strings.ToLower(account.Email),
+		"status": entities.BusinessMemberInvited,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if invite == nil || invite.InviteExpiresAt == nil || time.Now().After(*invite.InviteExpiresAt) {
+		apperrors.NotFoundError(ctx, "This invite was not found or has expired")
+		return nil
+	}
+	return invite
+}
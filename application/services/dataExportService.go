@@ -0,0 +1,163 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// RequestDataExport queues a copy of everything we hold on the user. They are told when it is ready to download.
+func RequestDataExport(ctx any, userID string, password string) *entities.DataRequest {
+	_, request := createDataRequest(ctx, userID, password, entities.DataExportRequest, time.Now())
+	return request
+}
+
+// OpenDataExport returns where the user's export archive is on disk.
+func OpenDataExport(ctx any, userID string, requestID string) *string {
+	request, err := repository.DataRequestRepo().FindOneByFilter(map[string]interface{}{
+		"_id": requestID,
+		"userID": userID,
+		"type": entities.DataExportRequest,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if request == nil {
+		apperrors.NotFoundError(ctx, "This export was not found")
+		return nil
+	}
+	if request.Status != entities.DataRequestCompleted {
+		apperrors.ClientError(ctx, "This export is not ready yet. You will be notified when it is.", nil)
+		return nil
+	}
+	if request.FileName == nil || request.ExpiresAt == nil || time.Now().After(*request.ExpiresAt) {
+		apperrors.NotFoundError(ctx, "This export has expired. Request a new one to continue.")
+		return nil
+	}
+	path := filepath.Join(dataExportsDir(), *request.FileName)
+	return &path
+}
+
+func processDataExport(request *entities.DataRequest) {
+	account, err := repository.UserRepo().FindByID(request.UserID)
+	if err == nil && account == nil {
+		err = errors.New("user not found")
+	}
+	if err != nil {
+		failDataExport(request, err)
+		return
+	}
+	fileName, err := writeDataExport(account)
+	if err != nil {
+		failDataExport(request, err)
+		return
+	}
+	expiresAt := time.Now().Add(constants.DATA_EXPORT_TTL)
+	finishDataRequest(request, entities.DataRequestCompleted, map[string]any{
+		"fileName": fileName,
+		"expiresAt": expiresAt,
+	})
+	notifyDataRequest(account, "Your data export is ready", fmt.Sprintf("A copy of your Kego data is ready to download from the app until %s. If you did not ask for it, change your password now.", expiresAt.Format(time.RFC1123)))
+}
+
+func failDataExport(request *entities.DataRequest, err error) {
+	logger.Error(errors.New("could not export user data"), logger.LoggerOptions{
+		Key: "error",
+		Data: err,
+	}, logger.LoggerOptions{
+		Key: "requestID",
+		Data: request.ID,
+	})
+	reason := "Something went wrong while exporting your data. Please request a new export."
+	finishDataRequest(request, entities.DataRequestFailed, map[string]any{
+		"failureReason": reason,
+	})
+}
+
+// writeDataExport bundles the user's data into a zip of JSON files and returns its name in the exports folder.
+func writeDataExport(account *entities.User) (string, error) {
+	sections := map[string]func() (any, error){
+		"profile.json": func() (any, error) {
+			return account, nil
+		},
+		"wallets.json": func() (any, error) {
+			return repository.WalletRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"businesses.json": func() (any, error) {
+			return repository.BusinessRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"business_memberships.json": func() (any, error) {
+			return repository.BusinessMemberRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"transactions.json": func() (any, error) {
+			return repository.TransactionRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"notifications.json": func() (any, error) {
+			return repository.NotificationRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"notification_preferences.json": func() (any, error) {
+			return repository.NotificationPreferencesRepo().FindOneByFilter(map[string]interface{}{"userID": account.ID})
+		},
+		"devices.json": func() (any, error) {
+			return repository.DeviceRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"sessions.json": func() (any, error) {
+			return repository.SessionRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+		"identity_verifications.json": func() (any, error) {
+			return repository.IdentityVerificationRepo().FindMany(map[string]interface{}{"userID": account.ID})
+		},
+	}
+	dir := dataExportsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	fileName := fmt.Sprintf("%s-%s.zip", account.ID, time.Now().UTC().Format("20060102T150405Z"))
+	// written under a temporary name so a half written archive is never offered for download
+	tmp, err := os.CreateTemp(dir, "export-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	archive := zip.NewWriter(tmp)
+	for name, load := range sections {
+		data, err := load()
+		if err != nil {
+			tmp.Close()
+			return "", err
+		}
+		file, err := archive.Create(name)
+		if err != nil {
+			tmp.Close()
+			return "", err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			tmp.Close()
+			return "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, fileName)); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/application/services/types"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// the folder export archives are written to
+func dataExportsDir() string {
+	if dir := os.Getenv("DATA_EXPORT_DIR"); dir != "" {
+		return dir
+	}
+	return "exports"
+}
+
+// StartDataRequestWorker carries out data exports and erasures in the background.
+func StartDataRequestWorker() {
+	go func() {
+		ticker := time.NewTicker(constants.DATA_REQUEST_POLL_INTERVAL)
+		defer ticker.Stop()
+		for range ticker.C {
+			processDueDataRequests()
+			removeExpiredDataExports()
+		}
+	}()
+}
+
+func FetchDataRequests(ctx any, userID string) *[]entities.DataRequest {
+	requests, err := repository.DataRequestRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return requests
+}
+
+// CancelDataRequest stops a request that has not started yet, e.g. an erasure during its grace period.
+func CancelDataRequest(ctx any, userID string, requestID string) error {
+	now := time.Now()
+	affected, err := repository.DataRequestRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": requestID,
+		"userID": userID,
+		"status": entities.DataRequestPending,
+		"$or": []map[string]any{
+			{"lockedUntil": nil},
+			{"lockedUntil": map[string]any{"$lt": now}},
+		},
+	}, map[string]any{
+		"$set": map[string]any{
+			"status": entities.DataRequestCancelled,
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if affected == 0 {
+		err = errors.New("This request was not found or can no longer be cancelled")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
+
+// createDataRequest records the request after checking the user's password, since both exports and erasures
+// hand over or destroy everything we hold on the user.
+func createDataRequest(ctx any, userID string, password string, requestType entities.DataRequestType, scheduledFor time.Time) (*entities.User, *entities.DataRequest) {
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if account == nil {
+		apperrors.NotFoundError(ctx, fmt.Sprintf("This user profile was not found. Please contact support on %s to help resolve this issue.", constants.SUPPORT_EMAIL))
+		return nil, nil
+	}
+	if !VerifyPin(ctx, account, password, &types.PinSelectionType{Password: true}) {
+		return nil, nil
+	}
+	dataRequestRepository := repository.DataRequestRepo()
+	inProgress, err := dataRequestRepository.CountDocs(map[string]interface{}{
+		"userID": userID,
+		"type": requestType,
+		"status": entities.DataRequestPending,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if inProgress != 0 {
+		apperrors.EntityAlreadyExistsError(ctx, fmt.Sprintf("You already have a data %s in progress", requestType))
+		return nil, nil
+	}
+	request, err := dataRequestRepository.CreateOne(nil, entities.DataRequest{
+		UserID: userID,
+		Type: requestType,
+		Status: entities.DataRequestPending,
+		ScheduledFor: scheduledFor,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	return account, request
+}
+
+func processDueDataRequests() {
+	now := time.Now()
+	due, err := repository.DataRequestRepo().FindMany(map[string]interface{}{
+		"status": entities.DataRequestPending,
+		"scheduledFor": map[string]any{
+			"$lte": now,
+		},
+		"$or": []map[string]any{
+			{"lockedUntil": nil},
+			{"lockedUntil": map[string]any{"$lt": now}},
+		},
+	}, options.Find().SetSort(bson.D{{Key: "scheduledFor", Value: 1}}).SetLimit(10))
+	if err != nil {
+		return
+	}
+	for _, request := range *due {
+		if !claimDataRequest(&request) {
+			continue
+		}
+		switch request.Type {
+		case entities.DataExportRequest:
+			processDataExport(&request)
+		case entities.DataErasureRequest:
+			processAccountErasure(&request)
+		}
+	}
+}
+
+// claimDataRequest locks the request so other instances of the worker skip it while it is being carried out.
+func claimDataRequest(request *entities.DataRequest) bool {
+	now := time.Now()
+	affected, err := repository.DataRequestRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": request.ID,
+		"status": entities.DataRequestPending,
+		"$or": []map[string]any{
+			{"lockedUntil": nil},
+			{"lockedUntil": map[string]any{"$lt": now}},
+		},
+	}, map[string]any{
+		"$set": map[string]any{
+			"lockedUntil": now.Add(constants.DATA_REQUEST_LOCK_DURATION),
+		},
+	})
+	return err == nil && affected == 1
+}
+
+func finishDataRequest(request *entities.DataRequest, status entities.DataRequestStatus, update map[string]any) {
+	now := time.Now()
+	if update == nil {
+		update = map[string]any{}
+	}
+	update["status"] = status
+	update["lockedUntil"] = nil
+	update["updatedAt"] = now
+	if status == entities.DataRequestCompleted {
+		update["completedAt"] = now
+	}
+	_, err := repository.DataRequestRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": request.ID,
+	}, map[string]any{
+		"$set": update,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not update data request"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "requestID",
+			Data: request.ID,
+		})
+	}
+}
+
+func notifyDataRequest(account *entities.User, title string, body string) {
+	err := events.Publish(nil, events.SecurityAlertPayload{
+		UserID: account.ID,
+		Email: account.Email,
+		FirstName: account.FirstName,
+		Title: title,
+		Body: body,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not publish data request alert"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: account.ID,
+		})
+	}
+}
+
+// removeExpiredDataExports deletes archives once they can no longer be downloaded.
+func removeExpiredDataExports() {
+	dataRequestRepository := repository.DataRequestRepo()
+	expired, err := dataRequestRepository.FindMany(map[string]interface{}{
+		"type": entities.DataExportRequest,
+		"fileName": map[string]any{
+			"$ne": nil,
+		},
+		"expiresAt": map[string]any{
+			"$lt": time.Now(),
+		},
+	})
+	if err != nil {
+		return
+	}
+	for _, request := range *expired {
+		removeDataExportFile(&request)
+	}
+}
+
+func removeDataExportFile(request *entities.DataRequest) {
+	err := os.Remove(filepath.Join(dataExportsDir(), *request.FileName))
+	if err != nil && !os.IsNotExist(err) {
+		logger.Error(errors.New("could not delete data export"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "requestID",
+			Data: request.ID,
+		})
+		return
+	}
+	repository.DataRequestRepo().UpdatePartialByID(request.ID, map[string]any{
+		"fileName": nil,
+	})
+}
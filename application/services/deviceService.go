@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// RegisterDevice records that the user is signed in on the device, adding it to their devices if it is new.
+// A nil push token leaves the one already saved for the device in place.
+func RegisterDevice(userID string, deviceID string, pushToken *string, userAgent string, appVersion string, trusted bool) error {
+	deviceRepository := repository.DeviceRepo()
+	if pushToken != nil {
+		if err := releasePushToken(userID, deviceID, *pushToken); err != nil {
+			return err
+		}
+	}
+	device, err := deviceRepository.FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+		"deviceID": deviceID,
+	})
+	if err != nil {
+		return err
+	}
+	if device == nil {
+		_, err = deviceRepository.CreateOne(nil, entities.Device{
+			UserID: userID,
+			DeviceID: deviceID,
+			PushToken: pushToken,
+			Platform: entities.PlatformFromUserAgent(userAgent),
+			AppVersion: appVersion,
+			UserAgent: userAgent,
+			LastSeenAt: time.Now(),
+			Trusted: trusted,
+		})
+		return err
+	}
+	update := map[string]any{
+		"platform": entities.PlatformFromUserAgent(userAgent),
+		"appVersion": appVersion,
+		"userAgent": userAgent,
+		"lastSeenAt": time.Now(),
+	}
+	if pushToken != nil {
+		update["pushToken"] = *pushToken
+	}
+	_, err = deviceRepository.UpdatePartialByID(device.ID, update)
+	return err
+}
+
+// releasePushToken removes the token from any other device it was saved on. FCM tokens belong to an app install,
+// so a token seen on another account or device id means that install now belongs to someone else.
+func releasePushToken(userID string, deviceID string, pushToken string) error {
+	_, err := repository.DeviceRepo().UpdateManyWithOperator(map[string]interface{}{
+		"pushToken": pushToken,
+		"$nor": []map[string]any{{
+			"userID": userID,
+			"deviceID": deviceID,
+		}},
+	}, map[string]any{
+		"$set": map[string]any{
+			"pushToken": nil,
+		},
+	})
+	return err
+}
+
+func FetchDevices(ctx any, userID string) *[]entities.Device {
+	devices, err := repository.DeviceRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+	}, options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return devices
+}
+
+// UpdateDevicePushToken saves the new token FCM issued to the device after refreshing it.
+func UpdateDevicePushToken(ctx any, userID string, deviceID string, pushToken string, userAgent string, appVersion string) error {
+	err := RegisterDevice(userID, deviceID, &pushToken, userAgent, appVersion, false)
+	if err != nil {
+		logger.Error(errors.New("could not update device push token"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+// RemoveDevice stops notifications to a device the user no longer uses.
+func RemoveDevice(ctx any, userID string, deviceID string) error {
+	deleted, err := repository.DeviceRepo().DeleteOne(nil, map[string]interface{}{
+		"userID": userID,
+		"deviceID": deviceID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if deleted == 0 {
+		apperrors.NotFoundError(ctx, "device not found")
+		return errors.New("device not found")
+	}
+	return nil
+}
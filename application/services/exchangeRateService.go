@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+	international_payment_processor "kego.com/infrastructure/payment_processor/chimoney"
+)
+
+var ErrStaleExchangeRates = errors.New("exchange rates are unavailable and the last known rates are too old to use")
+
+var exchangeRateCache = struct {
+	sync.RWMutex
+	snapshot *entities.ExchangeRateSnapshot
+}{}
+
+// exchangeRateRefreshes makes concurrent requests that find the cache out of date wait on a single call to the provider.
+var exchangeRateRefreshes singleflight.Group
+
+// StartExchangeRateRefresher loads the last persisted snapshot into the cache
+// and refreshes the rates from the provider on a fixed interval.
+func StartExchangeRateRefresher() {
+	latest, err := repository.ExchangeRateSnapshotRepo().FindOneByFilter(map[string]interface{}{}, options.FindOne().SetSort(bson.D{{Key: "fetchedAt", Value: -1}}))
+	if err == nil && latest != nil {
+		setCachedExchangeRates(latest)
+	}
+	go func() {
+		RefreshExchangeRates()
+		ticker := time.NewTicker(constants.EXCHANGE_RATE_REFRESH_INTERVAL)
+		defer ticker.Stop()
+		for range ticker.C {
+			RefreshExchangeRates()
+		}
+	}()
+}
+
+// RefreshExchangeRates fetches the latest rates, persists them as a snapshot and updates the cache.
+func RefreshExchangeRates() (*entities.ExchangeRateSnapshot, error) {
+	rates, statusCode, err := international_payment_processor.InternationalPaymentProcessor.FetchExchangeRates()
+	if err != nil {
+		logger.Error(errors.New("could not refresh exchange rates"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		})
+		return nil, err
+	}
+	snapshot, err := repository.ExchangeRateSnapshotRepo().CreateOne(nil, entities.ExchangeRateSnapshot{
+		Provider: "chimoney",
+		Rates: *rates,
+		FetchedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error(errors.New("could not persist exchange rate snapshot"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		return nil, err
+	}
+	setCachedExchangeRates(snapshot)
+	return snapshot, nil
+}
+
+// CurrentExchangeRates serves rates from the cache. Rates older than the refresh interval are refreshed first,
+// and if the provider cannot be reached the cached rates are only used while they are within the staleness bound.
+func CurrentExchangeRates() (*entities.ExchangeRateSnapshot, error) {
+	exchangeRateCache.RLock()
+	snapshot := exchangeRateCache.snapshot
+	exchangeRateCache.RUnlock()
+	if snapshot != nil && snapshot.Age() < constants.EXCHANGE_RATE_REFRESH_INTERVAL {
+		return snapshot, nil
+	}
+	refreshed, err, _ := exchangeRateRefreshes.Do("exchangeRates", func() (any, error) {
+		return RefreshExchangeRates()
+	})
+	if err == nil {
+		return refreshed.(*entities.ExchangeRateSnapshot), nil
+	}
+	if snapshot != nil && snapshot.Age() < constants.EXCHANGE_RATE_MAX_STALENESS {
+		logger.Warning("serving cached exchange rates as the provider could not be reached", logger.LoggerOptions{
+			Key: "fetchedAt",
+			Data: snapshot.FetchedAt,
+		})
+		return snapshot, nil
+	}
+	return nil, ErrStaleExchangeRates
+}
+
+func setCachedExchangeRates(snapshot *entities.ExchangeRateSnapshot) {
+	exchangeRateCache.Lock()
+	defer exchangeRateCache.Unlock()
+	if exchangeRateCache.snapshot == nil || snapshot.FetchedAt.After(exchangeRateCache.snapshot.FetchedAt) {
+		exchangeRateCache.snapshot = snapshot
+	}
+}
+
+func GetExchangeRates(ctx any) (map[string]float64, *entities.ExchangeRateSnapshot) {
+	snapshot, err := CurrentExchangeRates()
+	if err != nil {
+		apperrors.ExternalDependencyError(ctx, "chimoney", "0", err)
+		return nil, nil
+	}
+	return snapshot.Rates.FormatAllRates(), snapshot
+}
+
+// ConvertToNGN values an amount in naira and dollars using the current rates.
+func ConvertToNGN(ctx any, amount money.Money) (*entities.CurrencyConversion, *entities.ExchangeRateSnapshot) {
+	snapshot, err := CurrentExchangeRates()
+	if err != nil {
+		apperrors.ExternalDependencyError(ctx, "chimoney", "0", err)
+		return nil, nil
+	}
+	conversion, err := snapshot.Rates.Convert(amount)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil, nil
+	}
+	return conversion, snapshot
+}
+
+// FetchExchangeRateAt returns the snapshot that was in use at the time passed in.
+func FetchExchangeRateAt(ctx any, at time.Time) *entities.ExchangeRateSnapshot {
+	snapshot, err := repository.ExchangeRateSnapshotRepo().FindOneByFilter(map[string]interface{}{
+		"fetchedAt": map[string]any{
+			"$lte": at,
+		},
+	}, options.FindOne().SetSort(bson.D{{Key: "fetchedAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if snapshot == nil {
+		apperrors.NotFoundError(ctx, fmt.Sprintf("No exchange rates were recorded on or before %s", at.Format(time.RFC3339)))
+		return nil
+	}
+	return snapshot
+}
+
+// FetchExchangeRateHistory returns the rate of a currency against the naira between two dates.
+// Ranges longer than two days are reduced to one point per hour so they can be drawn on a chart.
+func FetchExchangeRateHistory(ctx any, currency string, from time.Time, to time.Time) *[]map[string]any {
+	if !from.Before(to) {
+		apperrors.ClientError(ctx, "from must be before to", nil)
+		return nil
+	}
+	if to.Sub(from) > constants.EXCHANGE_RATE_HISTORY_MAX_RANGE {
+		apperrors.ClientError(ctx, fmt.Sprintf("You cannot fetch more than %d days of exchange rates at a time", int(constants.EXCHANGE_RATE_HISTORY_MAX_RANGE.Hours() / 24)), nil)
+		return nil
+	}
+	snapshots, err := repository.ExchangeRateSnapshotRepo().FindMany(map[string]interface{}{
+		"fetchedAt": map[string]any{
+			"$gte": from,
+			"$lte": to,
+		},
+	}, options.Find().SetSort(bson.D{{Key: "fetchedAt", Value: 1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	bucket := time.Duration(0)
+	if to.Sub(from) > 48 * time.Hour {
+		bucket = time.Hour
+	}
+	points := []map[string]any{}
+	var lastBucket time.Time
+	for _, snapshot := range *snapshots {
+		rate, err := snapshot.Rates.NGNRate(currency)
+		if err != nil {
+			apperrors.ClientError(ctx, err.Error(), nil)
+			return nil
+		}
+		if bucket != 0 {
+			if current := snapshot.FetchedAt.Truncate(bucket); current.Equal(lastBucket) {
+				continue
+			} else {
+				lastBucket = current
+			}
+		}
+		points = append(points, map[string]any{
+			"rate": rate.String(),
+			"fetchedAt": snapshot.FetchedAt,
+		})
+	}
+	return &points
+}
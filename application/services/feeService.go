@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+	"kego.com/infrastructure/validator"
+)
+
+// The fee schedule every business is on until it is assigned a negotiated plan.
+func defaultPricingPlan() entities.PricingPlan {
+	return entities.PricingPlan{
+		Code: constants.DEFAULT_PRICING_PLAN_CODE,
+		Name: "Standard",
+		Description: "Default pricing for all businesses",
+		Version: 1,
+		Rules: []entities.FeeRule{
+			{
+				Intent: entities.FlutterwaveDebitLocal,
+				Component: entities.ProcessorFeeComponent,
+				Type: entities.TieredFee,
+				Tiers: []entities.FeeTier{
+					{UpTo: utils.GetInt64Pointer(500000), Flat: 1000},
+					{UpTo: utils.GetInt64Pointer(5000000), Flat: 2500},
+					{Flat: 5000},
+				},
+				VATBasisPoints: 750,
+			},
+			{
+				Intent: entities.FlutterwaveDebitLocal,
+				Component: entities.PlatformFeeComponent,
+				Type: entities.TieredFee,
+				Tiers: []entities.FeeTier{
+					{UpTo: utils.GetInt64Pointer(500000), Flat: 500},
+					{UpTo: utils.GetInt64Pointer(5000000), Flat: 1250},
+					{Flat: 2500},
+				},
+				VATBasisPoints: 750,
+			},
+			{
+				Intent: entities.ChimoneyDebitInternational,
+				Component: entities.ProcessorFeeComponent,
+				Type: entities.PercentageFee,
+				RateBasisPoints: 50,
+			},
+			{
+				Intent: entities.ChimoneyDebitInternational,
+				Component: entities.PlatformFeeComponent,
+				Type: entities.PercentageFee,
+				RateBasisPoints: 100,
+			},
+		},
+	}
+}
+
+// SeedDefaultPricingPlan creates the first version of the default plan if it does not exist.
+func SeedDefaultPricingPlan() {
+	count, err := repository.PricingPlanRepo().CountDocs(map[string]interface{}{
+		"code": constants.DEFAULT_PRICING_PLAN_CODE,
+	})
+	if err != nil || count != 0 {
+		return
+	}
+	_, err = repository.PricingPlanRepo().CreateOne(nil, defaultPricingPlan())
+	if err != nil {
+		logger.Error(errors.New("could not seed default pricing plan"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	}
+}
+
+// FetchActivePricingPlan returns the latest version of a plan.
+func FetchActivePricingPlan(code string) (*entities.PricingPlan, error) {
+	return repository.PricingPlanRepo().FindOneByFilter(map[string]interface{}{
+		"code": code,
+	}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}))
+}
+
+// CalculateTransactionFees prices a transaction using the plan the business is on.
+// Fees are charged in the currency of the amount passed in.
+func CalculateTransactionFees(ctx any, businessID string, intent entities.TransactionIntent, corridor string, amount money.Money) *entities.FeeBreakdown {
+	planCode := constants.DEFAULT_PRICING_PLAN_CODE
+	business, err := repository.BusinessRepo().FindByID(businessID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if business != nil && business.PricingPlanCode != nil {
+		planCode = *business.PricingPlanCode
+	}
+	plan, err := FetchActivePricingPlan(planCode)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if plan == nil {
+		logger.Error(errors.New("pricing plan not found"), logger.LoggerOptions{
+			Key: "code",
+			Data: planCode,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	breakdown, err := plan.Calculate(intent, corridor, amount)
+	if err != nil {
+		logger.Error(errors.New("could not calculate transaction fees"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "amount",
+			Data: amount,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return breakdown
+}
+
+// CreatePricingPlanVersion stores the payload as the next version of the plan with the same code.
+func CreatePricingPlanVersion(ctx any, payload *entities.PricingPlan) *entities.PricingPlan {
+	validationErr := validator.ValidatorInstance.ValidateStruct(*payload)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx, validationErr)
+		return nil
+	}
+	current, err := FetchActivePricingPlan(payload.Code)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	payload.ID = ""
+	payload.Version = 1
+	if current != nil {
+		payload.Version = current.Version + 1
+	}
+	plan, err := repository.PricingPlanRepo().CreateOne(nil, *payload)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return plan
+}
+
+func AssignPricingPlan(ctx any, businessID string, code string) error {
+	plan, err := FetchActivePricingPlan(code)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if plan == nil {
+		err = fmt.Errorf("pricing plan %s does not exist", code)
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	affected, err := repository.BusinessRepo().UpdatePartialByID(businessID, map[string]any{
+		"pricingPlanCode": code,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if affected == 0 {
+		err = errors.New("business does not exist")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
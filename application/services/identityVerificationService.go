@@ -0,0 +1,272 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	fileupload "kego.com/infrastructure/file_upload"
+	identityverification "kego.com/infrastructure/identity_verification"
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+	"kego.com/infrastructure/logger"
+)
+
+// VerifyIdentityDocument checks a NIN, passport or driver's licence for a user who has already verified their BVN.
+// The holder has to be the person on the BVN and, where the issuer has a picture, look like the user.
+// Every attempt is kept as evidence whether or not it passes.
+func VerifyIdentityDocument(ctx any, userID string, documentType entities.IdentityDocumentType, number string, image *multipart.FileHeader) *entities.IdentityVerification {
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if account == nil {
+		apperrors.NotFoundError(ctx, fmt.Sprintf("This user profile was not found. Please contact support on %s to help resolve this issue.", constants.SUPPORT_EMAIL))
+		return nil
+	}
+	if !account.KYCCompleted {
+		apperrors.ClientError(ctx, "Verify your BVN before adding other identity documents", nil)
+		return nil
+	}
+	identityVerificationRepository := repository.IdentityVerificationRepo()
+	verified, err := identityVerificationRepository.CountDocs(map[string]interface{}{
+		"userID": userID,
+		"documentType": documentType,
+		"status": entities.IdentityVerified,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if verified != 0 {
+		apperrors.EntityAlreadyExistsError(ctx, "You have already verified this type of document")
+		return nil
+	}
+	evidence := entities.IdentityVerification{
+		UserID: userID,
+		DocumentType: documentType,
+	}
+	usedElsewhere, err := identityVerificationRepository.CountDocs(map[string]interface{}{
+		"documentNumberHash": hashDocumentNumber(documentType, number),
+		"status": entities.IdentityVerified,
+		"userID": map[string]any{
+			"$ne": userID,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if usedElsewhere != 0 {
+		rejectIdentityDocument(ctx, evidence, number, fmt.Sprintf("This document has already been used to verify another account. Please contact support on %s to help resolve this issue.", constants.SUPPORT_EMAIL))
+		return nil
+	}
+
+	var document *identity_verification_types.DocumentData
+	if documentType == entities.NINDocument {
+		document, err = identityverification.IdentityVerifier.FetchNINDetails(number)
+	} else {
+		// kept as evidence under its own name so the profile picture is not replaced
+		imageID := fmt.Sprintf("%s-%s", userID, documentType)
+		url, uploadErr := fileupload.FileUploader.UploadSingleFile(image, &imageID)
+		if uploadErr != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		evidence.DocumentImage = url
+		document, err = identityverification.IdentityVerifier.VerifyDocument(identity_verification_types.DocumentLookup{
+			Type: identity_verification_types.DocumentType(documentType),
+			Number: number,
+			ImageURL: *url,
+			LastName: account.LastName,
+		})
+	}
+	if errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+		apperrors.ExternalDependencyError(ctx, "identity verification", "", err)
+		return nil
+	}
+	if err != nil {
+		rejectIdentityDocument(ctx, evidence, number, err.Error())
+		return nil
+	}
+	evidence.Provider = document.Provider
+	evidence.FirstName = document.FirstName
+	evidence.MiddleName = document.MiddleName
+	evidence.LastName = document.LastName
+	evidence.DateOfBirth = document.DateOfBirth
+	evidence.ExpiryDate = document.ExpiryDate
+	evidence.NameMatched, evidence.DateOfBirthMatched = MatchesBVNRecord(account.FirstName, account.MiddleName, account.LastName, account.DOB, document)
+
+	if document.ExpiryDate != nil {
+		expiresAt, err := time.Parse("2006-01-02", *document.ExpiryDate)
+		if err == nil && expiresAt.Before(time.Now()) {
+			rejectIdentityDocument(ctx, evidence, number, "This document has expired. Please use one that is still valid.")
+			return nil
+		}
+	}
+	if !evidence.NameMatched || !evidence.DateOfBirthMatched {
+		rejectIdentityDocument(ctx, evidence, number, fmt.Sprintf("The name or date of birth on this document does not match your BVN. If you think this is a mistake please contact support on %s", constants.SUPPORT_EMAIL))
+		return nil
+	}
+	// only possible when the issuer has a picture of the holder
+	if document.Base64Image != "" && account.ProfileImage != "" {
+		confidence, err := identityverification.IdentityVerifier.FaceMatch(account.ProfileImage, document.Base64Image)
+		if errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+			apperrors.ExternalDependencyError(ctx, "identity verification", "", err)
+			return nil
+		}
+		if err != nil {
+			rejectIdentityDocument(ctx, evidence, number, err.Error())
+			return nil
+		}
+		evidence.FaceMatchConfidence = confidence
+		if *confidence < constants.FACE_MATCH_MIN_CONFIDENCE {
+			rejectIdentityDocument(ctx, evidence, number, fmt.Sprintf("The picture on this document does not match the one taken when you verified your identity. If you think this is a mistake please contact support on %s", constants.SUPPORT_EMAIL))
+			return nil
+		}
+	}
+	evidence.Status = entities.IdentityVerified
+	saved, err := RecordIdentityVerification(evidence, number)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	_, err = repository.UserRepo().UpdatePartialByID(userID, map[string]any{
+		"kycTier": entities.KYCTierFull,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return saved
+}
+
+func FetchIdentityVerifications(ctx any, userID string) *[]entities.IdentityVerification {
+	verifications, err := repository.IdentityVerificationRepo().FindMany(map[string]interface{}{
+		"userID": userID,
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return verifications
+}
+
+// RecordKYCEvidence keeps the outcome of the checks made while a user verifies their account with their BVN,
+// and their NIN if they gave one.
+func RecordKYCEvidence(userID string, bvn string, bvnData *identity_verification_types.BVNData, nin string, ninData *identity_verification_types.DocumentData, confidence *float32, rejectionReason *string) {
+	status := entities.IdentityVerified
+	if rejectionReason != nil {
+		status = entities.IdentityRejected
+	}
+	bvnEvidence := entities.IdentityVerification{
+		UserID: userID,
+		DocumentType: entities.BVNDocument,
+		Provider: bvnData.Provider,
+		Status: status,
+		FirstName: bvnData.FirstName,
+		MiddleName: bvnData.MiddleName,
+		LastName: bvnData.LastName,
+		DateOfBirth: bvnData.DateOfBirth,
+		// the bvn is the record everything else is checked against
+		NameMatched: true,
+		DateOfBirthMatched: true,
+		RejectionReason: rejectionReason,
+	}
+	if ninData == nil {
+		bvnEvidence.FaceMatchConfidence = confidence
+	}
+	RecordIdentityVerification(bvnEvidence, bvn)
+	if ninData == nil {
+		return
+	}
+	ninEvidence := entities.IdentityVerification{
+		UserID: userID,
+		DocumentType: entities.NINDocument,
+		Provider: ninData.Provider,
+		Status: status,
+		FirstName: ninData.FirstName,
+		MiddleName: ninData.MiddleName,
+		LastName: ninData.LastName,
+		DateOfBirth: ninData.DateOfBirth,
+		FaceMatchConfidence: confidence,
+		RejectionReason: rejectionReason,
+	}
+	ninEvidence.NameMatched, ninEvidence.DateOfBirthMatched = MatchesBVNRecord(bvnData.FirstName, bvnData.MiddleName, bvnData.LastName, bvnData.DateOfBirth, ninData)
+	RecordIdentityVerification(ninEvidence, nin)
+}
+
+// RecordIdentityVerification stores the evidence with the document number masked and hashed.
+func RecordIdentityVerification(evidence entities.IdentityVerification, number string) (*entities.IdentityVerification, error) {
+	evidence.DocumentNumber = maskDocumentNumber(number)
+	evidence.DocumentNumberHash = hashDocumentNumber(evidence.DocumentType, number)
+	saved, err := repository.IdentityVerificationRepo().CreateOne(nil, evidence)
+	if err != nil {
+		logger.Error(errors.New("could not record identity verification"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: evidence.UserID,
+		})
+	}
+	return saved, err
+}
+
+// MatchesBVNRecord checks the holder of a document is the person on a BVN. Names may be in a different order
+// or leave out the middle name, but every name on the document has to be on the BVN.
+func MatchesBVNRecord(firstName string, middleName *string, lastName string, dob string, document *identity_verification_types.DocumentData) (nameMatched bool, dateOfBirthMatched bool) {
+	bvnNames := map[string]bool{}
+	for _, name := range nameParts(firstName, middleName, lastName) {
+		bvnNames[name] = true
+	}
+	documentNames := nameParts(document.FirstName, document.MiddleName, document.LastName)
+	nameMatched = len(documentNames) != 0
+	for _, name := range documentNames {
+		if !bvnNames[name] {
+			nameMatched = false
+			break
+		}
+	}
+	dateOfBirthMatched = document.DateOfBirth != "" && identity_verification_types.NormaliseDate(dob) == identity_verification_types.NormaliseDate(document.DateOfBirth)
+	return nameMatched, dateOfBirthMatched
+}
+
+func nameParts(firstName string, middleName *string, lastName string) []string {
+	names := firstName + " " + lastName
+	if middleName != nil {
+		names += " " + *middleName
+	}
+	return strings.FieldsFunc(strings.ToLower(names), func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+}
+
+func rejectIdentityDocument(ctx any, evidence entities.IdentityVerification, number string, reason string) {
+	evidence.Status = entities.IdentityRejected
+	evidence.RejectionReason = &reason
+	RecordIdentityVerification(evidence, number)
+	apperrors.ClientError(ctx, reason, nil)
+}
+
+func maskDocumentNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+func hashDocumentNumber(documentType entities.IdentityDocumentType, number string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%s", documentType, strings.ToUpper(strings.TrimSpace(number)))))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// CreateInternationalPaymentQuote values the amount, which is in the destination country's currency, in naira and prices the payout.
+func CreateInternationalPaymentQuote(ctx any, userID string, businessID string, destinationCountryCode string, amount money.Money) *entities.InternationalPaymentQuote {
+	if currency := utils.CountryCodeToCurrencyCode(destinationCountryCode); currency == "" || currency != amount.Currency {
+		apperrors.ClientError(ctx, fmt.Sprintf("Payments to %s must be made in the local currency", destinationCountryCode), nil)
+		return nil
+	}
+	conversion, snapshot := ConvertToNGN(ctx, amount)
+	if conversion == nil {
+		return nil
+	}
+	if !VerifyTransferAmountLimits(ctx, conversion.ValueInNGN) {
+		return nil
+	}
+	fees := CalculateTransactionFees(ctx, businessID, entities.ChimoneyDebitInternational, destinationCountryCode, conversion.ValueInNGN)
+	if fees == nil {
+		return nil
+	}
+	totalAmount, err := fees.Total(conversion.ValueInNGN)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil
+	}
+	quote, err := repository.InternationalPaymentQuoteRepo().CreateOne(nil, entities.InternationalPaymentQuote{
+		UserID: userID,
+		BusinessID: businessID,
+		DestinationCountryCode: destinationCountryCode,
+		Amount: amount,
+		Rate: conversion.Rate,
+		ExchangeRateSnapshotID: snapshot.ID,
+		ValueInUSD: conversion.ValueInUSD,
+		AmountInNGN: conversion.ValueInNGN,
+		ProcessorFee: fees.ProcessorFee,
+		Fee: fees.PlatformFee,
+		PricingPlan: fees.PricingPlan,
+		TotalAmount: totalAmount,
+		Consumed: false,
+		ExpiresAt: time.Now().Add(constants.INTERNATIONAL_PAYMENT_QUOTE_TTL),
+	})
+	if err != nil {
+		logger.Error(errors.New("error creating international payment quote"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return quote
+}
+
+// FindInternationalPaymentQuote checks that the quote belongs to this payout and can still be used. It does not
+// use the quote up, so a payout that is turned down, e.g. for a wrong pin, can be tried again with it.
+func FindInternationalPaymentQuote(ctx any, quoteID string, userID string, businessID string, destinationCountryCode string, amount money.Money) *entities.InternationalPaymentQuote {
+	quote, err := repository.InternationalPaymentQuoteRepo().FindByID(quoteID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if quote == nil {
+		apperrors.NotFoundError(ctx, "This quote was not found. Request a new quote to continue.")
+		return nil
+	}
+	if quote.UserID != userID || quote.BusinessID != businessID {
+		logger.Warning("user attempted to use a quote that was not issued to them", logger.LoggerOptions{
+			Key: "quoteID",
+			Data: quoteID,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		})
+		apperrors.NotFoundError(ctx, "This quote was not found. Request a new quote to continue.")
+		return nil
+	}
+	if quote.Amount != amount || quote.DestinationCountryCode != destinationCountryCode {
+		apperrors.ClientError(ctx, "The payment details do not match the quote provided. Request a new quote to continue.", nil)
+		return nil
+	}
+	if quote.Consumed {
+		apperrors.ClientError(ctx, "This quote has already been used. Request a new quote to continue.", nil)
+		return nil
+	}
+	if quote.Expired() {
+		apperrors.ClientError(ctx, "This quote has expired. Request a new quote to continue.", nil)
+		return nil
+	}
+	return quote
+}
+
+// ConsumeInternationalPaymentQuote marks a quote found with FindInternationalPaymentQuote as used once the payout
+// has been authorised and its funds locked. A quote can only be consumed once, so it cannot be replayed to send a second payment at the same rate.
+func ConsumeInternationalPaymentQuote(ctx any, quote *entities.InternationalPaymentQuote) *entities.InternationalPaymentQuote {
+	affected, err := repository.InternationalPaymentQuoteRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": quote.ID,
+		"consumed": false,
+	}, map[string]any{
+		"$set": map[string]any{
+			"consumed": true,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		apperrors.ClientError(ctx, "This quote has already been used. Request a new quote to continue.", nil)
+		return nil
+	}
+	quote.Consumed = true
+	return quote
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/database/repository/cache"
+	fileupload "kego.com/infrastructure/file_upload"
+	identityverification "kego.com/infrastructure/identity_verification"
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+	"kego.com/infrastructure/logger"
+)
+
+// FIRS TINs are 8 digits, a dash and 4 digits. JTB TINs are 10 digits.
+var tinRegex = regexp.MustCompile(`^([0-9]{8}-?[0-9]{4}|[0-9]{10})$`)
+
+// SaveBusinessKYBDetails looks the business up with CAC and records who runs and owns it.
+// The business takes the name it is registered with so it cannot pay out under someone else's.
+func SaveBusinessKYBDetails(ctx any, businessID string, details *dto.BusinessKYBDTO) *entities.Business {
+	business := findBusiness(ctx, businessID)
+	if business == nil || !kybEditable(ctx, business) {
+		return nil
+	}
+	if !tinRegex.MatchString(details.TIN) {
+		apperrors.ClientError(ctx, "Enter a valid TIN", nil)
+		return nil
+	}
+	var ownership float64
+	for _, owner := range details.BeneficialOwners {
+		if owner.OwnershipPercent == nil {
+			apperrors.ClientError(ctx, "Enter how much of the business each beneficial owner owns", nil)
+			return nil
+		}
+		ownership += *owner.OwnershipPercent
+	}
+	if ownership > 100 {
+		apperrors.ClientError(ctx, "Beneficial owners cannot own more than 100% of the business between them", nil)
+		return nil
+	}
+	company, err := identityverification.IdentityVerifier.FetchCompanyDetails(details.RegistrationNumber)
+	if errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+		apperrors.ExternalDependencyError(ctx, "identity verification", "", err)
+		return nil
+	}
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil
+	}
+	if company.Status != "" && company.Status != "ACTIVE" {
+		apperrors.ClientError(ctx, fmt.Sprintf("CAC lists %s as %s. Only active businesses can be verified.", company.Name, company.Status), nil)
+		return nil
+	}
+	businessRepository := repository.BusinessRepo()
+	claimed, err := businessRepository.CountDocs(map[string]interface{}{
+		"kyb.registrationNumber": company.RegistrationNumber,
+		"kybStatus": map[string]any{
+			"$in": []entities.KYBStatus{entities.KYBPendingReview, entities.KYBApproved},
+		},
+		"userID": map[string]any{
+			"$ne": business.UserID,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if claimed != 0 {
+		apperrors.ClientError(ctx, fmt.Sprintf("This business has already been registered on Kego by someone else. Please contact support on %s to help resolve this issue.", constants.SUPPORT_EMAIL), nil)
+		return nil
+	}
+	kyb := entities.BusinessKYB{
+		RegistrationNumber: company.RegistrationNumber,
+		RegisteredName: company.Name,
+		RegistrationDate: company.RegistrationDate,
+		RegistrationStatus: company.Status,
+		LookupProvider: company.Provider,
+		TIN: details.TIN,
+		RegisteredAddress: details.RegisteredAddress,
+		Directors: details.Directors,
+		BeneficialOwners: details.BeneficialOwners,
+		Documents: []entities.BusinessDocument{},
+	}
+	if business.KYB != nil {
+		kyb.Documents = business.KYB.Documents
+		kyb.RejectionReason = business.KYB.RejectionReason
+	}
+	_, err = businessRepository.UpdatePartialByID(businessID, map[string]any{
+		"name": company.Name,
+		"kyb": kyb,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	_, err = repository.WalletRepo().UpdatePartialByFilter(map[string]interface{}{
+		"businessID": businessID,
+	}, map[string]any{
+		"businessName": company.Name,
+	})
+	if err == nil {
+		_, err = repository.BusinessMemberRepo().UpdatePartialByFilter(map[string]interface{}{
+			"businessID": businessID,
+		}, map[string]any{
+			"businessName": company.Name,
+		})
+	}
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	business.Name = company.Name
+	business.KYB = &kyb
+	return business
+}
+
+// UploadBusinessDocument keeps a document for the KYB review, replacing any earlier one of the same type.
+func UploadBusinessDocument(ctx any, businessID string, documentType entities.BusinessDocumentType, file *multipart.FileHeader) *entities.BusinessDocument {
+	business := findBusiness(ctx, businessID)
+	if business == nil || !kybEditable(ctx, business) {
+		return nil
+	}
+	if business.KYB == nil {
+		apperrors.ClientError(ctx, "Add your business's registration details before uploading documents", nil)
+		return nil
+	}
+	fileID := fmt.Sprintf("%s-%s", businessID, documentType)
+	url, err := fileupload.FileUploader.UploadSingleFile(file, &fileID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	document := entities.BusinessDocument{
+		Type: documentType,
+		FileName: file.Filename,
+		URL: *url,
+		UploadedAt: time.Now(),
+	}
+	businessRepository := repository.BusinessRepo()
+	_, err = businessRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": businessID,
+	}, map[string]any{
+		"$pull": map[string]any{
+			"kyb.documents": map[string]any{
+				"type": documentType,
+			},
+		},
+	})
+	if err == nil {
+		_, err = businessRepository.UpdateManyWithOperator(map[string]interface{}{
+			"_id": businessID,
+		}, map[string]any{
+			"$push": map[string]any{
+				"kyb.documents": document,
+			},
+		})
+	}
+	if err != nil {
+		logger.Error(errors.New("could not save business document"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return &document
+}
+
+// SubmitBusinessKYB sends the business to be reviewed once everything the review needs is in.
+func SubmitBusinessKYB(ctx any, businessID string) *entities.Business {
+	business := findBusiness(ctx, businessID)
+	if business == nil || !kybEditable(ctx, business) {
+		return nil
+	}
+	if business.KYB == nil {
+		apperrors.ClientError(ctx, "Add your business's registration details before submitting it for review", nil)
+		return nil
+	}
+	for _, required := range entities.RequiredBusinessDocuments {
+		uploaded := false
+		for _, document := range business.KYB.Documents {
+			if document.Type == required {
+				uploaded = true
+				break
+			}
+		}
+		if !uploaded {
+			apperrors.ClientError(ctx, fmt.Sprintf("Upload your business's %s before submitting it for review", required), nil)
+			return nil
+		}
+	}
+	now := time.Now()
+	_, err := repository.BusinessRepo().UpdatePartialByID(businessID, map[string]any{
+		"kybStatus": entities.KYBPendingReview,
+		"kyb.submittedAt": now,
+		"kyb.rejectionReason": nil,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	business.KYBStatus = entities.KYBPendingReview
+	business.KYB.SubmittedAt = &now
+	business.KYB.RejectionReason = nil
+	return business
+}
+
+func FetchBusinessKYBReviews(ctx any, status entities.KYBStatus) *[]entities.Business {
+	if status == "" {
+		status = entities.KYBPendingReview
+	}
+	businesses, err := repository.BusinessRepo().FindMany(map[string]interface{}{
+		"kybStatus": status,
+	}, options.Find().SetSort(bson.D{{Key: "kyb.submittedAt", Value: 1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return businesses
+}
+
+// ReviewBusinessKYB records an admin's decision on a business and tells its owner.
+func ReviewBusinessKYB(ctx any, reviewerID string, businessID string, approve bool, reason *string) error {
+	if !approve && (reason == nil || *reason == "") {
+		err := errors.New("Give a reason for rejecting this business so its owner can fix it")
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	status := entities.KYBRejected
+	if approve {
+		status = entities.KYBApproved
+		reason = nil
+	}
+	businessRepository := repository.BusinessRepo()
+	affected, err := businessRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": businessID,
+		"kybStatus": entities.KYBPendingReview,
+	}, map[string]any{
+		"$set": map[string]any{
+			"kybStatus": status,
+			"kyb.reviewedAt": time.Now(),
+			"kyb.reviewedBy": reviewerID,
+			"kyb.rejectionReason": reason,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if affected == 0 {
+		err = errors.New("This business is not waiting to be reviewed")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	business, err := businessRepository.FindByID(businessID)
+	if err != nil || business == nil {
+		return nil
+	}
+	owner, err := repository.UserRepo().FindByID(business.UserID)
+	if err != nil || owner == nil {
+		return nil
+	}
+	err = events.Publish(nil, events.BusinessKYBReviewedPayload{
+		UserID: owner.ID,
+		Email: owner.Email,
+		FirstName: owner.FirstName,
+		BusinessID: business.ID,
+		BusinessName: business.Name,
+		Approved: approve,
+		Reason: reason,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not publish kyb review"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		})
+	}
+	return nil
+}
+
+// verifyBusinessPayoutLimits keeps businesses that have not passed KYB to small payouts.
+// The daily limit is enforced when the payout's funds are locked, see reserveBusinessPayoutAllowance.
+func verifyBusinessPayoutLimits(ctx any, businessID string, amountInNGN money.Money) error {
+	business, err := repository.BusinessRepo().FindByID(businessID, options.FindOne().SetProjection(map[string]any{
+		"kybStatus": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if business == nil {
+		err = errors.New("business does not exist")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	if business.KYBStatus != entities.KYBApproved && amountInNGN.Amount > constants.UNVERIFIED_BUSINESS_MAX_PAYOUT_KOBO {
+		err = fmt.Errorf("Businesses that have not been verified cannot send more than %s at a time. Verify your business to raise your limits.", money.NGN(constants.UNVERIFIED_BUSINESS_MAX_PAYOUT_KOBO).Format())
+		apperrors.ForbiddenError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
+
+func businessPayoutAllowanceKey(businessID string, day time.Time) string {
+	return fmt.Sprintf("%s-payouts-%s", businessID, day.UTC().Format("2006-01-02"))
+}
+
+// reserveBusinessPayoutAllowance counts the payout against the business's daily limit. The amount is added to
+// the day's total and checked in one step so payouts made at the same time cannot get past the limit together.
+// A payout that does not go out gives its amount back with releaseBusinessPayoutAllowance.
+func reserveBusinessPayoutAllowance(ctx any, businessID string, amountInNGN money.Money, day time.Time) error {
+	business, err := repository.BusinessRepo().FindByID(businessID, options.FindOne().SetProjection(map[string]any{
+		"kybStatus": 1,
+	}))
+	if err != nil || business == nil {
+		apperrors.FatalServerError(ctx)
+		return errors.New("could not find business to check its payout limit")
+	}
+	dailyLimit := money.NGN(constants.VERIFIED_BUSINESS_DAILY_PAYOUT_KOBO)
+	if business.KYBStatus != entities.KYBApproved {
+		dailyLimit = money.NGN(constants.UNVERIFIED_BUSINESS_DAILY_PAYOUT_KOBO)
+	}
+	key := businessPayoutAllowanceKey(businessID, day)
+	sent, err := cache.Cache.IncrementBy(key, amountInNGN.Amount, 48 * time.Hour)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if sent > dailyLimit.Amount {
+		cache.Cache.IncrementBy(key, -amountInNGN.Amount, 48 * time.Hour)
+		err = fmt.Errorf("This payment would take this business over its limit of %s for today", dailyLimit.Format())
+		if business.KYBStatus != entities.KYBApproved {
+			err = fmt.Errorf("%s. Verify your business to raise your limits.", err.Error())
+		}
+		apperrors.ForbiddenError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
+
+// releaseBusinessPayoutAllowance gives back the part of a business's daily limit a payout reserved when it did not go out.
+func releaseBusinessPayoutAllowance(businessID string, amountInNGN money.Money, day time.Time) {
+	_, err := cache.Cache.IncrementBy(businessPayoutAllowanceKey(businessID, day), -amountInNGN.Amount, 48 * time.Hour)
+	if err != nil {
+		logger.Error(errors.New("could not release business payout allowance"), logger.LoggerOptions{
+			Key: "businessID",
+			Data: businessID,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	}
+}
+
+// findBusiness loads a business the user has already been authorised to act for.
+func findBusiness(ctx any, businessID string) *entities.Business {
+	business, err := repository.BusinessRepo().FindByID(businessID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if business == nil {
+		apperrors.NotFoundError(ctx, "business does not exist")
+		return nil
+	}
+	return business
+}
+
+// kybEditable stops a business's details changing while they are being reviewed or once they have been approved.
+func kybEditable(ctx any, business *entities.Business) bool {
+	switch business.KYBStatus {
+	case entities.KYBPendingReview:
+		apperrors.ClientError(ctx, "Your business is being reviewed and cannot be changed until the review is done", nil)
+		return false
+	case entities.KYBApproved:
+		apperrors.ClientError(ctx, fmt.Sprintf("Your business has been verified. Please contact support on %s to change its details.", constants.SUPPORT_EMAIL), nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/entities"
+)
+
+func FetchNotificationPreferences(ctx any, userID string) *entities.NotificationPreferences {
+	preferences, err := events.FindNotificationPreferences(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return preferences
+}
+
+// UpdateNotificationPreferences applies the categories passed in and leaves the rest as they were.
+func UpdateNotificationPreferences(ctx any, userID string, payload *dto.NotificationPreferencesDTO) *entities.NotificationPreferences {
+	for _, channel := range []*dto.ChannelPreferencesDTO{payload.Email, payload.Push, payload.SMS} {
+		if channel != nil && channel.Security != nil && !*channel.Security {
+			apperrors.ClientError(ctx, "Security notifications cannot be turned off", nil)
+			return nil
+		}
+	}
+	if payload.OTPDelivery != nil && *payload.OTPDelivery != entities.OTPByEmail {
+		user, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+			"phone": 1,
+		}))
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		if user == nil || user.Phone.LocalNumber == "" {
+			apperrors.ClientError(ctx, "Add a phone number to your account to receive OTPs by SMS", nil)
+			return nil
+		}
+	}
+	preferences := FetchNotificationPreferences(ctx, userID)
+	if preferences == nil {
+		return nil
+	}
+	applyChannelPreferences(&preferences.Email, payload.Email)
+	applyChannelPreferences(&preferences.Push, payload.Push)
+	applyChannelPreferences(&preferences.SMS, payload.SMS)
+	if payload.OTPDelivery != nil {
+		preferences.OTPDelivery = *payload.OTPDelivery
+	}
+	preferencesRepository := repository.NotificationPreferencesRepo()
+	if preferences.ID == "" {
+		created, err := preferencesRepository.CreateOne(nil, *preferences)
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		return created
+	}
+	_, err := preferencesRepository.UpdatePartialByID(preferences.ID, map[string]any{
+		"email": preferences.Email,
+		"push": preferences.Push,
+		"sms": preferences.SMS,
+		"otpDelivery": preferences.OTPDelivery,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return preferences
+}
+
+func applyChannelPreferences(preferences *entities.ChannelPreferences, payload *dto.ChannelPreferencesDTO) {
+	if payload == nil {
+		return
+	}
+	if payload.Transactions != nil {
+		preferences.Transactions = *payload.Transactions
+	}
+	if payload.Marketing != nil {
+		preferences.Marketing = *payload.Marketing
+	}
+	if payload.Product != nil {
+		preferences.Product = *payload.Product
+	}
+	preferences.Security = true
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+)
+
+// FetchNotifications returns a page of the user's inbox, newest first.
+func FetchNotifications(ctx any, userID string, page int64, limit int64, unreadOnly bool) (*[]entities.Notification, int64) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > constants.NOTIFICATIONS_MAX_PAGE_SIZE {
+		limit = constants.NOTIFICATIONS_MAX_PAGE_SIZE
+	}
+	filter := map[string]interface{}{
+		"userID": userID,
+	}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	notificationRepository := repository.NotificationRepo()
+	total, err := notificationRepository.CountDocs(filter)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, 0
+	}
+	notifications, err := notificationRepository.FindMany(filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetSkip((page - 1) * limit).SetLimit(limit))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, 0
+	}
+	return notifications, total
+}
+
+func CountUnreadNotifications(ctx any, userID string) *int64 {
+	count, err := repository.NotificationRepo().CountDocs(map[string]interface{}{
+		"userID": userID,
+		"read": false,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return &count
+}
+
+func MarkNotificationRead(ctx any, userID string, notificationID string) error {
+	affected, err := repository.NotificationRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": notificationID,
+		"userID": userID,
+		"read": false,
+	}, map[string]any{
+		"$set": map[string]any{
+			"read": true,
+			"readAt": time.Now(),
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if affected == 0 {
+		// the notification may already be read
+		count, err := repository.NotificationRepo().CountDocs(map[string]interface{}{
+			"_id": notificationID,
+			"userID": userID,
+		})
+		if err != nil || count == 0 {
+			apperrors.NotFoundError(ctx, "notification not found")
+			return errors.New("notification not found")
+		}
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks every unread notification in the user's inbox as read and returns how many changed.
+func MarkAllNotificationsRead(ctx any, userID string) *int64 {
+	affected, err := repository.NotificationRepo().UpdateManyWithOperator(map[string]interface{}{
+		"userID": userID,
+		"read": false,
+	}, map[string]any{
+		"$set": map[string]any{
+			"read": true,
+			"readAt": time.Now(),
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return &affected
+}
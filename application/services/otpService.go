@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/events"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+)
+
+// SendAccountOTP sends the user an otp for the purpose on the channels they get otps on.
+// subject is what the otp is saved against, the same one has to be given to auth.VerifyOTP.
+func SendAccountOTP(ctx any, account *entities.User, purpose auth.OTPPurpose, subject string, title string) error {
+	otp, blocked, err := auth.GenerateOTP(purpose, subject)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx, blocked.Message(), blocked.RetryAfter, false)
+		return errors.New(blocked.Message())
+	}
+	channels, err := events.OTPChannels(account.ID, account.Phone.LocalNumber != "", "")
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	err = events.Publish(nil, events.OTPRequestedPayload{
+		UserID: account.ID,
+		Email: account.Email,
+		FirstName: account.FirstName,
+		OTP: *otp,
+		Subject: title,
+		Channels: channels,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
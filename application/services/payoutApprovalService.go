@@ -0,0 +1,595 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/events"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// someone who can approve a business's payouts
+type payoutApprover struct {
+	UserID 		string
+	Email 		string
+	FirstName 	string
+	Role 		entities.BusinessRole
+}
+
+// StartPayoutApprovalWorker cancels payouts that were not approved in time and returns their funds.
+func StartPayoutApprovalWorker() {
+	go func() {
+		ticker := time.NewTicker(constants.PAYOUT_APPROVAL_POLL_INTERVAL)
+		defer ticker.Stop()
+		for range ticker.C {
+			expirePayoutApprovals()
+		}
+	}()
+}
+
+// SetPayoutApprovalPolicy replaces the business's approval rules. Sending no rules turns approvals off.
+func SetPayoutApprovalPolicy(ctx any, userID string, businessID string, payload *dto.PayoutApprovalPolicyDTO) *entities.Business {
+	business := findBusiness(ctx, businessID)
+	if business == nil {
+		return nil
+	}
+	var policy *entities.PayoutApprovalPolicy
+	if len(payload.Rules) != 0 {
+		approvers, err := findPayoutApprovers(business)
+		if err != nil {
+			apperrors.FatalServerError(ctx)
+			return nil
+		}
+		for _, rule := range payload.Rules {
+			eligible := len(eligiblePayoutApprovers(approvers, rule, ""))
+			if eligible < rule.Approvals {
+				apperrors.ClientError(ctx, fmt.Sprintf("Payouts of %s or more need %d approvals but only %d people in this business can approve them", money.NGN(rule.Threshold).Format(), rule.Approvals, eligible), nil)
+				return nil
+			}
+		}
+		expiresAfterHours := payload.ExpiresAfterHours
+		if expiresAfterHours == 0 {
+			expiresAfterHours = constants.DEFAULT_PAYOUT_APPROVAL_EXPIRY_HOURS
+		}
+		policy = &entities.PayoutApprovalPolicy{
+			Rules: payload.Rules,
+			ExpiresAfterHours: expiresAfterHours,
+			UpdatedBy: userID,
+			UpdatedAt: time.Now(),
+		}
+	}
+	_, err := repository.BusinessRepo().UpdatePartialByID(businessID, map[string]any{
+		"payoutApprovalPolicy": policy,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	business.PayoutApprovalPolicy = policy
+	return business
+}
+
+func FetchPayoutApprovalPolicy(ctx any, businessID string) (*entities.PayoutApprovalPolicy, bool) {
+	business := findBusiness(ctx, businessID)
+	if business == nil {
+		return nil, false
+	}
+	return business.PayoutApprovalPolicy, true
+}
+
+// PayoutApprovalRuleFor returns the rule a payout has to be approved under, or nil if it can be sent straight away.
+// Payouts that could never get enough approvals are turned down before any funds are locked.
+func PayoutApprovalRuleFor(ctx any, businessID string, requesterID string, amountInNGN money.Money) (*entities.PayoutApprovalRule, bool) {
+	business := findBusiness(ctx, businessID)
+	if business == nil {
+		return nil, false
+	}
+	if business.PayoutApprovalPolicy == nil {
+		return nil, true
+	}
+	rule := business.PayoutApprovalPolicy.RuleFor(amountInNGN.Amount)
+	if rule == nil {
+		return nil, true
+	}
+	approvers, err := findPayoutApprovers(business)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, false
+	}
+	eligible := len(eligiblePayoutApprovers(approvers, *rule, requesterID))
+	if eligible < rule.Approvals {
+		apperrors.ClientError(ctx, fmt.Sprintf("This payment needs %d approvals but only %d other people in this business can approve it", rule.Approvals, eligible), nil)
+		return nil, false
+	}
+	return rule, true
+}
+
+// HoldPayoutForApproval records a payout whose funds have been locked and asks the business's approvers to approve it.
+// quoteExpiresAt is when the quote an international payout was priced with expires, and nil for local payouts.
+func HoldPayoutForApproval(ctx any, requesterID string, rule entities.PayoutApprovalRule, lockedFunds entities.LockedFunds, transaction entities.Transaction, quoteExpiresAt *time.Time) *entities.PayoutApproval {
+	business := findBusiness(ctx, *transaction.BusinessID)
+	if business == nil || business.PayoutApprovalPolicy == nil {
+		UnlockFunds(transaction.WalletID, lockedFunds)
+		if business != nil {
+			apperrors.ClientError(ctx, "Payment approvals have been turned off for this business. Try sending the payment again.", nil)
+		}
+		return nil
+	}
+	approval, err := repository.PayoutApprovalRepo().CreateOne(nil, entities.PayoutApproval{
+		BusinessID: business.ID,
+		WalletID: transaction.WalletID,
+		RequestedBy: requesterID,
+		Rule: rule,
+		Status: entities.PayoutAwaitingApproval,
+		LockedFunds: lockedFunds,
+		Transaction: transaction,
+		QuoteExpiresAt: quoteExpiresAt,
+		Approvers: []entities.PayoutApprover{},
+		ExpiresAt: time.Now().Add(time.Duration(business.PayoutApprovalPolicy.ExpiresAfterHours) * time.Hour),
+	})
+	if err != nil {
+		UnlockFunds(transaction.WalletID, lockedFunds)
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	approvers, err := findPayoutApprovers(business)
+	if err == nil {
+		for _, approver := range eligiblePayoutApprovers(approvers, rule, requesterID) {
+			err = events.Publish(nil, events.PayoutApprovalRequestedPayload{
+				UserID: approver.UserID,
+				Email: approver.Email,
+				FirstName: approver.FirstName,
+				ApprovalID: approval.ID,
+				BusinessID: business.ID,
+				BusinessName: business.Name,
+				RequesterName: fmt.Sprintf("%s %s", transaction.Sender.FirstName, transaction.Sender.LastName),
+				Amount: transaction.Amount,
+				RecipientName: transaction.Recepient.Name,
+				ExpiresAt: approval.ExpiresAt,
+			})
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		logger.Error(errors.New("could not notify payout approvers"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "approvalID",
+			Data: approval.ID,
+		})
+	}
+	return approval
+}
+
+func FetchPayoutApprovals(ctx any, businessID string, status entities.PayoutApprovalStatus) *[]entities.PayoutApproval {
+	filter := map[string]interface{}{
+		"businessID": businessID,
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	approvals, err := repository.PayoutApprovalRepo().FindMany(filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(100))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return approvals
+}
+
+// ApprovePayout records the user's approval and sends the payout once it has as many as its rule needs.
+func ApprovePayout(ctx any, userID string, role entities.BusinessRole, businessID string, approvalID string, pin string, confirmation PayoutConfirmation) *entities.PayoutApproval {
+	approval := findPendingPayoutApproval(ctx, businessID, approvalID)
+	if approval == nil || !canDecidePayout(ctx, userID, role, approval) || !verifyTransactionPin(ctx, userID, pin) {
+		return nil
+	}
+	if !VerifyPayoutConfirmation(ctx, userID, approval.Transaction.AmountInNGN, confirmation) {
+		return nil
+	}
+	approver, err := repository.UserRepo().FindByID(userID)
+	if err != nil || approver == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	approvalRepository := repository.PayoutApprovalRepo()
+	now := time.Now()
+	affected, err := approvalRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": approval.ID,
+		"status": entities.PayoutAwaitingApproval,
+		"approvers.userID": map[string]any{
+			"$ne": userID,
+		},
+		"expiresAt": map[string]any{
+			"$gt": now,
+		},
+	}, map[string]any{
+		"$push": map[string]any{
+			"approvers": entities.PayoutApprover{
+				UserID: userID,
+				Name: fmt.Sprintf("%s %s", approver.FirstName, approver.LastName),
+				Role: role,
+				ApprovedAt: now,
+			},
+		},
+		"$set": map[string]any{
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		apperrors.ClientError(ctx, "You have already approved this payment or it has been decided", nil)
+		return nil
+	}
+	approval, err = approvalRepository.FindByID(approval.ID)
+	if err != nil || approval == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if len(approval.Approvers) < approval.Rule.Approvals {
+		return approval
+	}
+	// only the approval that completes the count sends the payout
+	affected, err = approvalRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": approval.ID,
+		"status": entities.PayoutAwaitingApproval,
+	}, map[string]any{
+		"$set": map[string]any{
+			"status": entities.PayoutApproved,
+			"decidedAt": now,
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		return approval
+	}
+	approval.Status = entities.PayoutApproved
+	approval.DecidedAt = &now
+	return sendApprovedPayout(ctx, approval)
+}
+
+// RejectPayout cancels the payout and returns its funds. Whoever made the payout can also reject it to withdraw it.
+func RejectPayout(ctx any, userID string, role entities.BusinessRole, businessID string, approvalID string, pin string, reason *string) *entities.PayoutApproval {
+	approval := findPendingPayoutApproval(ctx, businessID, approvalID)
+	if approval == nil {
+		return nil
+	}
+	if approval.RequestedBy != userID && !canDecidePayout(ctx, userID, role, approval) {
+		return nil
+	}
+	if !verifyTransactionPin(ctx, userID, pin) {
+		return nil
+	}
+	now := time.Now()
+	affected, err := repository.PayoutApprovalRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": approval.ID,
+		"status": entities.PayoutAwaitingApproval,
+	}, map[string]any{
+		"$set": map[string]any{
+			"status": entities.PayoutRejected,
+			"rejectedBy": userID,
+			"rejectionReason": reason,
+			"decidedAt": now,
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		apperrors.ClientError(ctx, "This payment has already been decided", nil)
+		return nil
+	}
+	approval.Status = entities.PayoutRejected
+	approval.RejectedBy = &userID
+	approval.RejectionReason = reason
+	approval.DecidedAt = &now
+	UnlockFunds(approval.WalletID, approval.LockedFunds)
+	if approval.RequestedBy != userID {
+		notifyPayoutRequester(approval)
+	}
+	return approval
+}
+
+func sendApprovedPayout(ctx any, approval *entities.PayoutApproval) *entities.PayoutApproval {
+	approvalRepository := repository.PayoutApprovalRepo()
+	if approval.QuoteExpiresAt != nil && time.Now().After(*approval.QuoteExpiresAt) && !requoteApprovedPayout(ctx, approval) {
+		failApprovedPayout(approval)
+		return nil
+	}
+	var trx *entities.Transaction
+	var err error
+	if approval.Transaction.Intent == entities.ChimoneyDebitInternational {
+		trx, err = SendInternationalPayout(ctx, &approval.Transaction)
+	} else {
+		trx, err = SendLocalPayout(ctx, &approval.Transaction)
+	}
+	if errors.Is(err, ErrPayoutNotSent) {
+		failApprovedPayout(approval)
+		return nil
+	}
+	if err != nil {
+		// sent but not recorded. Left as approved so it is looked into rather than sent again.
+		logger.Error(errors.New("approved payout was sent but not recorded"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "approvalID",
+			Data: approval.ID,
+		})
+		return nil
+	}
+	_, err = approvalRepository.UpdatePartialByID(approval.ID, map[string]any{
+		"status": entities.PayoutApprovalSent,
+		"transactionID": trx.ID,
+		"transaction": trx,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not mark approved payout as sent"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "approvalID",
+			Data: approval.ID,
+		})
+	}
+	approval.Status = entities.PayoutApprovalSent
+	approval.TransactionID = &trx.ID
+	approval.Transaction = *trx
+	notifyPayoutRequester(approval)
+	return approval
+}
+
+// requoteApprovedPayout prices an international payout again at today's rate when its quote expired while it
+// waited for approval. The payout is only sent if the funds locked for it still cover it, and any left over
+// are returned to the wallet.
+func requoteApprovedPayout(ctx any, approval *entities.PayoutApproval) bool {
+	transaction := &approval.Transaction
+	conversion, snapshot := ConvertToNGN(ctx, transaction.Amount)
+	if conversion == nil {
+		return false
+	}
+	fees := CalculateTransactionFees(ctx, approval.BusinessID, transaction.Intent, transaction.Recepient.Country, conversion.ValueInNGN)
+	if fees == nil {
+		return false
+	}
+	totalAmount, err := fees.Total(conversion.ValueInNGN)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return false
+	}
+	surplus, err := approval.LockedFunds.Amount.Sub(totalAmount)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return false
+	}
+	if surplus.IsNegative() {
+		apperrors.ClientError(ctx, "The exchange rate has moved since this payment was requested and the funds held for it no longer cover it. The payment has been cancelled and its funds returned. Send it again to continue.", nil)
+		return false
+	}
+	if surplus.IsPositive() {
+		if err := ReturnLockedFunds(approval.WalletID, approval.LockedFunds.LockedFundsID, surplus); err != nil {
+			apperrors.FatalServerError(ctx)
+			return false
+		}
+		approval.LockedFunds.Amount = totalAmount
+	}
+	transaction.AmountInUSD = &conversion.ValueInUSD
+	transaction.AmountInNGN = totalAmount
+	transaction.Fee = fees.PlatformFee
+	transaction.ProcessorFee = fees.ProcessorFee
+	transaction.PricingPlan = &fees.PricingPlan
+	transaction.ExchangeRate = &conversion.Rate
+	transaction.ExchangeRateSnapshotID = &snapshot.ID
+	_, err = repository.PayoutApprovalRepo().UpdatePartialByID(approval.ID, map[string]any{
+		"lockedFunds": approval.LockedFunds,
+		"transaction": approval.Transaction,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not record payout quoted again"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "approvalID",
+			Data: approval.ID,
+		})
+	}
+	return true
+}
+
+// failApprovedPayout returns the funds of an approved payout that could not be sent.
+func failApprovedPayout(approval *entities.PayoutApproval) {
+	repository.PayoutApprovalRepo().UpdatePartialByID(approval.ID, map[string]any{
+		"status": entities.PayoutApprovalFailed,
+	})
+	approval.Status = entities.PayoutApprovalFailed
+	UnlockFunds(approval.WalletID, approval.LockedFunds)
+	notifyPayoutRequester(approval)
+}
+
+func expirePayoutApprovals() {
+	approvalRepository := repository.PayoutApprovalRepo()
+	now := time.Now()
+	expired, err := approvalRepository.FindMany(map[string]interface{}{
+		"status": entities.PayoutAwaitingApproval,
+		"expiresAt": map[string]any{
+			"$lte": now,
+		},
+	}, options.Find().SetLimit(50))
+	if err != nil {
+		return
+	}
+	for _, approval := range *expired {
+		affected, err := approvalRepository.UpdateManyWithOperator(map[string]interface{}{
+			"_id": approval.ID,
+			"status": entities.PayoutAwaitingApproval,
+		}, map[string]any{
+			"$set": map[string]any{
+				"status": entities.PayoutApprovalExpired,
+				"decidedAt": now,
+				"updatedAt": now,
+			},
+		})
+		if err != nil || affected == 0 {
+			continue
+		}
+		approval.Status = entities.PayoutApprovalExpired
+		UnlockFunds(approval.WalletID, approval.LockedFunds)
+		notifyPayoutRequester(&approval)
+	}
+}
+
+func findPendingPayoutApproval(ctx any, businessID string, approvalID string) *entities.PayoutApproval {
+	approval, err := repository.PayoutApprovalRepo().FindOneByFilter(map[string]interface{}{
+		"_id": approvalID,
+		"businessID": businessID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if approval == nil {
+		apperrors.NotFoundError(ctx, "This payment was not found")
+		return nil
+	}
+	if approval.Status != entities.PayoutAwaitingApproval {
+		apperrors.ClientError(ctx, fmt.Sprintf("This payment is no longer waiting for approval. It was %s.", approval.Status), nil)
+		return nil
+	}
+	if time.Now().After(approval.ExpiresAt) {
+		apperrors.ClientError(ctx, "This payment was not approved in time and has been cancelled", nil)
+		return nil
+	}
+	return approval
+}
+
+// canDecidePayout stops people approving their own payouts or ones their role is not trusted with.
+func canDecidePayout(ctx any, userID string, role entities.BusinessRole, approval *entities.PayoutApproval) bool {
+	if approval.RequestedBy == userID {
+		apperrors.ForbiddenError(ctx, "You cannot approve a payment you made")
+		return false
+	}
+	for _, approverRole := range approval.Rule.ApproverRoles {
+		if approverRole == role {
+			return true
+		}
+	}
+	apperrors.ForbiddenError(ctx, "Your role in this business cannot approve this payment")
+	return false
+}
+
+// verifyTransactionPin checks the pin of someone moving money without going through InitiatePreAuth.
+func verifyTransactionPin(ctx any, userID string, pin string) bool {
+	success, err := verifyTransactionPinByUserID(ctx, userID, pin)
+	if err != nil || !success {
+		return false
+	}
+	user, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"transactionPinResetAt": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	return verifyTransactionPinCoolingOff(ctx, user) == nil
+}
+
+// findPayoutApprovers returns the business's owner and every active member who can send payouts.
+func findPayoutApprovers(business *entities.Business) ([]payoutApprover, error) {
+	owner, err := repository.UserRepo().FindByID(business.UserID, options.FindOne().SetProjection(map[string]any{
+		"email": 1,
+		"firstName": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	approvers := []payoutApprover{}
+	if owner != nil {
+		approvers = append(approvers, payoutApprover{
+			UserID: owner.ID,
+			Email: owner.Email,
+			FirstName: owner.FirstName,
+			Role: entities.BusinessOwner,
+		})
+	}
+	members, err := repository.BusinessMemberRepo().FindMany(map[string]interface{}{
+		"businessID": business.ID,
+		"status": entities.BusinessMemberActive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range *members {
+		if member.UserID == nil || !member.Role.Can(entities.SendPayouts) {
+			continue
+		}
+		approver := payoutApprover{
+			UserID: *member.UserID,
+			Email: member.Email,
+			Role: member.Role,
+		}
+		if member.FirstName != nil {
+			approver.FirstName = *member.FirstName
+		}
+		approvers = append(approvers, approver)
+	}
+	return approvers, nil
+}
+
+func eligiblePayoutApprovers(approvers []payoutApprover, rule entities.PayoutApprovalRule, requesterID string) []payoutApprover {
+	eligible := []payoutApprover{}
+	for _, approver := range approvers {
+		if approver.UserID == requesterID {
+			continue
+		}
+		for _, role := range rule.ApproverRoles {
+			if approver.Role == role {
+				eligible = append(eligible, approver)
+				break
+			}
+		}
+	}
+	return eligible
+}
+
+func notifyPayoutRequester(approval *entities.PayoutApproval) {
+	businessName := approval.Transaction.Sender.BusinessName
+	err := events.Publish(nil, events.PayoutApprovalDecidedPayload{
+		UserID: approval.RequestedBy,
+		Email: approval.Transaction.Sender.Email,
+		FirstName: approval.Transaction.Sender.FirstName,
+		ApprovalID: approval.ID,
+		BusinessID: approval.BusinessID,
+		BusinessName: businessName,
+		Amount: approval.Transaction.Amount,
+		Status: approval.Status,
+		Reason: approval.RejectionReason,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not publish payout approval decision"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "approvalID",
+			Data: approval.ID,
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	international_payment_processor "kego.com/infrastructure/payment_processor/chimoney"
+	"kego.com/infrastructure/payment_processor/types"
+)
+
+// ErrPayoutNotSent means the payment provider did not take the payout, so its locked funds can be returned.
+var ErrPayoutNotSent = errors.New("payout was not sent")
+
+// SendLocalPayout hands a naira payout to the local payment processor and records it.
+// transaction is filled in with what the processor returns.
+func SendLocalPayout(ctx any, transaction *entities.Transaction) (*entities.Transaction, error) {
+	reference := utils.GenerateUUIDString()
+	response := InitiateLocalPayment(ctx, &types.InitiateLocalTransferPayload{
+		AccountNumber: transaction.Recepient.AccountNumber,
+		AccountBank: transaction.Recepient.BankCode,
+		Currency: "NGN",
+		Amount: json.Number(transaction.Amount.Decimal()),
+		Narration: transaction.Description,
+		Reference: reference,
+		DebitCurrency: "NGN",
+		CallbackURL: os.Getenv("FLUTTERWAVE_TRANSFER_CALLBACK_URL"),
+	})
+	if response == nil {
+		return nil, ErrPayoutNotSent
+	}
+	transaction.TransactionReference = reference
+	transaction.MetaData = response
+	transaction.Recepient.Name = response.FullName
+	trx := RecordPayout(ctx, *transaction)
+	if trx == nil {
+		return nil, errors.New("could not record payout")
+	}
+	return trx, nil
+}
+
+// SendInternationalPayout hands a payout to Chimoney and records it. transaction.AmountInUSD must hold the
+// value quoted for the payout and is replaced with what Chimoney says it sent.
+func SendInternationalPayout(ctx any, transaction *entities.Transaction) (*entities.Transaction, error) {
+	destinationCountry := utils.CountryCodeToCountryName(transaction.Recepient.Country)
+	if os.Getenv("GIN_MODE") != "release" {
+		destinationCountry = utils.CountryCodeToCountryName("NG")
+	}
+	quotedValueInUSD := *transaction.AmountInUSD
+	response := InitiateInternationalPayment(ctx, &international_payment_processor.InternationalPaymentRequestPayload{
+		DestinationCountry: destinationCountry,
+		AccountNumber: transaction.Recepient.AccountNumber,
+		BankCode: transaction.Recepient.BankCode,
+		ValueInUSD: json.Number(quotedValueInUSD.Decimal()),
+	})
+	if response == nil {
+		return nil, ErrPayoutNotSent
+	}
+	amountInUSD, err := money.ParseDecimal(response.Chimoneys[0].ValueInUSD.String(), "USD")
+	if err != nil {
+		amountInUSD = quotedValueInUSD
+	}
+	transaction.TransactionReference = response.Chimoneys[0].ChiRef
+	transaction.MetaData = response.Chimoneys[0]
+	transaction.AmountInUSD = &amountInUSD
+	trx := RecordPayout(ctx, *transaction)
+	if trx == nil {
+		return nil, errors.New("could not record payout")
+	}
+	return trx, nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/application/services/types"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+	"kego.com/infrastructure/cryptography"
+	"kego.com/infrastructure/database/repository/cache"
+	fileupload "kego.com/infrastructure/file_upload"
+	identityverification "kego.com/infrastructure/identity_verification"
+	"kego.com/infrastructure/logger"
+)
+
+// ChangeTransactionPin replaces the user's pin. Wrong current pins count towards the same tries as payouts do.
+func ChangeTransactionPin(ctx any, userID string, currentPin string, password string, newPin string) error {
+	success, err := verifyTransactionPinByUserID(ctx, userID, currentPin)
+	if err != nil || !success {
+		return errors.New("could not verify transaction pin")
+	}
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if !VerifyPin(ctx, account, password, &types.PinSelectionType{Password: true}) {
+		return errors.New("wrong password")
+	}
+	err = saveTransactionPin(ctx, userID, newPin, nil)
+	if err != nil {
+		return err
+	}
+	notifyTransactionPinChanged(account, "Your transaction pin was changed", "The transaction pin on your Kego account was just changed.")
+	return nil
+}
+
+// RequestTransactionPinReset sends the user an OTP to start resetting a pin they have forgotten.
+func RequestTransactionPinReset(ctx any, userID string) error {
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if account == nil {
+		err = errors.New("this account does not exist")
+		apperrors.NotFoundError(ctx, err.Error())
+		return err
+	}
+	if !account.KYCCompleted || account.ProfileImage == "" {
+		err = fmt.Errorf("Complete your identity verification before resetting your pin or contact support on %s", constants.SUPPORT_EMAIL)
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	return SendAccountOTP(ctx, account, auth.OTPPinReset, userID, "Reset your transaction pin")
+}
+
+// ResetTransactionPin sets a new pin for a user who has forgotten theirs once they prove who they are with an OTP
+// and a selfie that matches the picture taken during their identity verification.
+// Payouts are paused for a cooling-off period afterwards in case someone else has taken over the account.
+func ResetTransactionPin(ctx any, userID string, otp string, selfie *multipart.FileHeader, newPin string, ipAddress string) error {
+	msg, success, blocked := auth.VerifyOTP(auth.OTPPinReset, userID, otp, ipAddress)
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx, msg, blocked.RetryAfter, blocked.Locked)
+		return errors.New(msg)
+	}
+	if !success {
+		apperrors.ClientError(ctx, msg, nil)
+		return errors.New(msg)
+	}
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if account == nil || account.ProfileImage == "" {
+		err = fmt.Errorf("Complete your identity verification before resetting your pin or contact support on %s", constants.SUPPORT_EMAIL)
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	// uploaded under its own name so the profile picture is not replaced
+	selfieID := fmt.Sprintf("%s-pin-reset", userID)
+	url, err := fileupload.FileUploader.UploadSingleFile(selfie, &selfieID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	defer func() {
+		if err := fileupload.FileUploader.DeleteSingleFile(selfieID); err != nil {
+			logger.Error(errors.New("could not delete pin reset selfie"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "userID",
+				Data: userID,
+			})
+		}
+	}()
+	result, err := identityverification.IdentityVerifier.FaceMatch(*url, account.ProfileImage)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	if *result < constants.FACE_MATCH_MIN_CONFIDENCE {
+		err = fmt.Errorf("Your picture does not match the one taken when you verified your identity. If you think this is a mistake please contact support on %s", constants.SUPPORT_EMAIL)
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
+	now := time.Now()
+	err = saveTransactionPin(ctx, userID, newPin, &now)
+	if err != nil {
+		return err
+	}
+	// the user has proven who they are so they are no longer locked out
+	cache.Cache.DeleteOne(fmt.Sprintf("%s-transaction-pin-tries", userID))
+	notifyTransactionPinChanged(account, "Your transaction pin was reset", fmt.Sprintf("The transaction pin on your Kego account was just reset. You will be able to send money again in %d hours.", int(constants.TRANSACTION_PIN_RESET_COOLING_OFF.Hours())))
+	return nil
+}
+
+// saveTransactionPin hashes and stores the pin. resetAt is set when a forgotten pin was reset to start the cooling-off period.
+func saveTransactionPin(ctx any, userID string, pin string, resetAt *time.Time) error {
+	hashedPin, err := cryptography.CryptoHahser.HashString(pin)
+	if err != nil {
+		logger.Error(errors.New("error hashing users new transaction pin"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	update := map[string]any{
+		"transactionPin": string(hashedPin),
+	}
+	if resetAt != nil {
+		update["transactionPinResetAt"] = resetAt
+	}
+	_, err = repository.UserRepo().UpdatePartialByID(userID, update)
+	if err != nil {
+		logger.Error(errors.New("error while updating user transaction pin"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		})
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+// verifyTransactionPinCoolingOff stops payouts for a while after a forgotten pin is reset.
+func verifyTransactionPinCoolingOff(ctx any, user *entities.User) error {
+	if user.TransactionPinResetAt == nil {
+		return nil
+	}
+	resumesAt := user.TransactionPinResetAt.Add(constants.TRANSACTION_PIN_RESET_COOLING_OFF)
+	if time.Now().Before(resumesAt) {
+		err := fmt.Errorf("Your transaction pin was recently reset so you cannot send money until %s", resumesAt.Format(time.RFC1123))
+		apperrors.ForbiddenError(ctx, err.Error())
+		return err
+	}
+	return nil
+}
+
+func notifyTransactionPinChanged(account *entities.User, title string, body string) {
+	err := events.Publish(nil, events.SecurityAlertPayload{
+		UserID: account.ID,
+		Email: account.Email,
+		FirstName: account.FirstName,
+		Title: title,
+		Body: body,
+	})
+	if err != nil {
+		logger.Error(errors.New("could not publish transaction pin security alert"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: account.ID,
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+	"kego.com/infrastructure/payment_processor/types"
+	"kego.com/infrastructure/validator"
+)
+
+var reconciliationProviderIntents = map[string]entities.TransactionIntent{
+	"flutterwave": entities.FlutterwaveDebitLocal,
+	"chimoney": entities.ChimoneyDebitInternational,
+}
+
+// StartReconciliation records a run and matches the processor's records against our transactions in the background.
+// When records is nil they are pulled from the provider's API for the period.
+func StartReconciliation(ctx any, provider string, from time.Time, to time.Time, records *[]types.TransferRecord, fileName *string, initiatedBy string) *entities.ReconciliationRun {
+	if !from.Before(to) {
+		apperrors.ClientError(ctx, "from must be before to", nil)
+		return nil
+	}
+	if to.Sub(from) > constants.RECONCILIATION_MAX_PERIOD {
+		apperrors.ClientError(ctx, fmt.Sprintf("You cannot reconcile more than %d days at a time", int(constants.RECONCILIATION_MAX_PERIOD.Hours() / 24)), nil)
+		return nil
+	}
+	source := entities.ReconciliationSourceAPI
+	if records != nil {
+		source = entities.ReconciliationSourceUpload
+	}
+	payload := entities.ReconciliationRun{
+		Provider: provider,
+		Source: source,
+		FileName: fileName,
+		PeriodStart: from,
+		PeriodEnd: to,
+		Status: entities.ReconciliationRunning,
+		InitiatedBy: initiatedBy,
+	}
+	validationErr := validator.ValidatorInstance.ValidateStruct(payload)
+	if validationErr != nil {
+		apperrors.ValidationFailedError(ctx, validationErr)
+		return nil
+	}
+	run, err := repository.ReconciliationRunRepo().CreateOne(nil, payload)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	go runReconciliation(*run, records)
+	return run
+}
+
+func runReconciliation(run entities.ReconciliationRun, records *[]types.TransferRecord) {
+	items, summary, err := reconcileRun(&run, records)
+	update := map[string]any{
+		"completedAt": time.Now(),
+	}
+	if err == nil && len(items) != 0 {
+		_, err = repository.ReconciliationItemRepo().CreateBulkAndReturnPayload(items)
+	}
+	if err != nil {
+		logger.Error(errors.New("reconciliation run failed"), logger.LoggerOptions{
+			Key: "runID",
+			Data: run.ID,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		update["status"] = entities.ReconciliationFailed
+		update["error"] = err.Error()
+	} else {
+		update["status"] = entities.ReconciliationCompleted
+		update["summary"] = summary
+	}
+	repository.ReconciliationRunRepo().UpdatePartialByID(run.ID, update)
+}
+
+func reconcileRun(run *entities.ReconciliationRun, records *[]types.TransferRecord) ([]entities.ReconciliationItem, *entities.ReconciliationSummary, error) {
+	var err error
+	if records == nil {
+		records, err = fetchProcessorRecords(run.Provider, run.PeriodStart, run.PeriodEnd)
+		if err != nil {
+			return nil, nil, err
+		}
+		// payouts the processor has finished are settled first so they are not reported as status mismatches.
+		// Uploaded reports are only compared, they are not trusted to move funds.
+		settleTransactions(*records)
+	}
+	references := []string{}
+	for _, record := range *records {
+		references = append(references, record.Reference)
+	}
+	// transactions referenced by the processor are included even when they were recorded just outside the period
+	transactions, err := repository.TransactionRepo().FindMany(map[string]interface{}{
+		"$or": []map[string]any{{
+			"intent": reconciliationProviderIntents[run.Provider],
+			"createdAt": map[string]any{
+				"$gte": run.PeriodStart,
+				"$lte": run.PeriodEnd,
+			},
+		}, {
+			"transactionReference": map[string]any{
+				"$in": references,
+			},
+		}},
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, nil, err
+	}
+	items, summary := reconcile(run, *records, *transactions)
+	return items, summary, nil
+}
+
+// reconcile matches processor records to transactions by reference and returns an item for every discrepancy.
+func reconcile(run *entities.ReconciliationRun, records []types.TransferRecord, transactions []entities.Transaction) ([]entities.ReconciliationItem, *entities.ReconciliationSummary) {
+	summary := entities.ReconciliationSummary{
+		ProcessorRecords: len(records),
+		LedgerRecords: len(transactions),
+	}
+	items := []entities.ReconciliationItem{}
+	newItem := func(discrepancy entities.ReconciliationDiscrepancy, reference string) entities.ReconciliationItem {
+		return entities.ReconciliationItem{
+			RunID: run.ID,
+			Discrepancy: discrepancy,
+			Reference: reference,
+			TransactionIDs: []string{},
+			Occurrences: 1,
+			Resolution: entities.ReconciliationItemOpen,
+		}
+	}
+
+	ledger := map[string][]entities.Transaction{}
+	for _, transaction := range transactions {
+		ledger[transaction.TransactionReference] = append(ledger[transaction.TransactionReference], transaction)
+	}
+	processor := map[string][]types.TransferRecord{}
+	for _, record := range records {
+		processor[record.Reference] = append(processor[record.Reference], record)
+	}
+
+	for _, transaction := range transactions {
+		reference := transaction.TransactionReference
+		matches := ledger[reference]
+		if matches[0].ID != transaction.ID {
+			continue
+		}
+		if len(matches) > 1 {
+			item := newItem(entities.DuplicateRecord, reference)
+			item.Occurrences = len(matches)
+			for _, match := range matches {
+				item.TransactionIDs = append(item.TransactionIDs, match.ID)
+			}
+			items = append(items, item)
+			summary.Duplicates++
+		}
+		if _, found := processor[reference]; !found {
+			item := newItem(entities.MissingAtProcessor, reference)
+			item.TransactionIDs = []string{transaction.ID}
+			amount := transaction.Amount
+			item.LedgerAmount = &amount
+			status := ledgerStatus(transaction)
+			item.LedgerStatus = &status
+			items = append(items, item)
+			summary.MissingAtProcessor++
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, record := range records {
+		reference := record.Reference
+		if seen[reference] {
+			continue
+		}
+		seen[reference] = true
+		recordsForReference := processor[reference]
+		if len(recordsForReference) > 1 {
+			item := newItem(entities.DuplicateRecord, reference)
+			item.Occurrences = len(recordsForReference)
+			item.ProcessorStatus = utils.GetStringPointer(record.Status)
+			items = append(items, item)
+			summary.Duplicates++
+		}
+		matches, found := ledger[reference]
+		if !found {
+			item := newItem(entities.MissingInLedger, reference)
+			item.ProcessorStatus = utils.GetStringPointer(record.Status)
+			if amount, err := money.ParseDecimal(record.Amount, record.Currency); err == nil {
+				item.ProcessorAmount = &amount
+			}
+			items = append(items, item)
+			summary.MissingInLedger++
+			continue
+		}
+		transaction := matches[0]
+		matched := true
+		ledgerAmount := transaction.Amount
+		if transaction.AmountInUSD != nil && strings.EqualFold(record.Currency, "USD") {
+			ledgerAmount = *transaction.AmountInUSD
+		}
+		processorAmount, err := money.ParseDecimal(record.Amount, record.Currency)
+		if err != nil || processorAmount != ledgerAmount {
+			item := newItem(entities.AmountMismatch, reference)
+			item.TransactionIDs = []string{transaction.ID}
+			item.LedgerAmount = &ledgerAmount
+			if err == nil {
+				item.ProcessorAmount = &processorAmount
+			}
+			items = append(items, item)
+			summary.AmountMismatches++
+			matched = false
+		}
+		processorStatus := normaliseProcessorStatus(record.Status)
+		if status := ledgerStatus(transaction); processorStatus != entities.TransactionPending && processorStatus != status {
+			item := newItem(entities.StatusMismatch, reference)
+			item.TransactionIDs = []string{transaction.ID}
+			item.ProcessorStatus = utils.GetStringPointer(record.Status)
+			item.LedgerStatus = &status
+			items = append(items, item)
+			summary.StatusMismatches++
+			matched = false
+		}
+		if matched {
+			summary.Matched++
+		}
+	}
+	return items, &summary
+}
+
+func ledgerStatus(transaction entities.Transaction) entities.TransactionStatus {
+	if transaction.Status == "" {
+		return entities.TransactionPending
+	}
+	return transaction.Status
+}
+
+// Maps the status names used by Flutterwave and Chimoney onto ours.
+func normaliseProcessorStatus(status string) entities.TransactionStatus {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "successful", "success", "paid", "completed":
+		return entities.TransactionSuccessful
+	case "failed", "expired", "cancelled", "reversed":
+		return entities.TransactionFailed
+	}
+	return entities.TransactionPending
+}
+
+// ParseReconciliationFile reads a settlement report exported from a processor.
+// The file must be a CSV with reference, amount, currency and status columns. A created_at column in RFC3339 is optional.
+func ParseReconciliationFile(ctx any, file io.Reader) *[]types.TransferRecord {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		apperrors.ClientError(ctx, "The file uploaded is not a valid CSV file", nil)
+		return nil
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"reference", "amount", "currency", "status"} {
+		if _, ok := columns[column]; !ok {
+			apperrors.ClientError(ctx, fmt.Sprintf("The file uploaded does not have a %s column", column), nil)
+			return nil
+		}
+	}
+	records := []types.TransferRecord{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			apperrors.ClientError(ctx, fmt.Sprintf("Line %d of the file could not be read", line), nil)
+			return nil
+		}
+		record := types.TransferRecord{
+			Reference: strings.TrimSpace(row[columns["reference"]]),
+			Amount: strings.TrimSpace(row[columns["amount"]]),
+			Currency: strings.ToUpper(strings.TrimSpace(row[columns["currency"]])),
+			Status: strings.TrimSpace(row[columns["status"]]),
+		}
+		if record.Reference == "" {
+			apperrors.ClientError(ctx, fmt.Sprintf("Line %d of the file has no reference", line), nil)
+			return nil
+		}
+		if _, err := money.ParseDecimal(record.Amount, record.Currency); err != nil {
+			apperrors.ClientError(ctx, fmt.Sprintf("Line %d of the file has an invalid amount or currency", line), nil)
+			return nil
+		}
+		if index, ok := columns["created_at"]; ok && row[index] != "" {
+			record.CreatedAt, err = time.Parse(time.RFC3339, strings.TrimSpace(row[index]))
+			if err != nil {
+				apperrors.ClientError(ctx, fmt.Sprintf("Line %d of the file has a created_at that is not in the RFC3339 format", line), nil)
+				return nil
+			}
+		}
+		records = append(records, record)
+	}
+	return &records
+}
+
+// ResolveReconciliationItem records how ops dealt with a discrepancy.
+func ResolveReconciliationItem(ctx any, itemID string, resolution entities.ReconciliationResolution, note string, resolvedBy string) *entities.ReconciliationItem {
+	if resolution != entities.ReconciliationItemResolved && resolution != entities.ReconciliationItemIgnored {
+		apperrors.ClientError(ctx, "resolution must be resolved or ignored", nil)
+		return nil
+	}
+	if strings.TrimSpace(note) == "" {
+		apperrors.ClientError(ctx, "Add a note explaining how this item was resolved", nil)
+		return nil
+	}
+	itemRepository := repository.ReconciliationItemRepo()
+	affected, err := itemRepository.UpdatePartialByID(itemID, map[string]any{
+		"resolution": resolution,
+		"resolutionNote": note,
+		"resolvedBy": resolvedBy,
+		"resolvedAt": time.Now(),
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if affected == 0 {
+		apperrors.NotFoundError(ctx, "reconciliation item not found")
+		return nil
+	}
+	item, err := itemRepository.FindByID(itemID)
+	if err != nil || item == nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return item
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+)
+
+// FetchActiveSessions lists the devices the user is signed in on, most recently used first.
+func FetchActiveSessions(ctx any, userID string) *[]entities.Session {
+	sessions, err := repository.SessionRepo().FindMany(activeSessionsFilter(userID), options.Find().SetSort(bson.D{{Key: "lastUsedAt", Value: -1}}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return sessions
+}
+
+// RevokeSession signs the user out of one of their sessions.
+func RevokeSession(ctx any, userID string, sessionID string) error {
+	filter := activeSessionsFilter(userID)
+	filter["_id"] = sessionID
+	session, err := repository.SessionRepo().FindOneByFilter(filter, options.FindOne().SetProjection(map[string]any{
+		"_id": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if session == nil {
+		apperrors.NotFoundError(ctx, "session not found")
+		return errors.New("session not found")
+	}
+	auth.SignOutUser(ctx, session.ID, "revoked by user")
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session making the request.
+func RevokeOtherSessions(ctx any, userID string, currentSessionID string) (*int, error) {
+	filter := activeSessionsFilter(userID)
+	filter["_id"] = map[string]any{
+		"$ne": currentSessionID,
+	}
+	sessions, err := repository.SessionRepo().FindMany(filter, options.Find().SetProjection(map[string]any{
+		"_id": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, err
+	}
+	for _, session := range *sessions {
+		auth.SignOutUser(ctx, session.ID, "revoked by user from another session")
+	}
+	revoked := len(*sessions)
+	return &revoked, nil
+}
+
+func activeSessionsFilter(userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"userID": userID,
+		"revokedAt": nil,
+		"expiresAt": map[string]any{
+			"$gt": time.Now(),
+		},
+	}
+}
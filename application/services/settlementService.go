@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+	paymentprocessor "kego.com/infrastructure/payment_processor"
+	"kego.com/infrastructure/payment_processor/types"
+)
+
+// StartTransactionSettlementWorker checks payouts that are still pending with their processor, for when
+// the processor's callback never arrives.
+func StartTransactionSettlementWorker() {
+	go func() {
+		ticker := time.NewTicker(constants.TRANSACTION_SETTLEMENT_POLL_INTERVAL)
+		defer ticker.Stop()
+		for range ticker.C {
+			for provider := range reconciliationProviderIntents {
+				settlePendingTransactions(provider)
+			}
+		}
+	}()
+}
+
+// SettleTransaction records the final status the processor gave a payout. Only pending payouts are updated,
+// so a callback and the settlement worker reporting the same payout cannot both move its funds.
+// The funds held for a successful payout are released and those held for a failed one are returned to the wallet.
+func SettleTransaction(reference string, status entities.TransactionStatus) error {
+	if status == entities.TransactionPending {
+		return nil
+	}
+	trxRepository := repository.TransactionRepo()
+	transaction, err := trxRepository.FindOneByFilter(map[string]interface{}{
+		"transactionReference": reference,
+		"status": entities.TransactionPending,
+	})
+	if err != nil {
+		return err
+	}
+	if transaction == nil {
+		// not ours or already settled
+		return nil
+	}
+	now := time.Now()
+	affected, err := trxRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": transaction.ID,
+		"status": entities.TransactionPending,
+	}, map[string]any{
+		"$set": map[string]any{
+			"status": status,
+			"settledAt": now,
+			"updatedAt": now,
+		},
+	})
+	if err != nil || affected == 0 {
+		return err
+	}
+	logger.Info("transaction settled", logger.LoggerOptions{
+		Key: "transactionReference",
+		Data: reference,
+	}, logger.LoggerOptions{
+		Key: "status",
+		Data: status,
+	})
+	if transaction.LockedFundsID == nil {
+		return nil
+	}
+	if status == entities.TransactionSuccessful {
+		return ReleaseLockedFunds(transaction.WalletID, *transaction.LockedFundsID)
+	}
+	return UnlockFunds(transaction.WalletID, entities.LockedFunds{
+		LockedFundsID: *transaction.LockedFundsID,
+		Amount: transaction.AmountInNGN,
+	})
+}
+
+// settleTransactions applies the statuses in records fetched from a processor.
+func settleTransactions(records []types.TransferRecord) {
+	for _, record := range records {
+		err := SettleTransaction(record.Reference, normaliseProcessorStatus(record.Status))
+		if err != nil {
+			logger.Error(errors.New("could not settle transaction"), logger.LoggerOptions{
+				Key: "transactionReference",
+				Data: record.Reference,
+			}, logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			})
+		}
+	}
+}
+
+func settlePendingTransactions(provider string) {
+	now := time.Now()
+	oldest, err := repository.TransactionRepo().FindOneByFilter(map[string]interface{}{
+		"intent": reconciliationProviderIntents[provider],
+		"status": entities.TransactionPending,
+		"createdAt": map[string]any{
+			"$gte": now.Add(-constants.TRANSACTION_SETTLEMENT_LOOKBACK),
+		},
+	}, options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil || oldest == nil {
+		return
+	}
+	records, err := fetchProcessorRecords(provider, oldest.CreatedAt.Add(-time.Minute), now)
+	if err != nil {
+		return
+	}
+	settleTransactions(*records)
+}
+
+func fetchProcessorRecords(provider string, from time.Time, to time.Time) (*[]types.TransferRecord, error) {
+	switch provider {
+	case "flutterwave":
+		return paymentprocessor.LocalPaymentProcessor.FetchTransfers(from, to)
+	case "chimoney":
+		return paymentprocessor.InternationalPaymentProcessor.FetchPayouts(from, to)
+	}
+	return nil, errors.New("unknown payment provider")
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// RecordPayout stores the transaction and the payment sent event together,
+// so the user is only notified about payouts that were recorded.
+func RecordPayout(ctx any, transaction entities.Transaction) *entities.Transaction {
+	trxRepository := repository.TransactionRepo()
+	var trx *entities.Transaction
+	err := trxRepository.StartTransaction(func(sc mongo.Session, c context.Context) error {
+		created, e := trxRepository.CreateOne(c, transaction)
+		if e != nil {
+			(sc).AbortTransaction(c)
+			return e
+		}
+		e = events.Publish(c, events.PaymentSentPayload{
+			TransactionID: created.ID,
+			UserID: created.UserID,
+			BusinessID: created.BusinessID,
+			Email: created.Sender.Email,
+			FirstName: created.Sender.FirstName,
+			Amount: created.Amount,
+			RecipientName: created.Recepient.Name,
+			RecipientCountry: created.Recepient.Country,
+		})
+		if e != nil {
+			(sc).AbortTransaction(c)
+			return e
+		}
+		trx = created
+		return (sc).CommitTransaction(c)
+	})
+	if err != nil {
+		logger.Error(errors.New("could not record payout"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "transactionReference",
+			Data: transaction.TransactionReference,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return trx
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/events"
+	"kego.com/application/money"
+	"kego.com/application/repository"
+	"kego.com/application/services/types"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+	"kego.com/infrastructure/cryptography"
+	"kego.com/infrastructure/logger"
+)
+
+var totpCodeRegex = regexp.MustCompile(`^[0-9]{6}$`)
+
+func FetchTwoFactorStatus(ctx any, userID string) map[string]any {
+	twoFactor, err := repository.TwoFactorRepo().FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return map[string]any{
+			"enabled": false,
+			"recoveryCodesLeft": 0,
+			"payoutThreshold": nil,
+		}
+	}
+	return map[string]any{
+		"enabled": true,
+		"recoveryCodesLeft": len(twoFactor.RecoveryCodes),
+		"payoutThreshold": twoFactor.PayoutThreshold,
+	}
+}
+
+// EnrollTwoFactor creates a new TOTP secret for the user to add to their authenticator app.
+// Two-factor authentication stays off until the user confirms it with a code from the app.
+func EnrollTwoFactor(ctx any, userID string, email string) *auth.TOTPEnrollment {
+	twoFactorRepository := repository.TwoFactorRepo()
+	twoFactor, err := twoFactorRepository.FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		apperrors.ClientError(ctx, "Two-factor authentication is already on", nil)
+		return nil
+	}
+	enrollment, err := auth.GenerateTOTP(email)
+	if err != nil {
+		logger.Error(errors.New("could not generate totp secret"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	secret, err := cryptography.DataEncrypter.Encrypt(enrollment.Secret)
+	if err != nil {
+		logger.Error(errors.New("could not encrypt totp secret"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if twoFactor == nil {
+		_, err = twoFactorRepository.CreateOne(nil, entities.TwoFactor{
+			UserID: userID,
+			Secret: secret,
+		})
+	} else {
+		_, err = twoFactorRepository.UpdatePartialByID(twoFactor.ID, map[string]any{
+			"secret": secret,
+			"lastUsedStep": 0,
+		})
+	}
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return enrollment
+}
+
+// ConfirmTwoFactor turns two-factor authentication on once the user proves their app is set up,
+// and returns recovery codes. The codes are only ever shown this once.
+func ConfirmTwoFactor(ctx any, userID string, code string) []string {
+	twoFactor, err := repository.TwoFactorRepo().FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if twoFactor == nil || twoFactor.Secret == "" {
+		apperrors.ClientError(ctx, "Set up two-factor authentication before confirming it", nil)
+		return nil
+	}
+	if twoFactor.Enabled {
+		apperrors.ClientError(ctx, "Two-factor authentication is already on", nil)
+		return nil
+	}
+	if !twoFactorAttemptAllowed(ctx, userID) {
+		return nil
+	}
+	step, ok := validateTOTP(twoFactor, code)
+	if !ok {
+		failTwoFactorAttempt(ctx, userID)
+		return nil
+	}
+	auth.TwoFactorGuard.Succeed(userID)
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	_, err = repository.TwoFactorRepo().UpdatePartialByID(twoFactor.ID, map[string]any{
+		"enabled": true,
+		"enabledAt": time.Now(),
+		"lastUsedStep": step,
+		"recoveryCodes": hashes,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return codes
+}
+
+// DisableTwoFactor turns two-factor authentication off. It needs the user's password and a current code.
+func DisableTwoFactor(ctx any, userID string, password string, code string) error {
+	account, err := repository.UserRepo().FindByID(userID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if account == nil {
+		apperrors.NotFoundError(ctx, "this account no longer exists")
+		return errors.New("account not found")
+	}
+	if !VerifyPin(ctx, account, password, &types.PinSelectionType{
+		Password: true,
+	}) {
+		return errors.New("wrong password")
+	}
+	if !requireTwoFactorCode(ctx, userID, code) {
+		return errors.New("wrong two-factor code")
+	}
+	_, err = repository.TwoFactorRepo().DeleteOne(nil, map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, for when they have used or lost them.
+func RegenerateRecoveryCodes(ctx any, userID string, code string) []string {
+	if !requireTwoFactorCode(ctx, userID, code) {
+		return nil
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	_, err = repository.TwoFactorRepo().UpdatePartialByFilter(map[string]interface{}{
+		"userID": userID,
+	}, map[string]any{
+		"recoveryCodes": hashes,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return codes
+}
+
+// SetPayoutTwoFactorThreshold makes payouts of the amount or more ask for a code. A nil amount turns it off.
+// Changing it needs a code so someone holding only the session cannot turn it off.
+func SetPayoutTwoFactorThreshold(ctx any, userID string, threshold *money.Money, code string) error {
+	if threshold != nil && (threshold.Currency != "NGN" || threshold.IsNegative()) {
+		apperrors.ClientError(ctx, "The payout threshold must be an amount in naira", nil)
+		return errors.New("invalid payout threshold")
+	}
+	if !requireTwoFactorCode(ctx, userID, code) {
+		return errors.New("wrong two-factor code")
+	}
+	_, err := repository.TwoFactorRepo().UpdatePartialByFilter(map[string]interface{}{
+		"userID": userID,
+	}, map[string]any{
+		"payoutThreshold": threshold,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	return nil
+}
+
+// What the user gave to confirm a payout.
+type PayoutConfirmation struct {
+	TOTPCode 	*string
+	OTP 		*string
+	IPAddress 	string
+}
+
+// VerifyPayoutConfirmation asks for a two-factor code when the payout is at or above the user's threshold.
+// Users without two-factor are sent an otp instead for payouts of PAYOUT_CONFIRMATION_OTP_THRESHOLD_KOBO or more.
+func VerifyPayoutConfirmation(ctx any, userID string, amountInNGN money.Money, confirmation PayoutConfirmation) bool {
+	twoFactor, err := repository.TwoFactorRepo().FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return verifyPayoutOTP(ctx, userID, amountInNGN, confirmation)
+	}
+	if twoFactor.PayoutThreshold == nil {
+		return true
+	}
+	below, err := amountInNGN.LessThan(*twoFactor.PayoutThreshold)
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return false
+	}
+	if below {
+		return true
+	}
+	code := confirmation.TOTPCode
+	if code == nil || *code == "" {
+		apperrors.TwoFactorRequired(ctx, "Enter the code from your authenticator app to send this payment")
+		return false
+	}
+	return requireTwoFactorCode(ctx, userID, *code)
+}
+
+// verifyPayoutOTP sends the user an otp for a large payout and checks it when they retry with it.
+// The otp is saved against the amount so it cannot be used to confirm a different payout.
+func verifyPayoutOTP(ctx any, userID string, amountInNGN money.Money, confirmation PayoutConfirmation) bool {
+	below, err := amountInNGN.LessThan(money.NGN(constants.PAYOUT_CONFIRMATION_OTP_THRESHOLD_KOBO))
+	if err != nil {
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return false
+	}
+	if below {
+		return true
+	}
+	subject := fmt.Sprintf("%s-%d%s", userID, amountInNGN.Amount, amountInNGN.Currency)
+	if confirmation.OTP == nil || *confirmation.OTP == "" {
+		account, err := repository.UserRepo().FindByID(userID)
+		if err != nil || account == nil {
+			apperrors.FatalServerError(ctx)
+			return false
+		}
+		if SendAccountOTP(ctx, account, auth.OTPPayoutConfirmation, subject, "Confirm your payment") != nil {
+			return false
+		}
+		apperrors.OTPRequired(ctx, "Enter the code we sent you to send this payment")
+		return false
+	}
+	msg, success, blocked := auth.VerifyOTP(auth.OTPPayoutConfirmation, subject, *confirmation.OTP, confirmation.IPAddress)
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx, msg, blocked.RetryAfter, blocked.Locked)
+		return false
+	}
+	if !success {
+		apperrors.ClientError(ctx, msg, nil)
+		return false
+	}
+	return true
+}
+
+// VerifyTwoFactorCode checks a code from the user's authenticator app or one of their recovery codes.
+// Each code can only be used once.
+func VerifyTwoFactorCode(userID string, code string) (bool, error) {
+	twoFactorRepository := repository.TwoFactorRepo()
+	twoFactor, err := twoFactorRepository.FindOneByFilter(map[string]interface{}{
+		"userID": userID,
+		"enabled": true,
+	})
+	if err != nil {
+		return false, err
+	}
+	if twoFactor == nil {
+		return false, nil
+	}
+	if totpCodeRegex.MatchString(code) {
+		step, ok := validateTOTP(twoFactor, code)
+		if !ok {
+			return false, nil
+		}
+		// the filter makes sure two requests with the same code cannot both be accepted
+		affected, err := twoFactorRepository.UpdateManyWithOperator(map[string]interface{}{
+			"_id": twoFactor.ID,
+			"lastUsedStep": map[string]any{
+				"$lt": step,
+			},
+		}, map[string]any{
+			"$set": map[string]any{
+				"lastUsedStep": step,
+			},
+		})
+		return err == nil && affected == 1, err
+	}
+	code = auth.NormaliseRecoveryCode(code)
+	for _, hash := range twoFactor.RecoveryCodes {
+		if !cryptography.CryptoHahser.VerifyData(hash, code) {
+			continue
+		}
+		affected, err := twoFactorRepository.UpdateManyWithOperator(map[string]interface{}{
+			"_id": twoFactor.ID,
+		}, map[string]any{
+			"$pull": map[string]any{
+				"recoveryCodes": hash,
+			},
+		})
+		return err == nil && affected == 1, err
+	}
+	return false, nil
+}
+
+// requireTwoFactorCode checks a code the signed in user entered. Wrong codes are counted by TwoFactorGuard
+// so a stolen session cannot be used to guess codes.
+func requireTwoFactorCode(ctx any, userID string, code string) bool {
+	if !twoFactorAttemptAllowed(ctx, userID) {
+		return false
+	}
+	ok, err := VerifyTwoFactorCode(userID, code)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	if !ok {
+		failTwoFactorAttempt(ctx, userID)
+		return false
+	}
+	auth.TwoFactorGuard.Succeed(userID)
+	return true
+}
+
+func twoFactorAttemptAllowed(ctx any, userID string) bool {
+	if status := auth.TwoFactorGuard.Check(userID, ""); status != nil {
+		apperrors.TooManyAttempts(ctx, status.Message(), status.RetryAfter, status.Locked)
+		return false
+	}
+	return true
+}
+
+// failTwoFactorAttempt records a wrong code and tells the user when it gets them locked out.
+func failTwoFactorAttempt(ctx any, userID string) {
+	status := auth.TwoFactorGuard.Fail(userID, "")
+	if status.AccountJustLocked {
+		notifyTwoFactorLocked(userID)
+	}
+	if status.Locked || status.RetryAfter != 0 {
+		apperrors.TooManyAttempts(ctx, status.Message(), status.RetryAfter, status.Locked)
+		return
+	}
+	apperrors.AuthenticationError(ctx, "wrong two-factor code")
+}
+
+func notifyTwoFactorLocked(userID string) {
+	account, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"email": 1,
+		"firstName": 1,
+	}))
+	if err == nil && account != nil {
+		err = events.Publish(nil, events.SecurityAlertPayload{
+			UserID: account.ID,
+			Email: account.Email,
+			FirstName: account.FirstName,
+			Title: "Two-factor codes paused after wrong attempts",
+			Body: "Someone signed in to your Kego account entered the wrong two-factor code several times, so codes will not be accepted for a while. If this was not you, change your password and sign out of your other devices.",
+		})
+	}
+	if err != nil {
+		logger.Error(errors.New("could not publish two-factor locked alert"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "userID",
+			Data: userID,
+		})
+	}
+}
+
+func validateTOTP(twoFactor *entities.TwoFactor, code string) (int64, bool) {
+	secret, err := cryptography.DataEncrypter.Decrypt(twoFactor.Secret)
+	if err != nil {
+		logger.Error(errors.New("could not decrypt totp secret"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		return 0, false
+	}
+	return auth.ValidateTOTP(secret, code, twoFactor.LastUsedStep)
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(constants.TWO_FACTOR_RECOVERY_CODES)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := []string{}
+	for _, code := range codes {
+		hash, err := cryptography.CryptoHahser.HashString(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, string(hash))
+	}
+	return codes, hashes, nil
+}
@@ -6,8 +6,10 @@ import (
 	"strconv"
 	"time"
 
+	"go.mongodb.org/mongo-driver/mongo/options"
 	apperrors "kego.com/application/appErrors"
 	"kego.com/application/constants"
+	"kego.com/application/money"
 	"kego.com/application/repository"
 	wallet_constants "kego.com/application/services/constants"
 	"kego.com/application/utils"
@@ -171,21 +173,44 @@ func verifyTransactionPinByUserID(ctx any, userID string, pin string) (bool, err
 	return pinMatch, nil
 }
 
-func verifyWalletBalance(ctx any, wallet *entities.Wallet, amount uint64) (bool, error) {
+// VerifyTransferAmountLimits checks a payout's naira value against the minimum and maximum a single transfer can move.
+func VerifyTransferAmountLimits(ctx any, amountInNGN money.Money) bool {
+	if amountInNGN.Currency != "NGN" {
+		apperrors.ClientError(ctx, "Transfer limits can only be checked in naira", nil)
+		return false
+	}
+	if amountInNGN.Amount < constants.MIN_TRANSFER_AMOUNT_KOBO {
+		apperrors.ClientError(ctx, fmt.Sprintf("You cannot send less than %s", money.NGN(constants.MIN_TRANSFER_AMOUNT_KOBO).Format()), nil)
+		return false
+	}
+	if amountInNGN.Amount >= constants.MAX_TRANSFER_AMOUNT_KOBO {
+		apperrors.ClientError(ctx, fmt.Sprintf("You cannot send more than %s at a time", money.NGN(constants.MAX_TRANSFER_AMOUNT_KOBO).Format()), nil)
+		return false
+	}
+	return true
+}
+
+func verifyWalletBalance(ctx any, wallet *entities.Wallet, amount money.Money) (bool, error) {
 	if wallet.Frozen {
 		err := fmt.Errorf("This wallet has been frozen and cannot carry out any transaction at the moment. Please contact support on %s to help resolve this issue.", constants.SUPPORT_EMAIL)
 		apperrors.AuthenticationError(ctx, err.Error())
 		return false, err
 	}
-	if wallet.Balance < amount {
-		err := fmt.Errorf("Insufficient funds. Credit your account with at least %s%v to complete this transaction.", wallet.Currency, utils.UInt64ToFloat32Currency(amount))
+	insufficient, err := wallet.Balance.LessThan(amount)
+	if err != nil {
+		err = fmt.Errorf("This wallet holds %s and cannot pay out %s", wallet.Balance.Currency, amount.Currency)
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return false, err
+	}
+	if insufficient {
+		err := fmt.Errorf("Insufficient funds. Credit your account with at least %s to complete this transaction.", amount.Format())
 		apperrors.ClientError(ctx, err.Error(), nil)
 		return false, err
 	}
 	return true, nil
 }
 
-func InitiatePreAuth(ctx any, businessID string, userID string, amount uint64, pin string) (*entities.Wallet, error) {
+func InitiatePreAuth(ctx any, businessID string, userID string, amount money.Money, pin string) (*entities.Wallet, error) {
 	wallet, err := GetWalletByBusinessID(ctx, businessID, userID)
 	if err != nil {
 		return nil, err
@@ -194,32 +219,81 @@ func InitiatePreAuth(ctx any, businessID string, userID string, amount uint64, p
 	if err != nil  || !success{
 		return nil, err
 	}
-	success, err = verifyWalletBalance(ctx, wallet, amount)
+	user, err := repository.UserRepo().FindByID(userID, options.FindOne().SetProjection(map[string]any{
+		"transactionPinResetAt": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, err
+	}
+	err = verifyTransactionPinCoolingOff(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return authorizeWalletDebit(ctx, wallet, amount)
+}
+
+// InitiateAPIKeyPreAuth is InitiatePreAuth for payouts made with a business API key, which have no pin to check.
+func InitiateAPIKeyPreAuth(ctx any, businessID string, amount money.Money) (*entities.Wallet, error) {
+	wallet, err := GetWalletByBusinessID(ctx, businessID, "")
+	if err != nil {
+		return nil, err
+	}
+	return authorizeWalletDebit(ctx, wallet, amount)
+}
+
+func authorizeWalletDebit(ctx any, wallet *entities.Wallet, amount money.Money) (*entities.Wallet, error) {
+	success, err := verifyWalletBalance(ctx, wallet, amount)
 	if err != nil  || !success {
 		return nil, err
 	}
+	if wallet.BusinessID != nil {
+		err = verifyBusinessPayoutLimits(ctx, *wallet.BusinessID, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return wallet, nil
 }
 
-func LockFunds(ctx any, wallet *entities.Wallet, amount uint64, intent entities.TransactionIntent) error {
+// LockFunds moves the amount out of the wallet's available balance. The balance is checked again
+// in the update so two payouts racing each other cannot overdraw the wallet.
+func LockFunds(ctx any, wallet *entities.Wallet, amount money.Money, intent entities.TransactionIntent) (*entities.LockedFunds, error) {
 	lockedFundsLog := entities.LockedFunds{
 		LockedFundsID: utils.GenerateUUIDString(),
 		Amount: amount,
 		LockedAt: time.Now(),
 		Reason: intent,
 	}
+	if wallet.BusinessID != nil {
+		if err := reserveBusinessPayoutAllowance(ctx, *wallet.BusinessID, amount, lockedFundsLog.LockedAt); err != nil {
+			return nil, err
+		}
+	}
 	walletRepository := repository.WalletRepo()
 	affected, err := walletRepository.UpdateManyWithOperator(map[string]interface{}{
 		"_id": wallet.ID,
+		"balance.currency": amount.Currency,
+		"balance.amount": map[string]any{
+			"$gte": amount.Amount,
+		},
 	}, map[string]any{
 		"$push": map[string]any{
 			"lockedFundsLog": lockedFundsLog,
 		},
 		"$inc": map[string]any {
-			"balance": int64(-amount),
+			"balance.amount": -amount.Amount,
 		},
 	})
-	if err != nil || affected == 0 {
+	if (err != nil || affected == 0) && wallet.BusinessID != nil {
+		releaseBusinessPayoutAllowance(*wallet.BusinessID, amount, lockedFundsLog.LockedAt)
+	}
+	if err == nil && affected == 0 {
+		err = fmt.Errorf("Insufficient funds. Credit your account with at least %s to complete this transaction.", amount.Format())
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil, err
+	}
+	if err != nil {
 		logger.Error(errors.New("could not lock funds"), logger.LoggerOptions{
 			Key: "intent",
 			Data: intent,
@@ -231,7 +305,137 @@ func LockFunds(ctx any, wallet *entities.Wallet, amount uint64, intent entities.
 			Data: err,
 		})
 		apperrors.UnknownError(ctx)
-		return err
+		return nil, err
+	}
+	return &lockedFundsLog, nil
+}
+
+// UnlockFunds returns locked funds to the wallet's available balance, e.g. when a payout is cancelled before it is sent.
+// The lock is removed in the same update so the funds cannot be returned twice. The payout no longer counts
+// against its business's daily limit.
+func UnlockFunds(walletID string, lockedFunds entities.LockedFunds) error {
+	businessID, lock := findLock(walletID, lockedFunds.LockedFundsID)
+	affected, err := repository.WalletRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": walletID,
+		"lockedFundsLog.lockedFundsID": lockedFunds.LockedFundsID,
+	}, map[string]any{
+		"$pull": map[string]any{
+			"lockedFundsLog": map[string]any{
+				"lockedFundsID": lockedFunds.LockedFundsID,
+			},
+		},
+		"$inc": map[string]any {
+			"balance.amount": lockedFunds.Amount.Amount,
+		},
+	})
+	if err == nil && affected == 0 {
+		err = errors.New("locked funds not found")
+	}
+	if err == nil && businessID != nil && lock != nil {
+		releaseBusinessPayoutAllowance(*businessID, lockedFunds.Amount, lock.LockedAt)
+	}
+	if err != nil {
+		logger.Error(errors.New("could not unlock funds"), logger.LoggerOptions{
+			Key: "walletID",
+			Data: walletID,
+		}, logger.LoggerOptions{
+			Key: "lockedFunds",
+			Data: lockedFunds,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	}
+	return err
+}
+
+// ReturnLockedFunds gives part of a lock back to the wallet's available balance, e.g. when a payout turns out to cost
+// less than was locked for it.
+func ReturnLockedFunds(walletID string, lockedFundsID string, amount money.Money) error {
+	businessID, lock := findLock(walletID, lockedFundsID)
+	affected, err := repository.WalletRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": walletID,
+		"lockedFundsLog": map[string]any{
+			"$elemMatch": map[string]any{
+				"lockedFundsID": lockedFundsID,
+				"amount.currency": amount.Currency,
+				"amount.amount": map[string]any{
+					"$gte": amount.Amount,
+				},
+			},
+		},
+	}, map[string]any{
+		"$inc": map[string]any {
+			"balance.amount": amount.Amount,
+			"lockedFundsLog.$.amount.amount": -amount.Amount,
+		},
+	})
+	if err == nil && affected == 0 {
+		err = errors.New("locked funds not found")
+	}
+	if err == nil && businessID != nil && lock != nil {
+		releaseBusinessPayoutAllowance(*businessID, amount, lock.LockedAt)
+	}
+	if err != nil {
+		logger.Error(errors.New("could not return locked funds"), logger.LoggerOptions{
+			Key: "walletID",
+			Data: walletID,
+		}, logger.LoggerOptions{
+			Key: "lockedFundsID",
+			Data: lockedFundsID,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+	}
+	return err
+}
+
+// findLock returns the business a wallet belongs to and one of its locks, so the day the lock counted against
+// the business's payout limit is known when its funds are returned.
+func findLock(walletID string, lockedFundsID string) (*string, *entities.LockedFunds) {
+	wallet, err := repository.WalletRepo().FindByID(walletID, options.FindOne().SetProjection(map[string]any{
+		"businessID": 1,
+		"lockedFundsLog": 1,
+	}))
+	if err != nil || wallet == nil {
+		return nil, nil
+	}
+	for _, lock := range wallet.LockedFundsLog {
+		if lock.LockedFundsID == lockedFundsID {
+			return wallet.BusinessID, &lock
+		}
+	}
+	return wallet.BusinessID, nil
+}
+
+// ReleaseLockedFunds removes a lock once the payout it was held for has gone out. The funds already left the
+// available balance when they were locked, so only the lock is removed.
+func ReleaseLockedFunds(walletID string, lockedFundsID string) error {
+	affected, err := repository.WalletRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": walletID,
+		"lockedFundsLog.lockedFundsID": lockedFundsID,
+	}, map[string]any{
+		"$pull": map[string]any{
+			"lockedFundsLog": map[string]any{
+				"lockedFundsID": lockedFundsID,
+			},
+		},
+	})
+	if err == nil && affected == 0 {
+		err = errors.New("locked funds not found")
+	}
+	if err != nil {
+		logger.Error(errors.New("could not release locked funds"), logger.LoggerOptions{
+			Key: "walletID",
+			Data: walletID,
+		}, logger.LoggerOptions{
+			Key: "lockedFundsID",
+			Data: lockedFundsID,
+		}, logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
 	}
-	return nil
+	return err
 }
\ No newline at end of file
@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	apperrors "kego.com/application/appErrors"
+	"kego.com/application/money"
 	"kego.com/application/repository"
 	walletUsecases "kego.com/application/usecases/wallet"
 	"kego.com/entities"
@@ -40,6 +41,12 @@ func CreateAccount(ctx any, payload *entities.User)(*entities.User, *entities.Wa
 		return nil, nil, err
 	}
 	payload.Password = string(passwordHash)
+	pinHash, err := cryptography.CryptoHahser.HashString(payload.TransactionPin)
+	if err != nil {
+		apperrors.ValidationFailedError(ctx, &[]error{err})
+		return nil, nil, err
+	}
+	payload.TransactionPin = string(pinHash)
 	var user *entities.User
 	var wallet *entities.Wallet
 	userRepo.StartTransaction(func(sc mongo.Session, c context.Context) error {
@@ -47,8 +54,8 @@ func CreateAccount(ctx any, payload *entities.User)(*entities.User, *entities.Wa
 		walletPayload := &entities.Wallet{
 			UserID: userPayload.ID,
 			Frozen: false,
-			Balance: 0,
-			LedgerBalance: 0,
+			Balance: money.Zero("NGN"),
+			LedgerBalance: money.Zero("NGN"),
 			Currency: "NGN",
 			LockedFundsLog: []entities.LockedFunds{},
 		}
@@ -1,17 +1,38 @@
 package authusecases
 
 import (
-	"time"
+	"errors"
 
 	apperrors "kego.com/application/appErrors"
+	"kego.com/application/events"
 	"kego.com/application/repository"
+	"kego.com/application/services"
 	"kego.com/entities"
 	"kego.com/infrastructure/auth"
 	"kego.com/infrastructure/cryptography"
-	"kego.com/infrastructure/database/repository/cache"
+	"kego.com/infrastructure/logger"
 )
 
-func LoginAccount(ctx any, email *string, phone *string, password *string, appVersion string, userAgent string, deviceID string) (*entities.User, *string) {
+// The device a sign in is coming from.
+type LoginDevice struct {
+	AppVersion 	string 					 `json:"appVersion"`
+	UserAgent 	string 					 `json:"userAgent"`
+	DeviceID 	string 					 `json:"deviceID"`
+	IPAddress 	string 					 `json:"ipAddress"`
+	Location 	entities.SessionLocation `json:"location"`
+}
+
+type LoginResult struct {
+	Account 			*entities.User
+	AccessToken 		*string
+	RefreshToken 		*string
+	// set instead of the tokens when the user has to enter a two-factor code to finish signing in
+	TwoFactorChallenge 	*string
+	// set with the challenge when the code is an otp sent to the user because they are signing in on a new device
+	StepUp 				bool
+}
+
+func LoginAccount(ctx any, email *string, phone *string, password *string, device LoginDevice) *LoginResult {
 	userRepo := repository.UserRepo()
 	var account *entities.User
 	var err error
@@ -27,45 +48,132 @@ func LoginAccount(ctx any, email *string, phone *string, password *string, appVe
 	}
 	if err != nil {
 		apperrors.FatalServerError(ctx)
-		return nil, nil
+		return nil
+	}
+	accountID := ""
+	if account != nil {
+		accountID = account.ID
+	}
+	if status := auth.LoginGuard.Check(accountID, device.IPAddress); status != nil {
+		apperrors.TooManyAttempts(ctx, status.Message(), status.RetryAfter, status.Locked)
+		return nil
 	}
 	if account == nil {
+		// guessing which accounts exist counts against the IP address too
+		auth.LoginGuard.Fail("", device.IPAddress)
 		apperrors.NotFoundError(ctx, "this account does not exist")
-		return nil, nil
+		return nil
 	}
 	if !account.EmailVerified {
 		apperrors.ClientError(ctx, "verify your email to use it to login", nil)
-		return nil, nil
+		return nil
 	}
 	passwordMatch := cryptography.CryptoHahser.VerifyData(account.Password, *password)
 	if !passwordMatch {
-		apperrors.AuthenticationError(ctx, "wrong password")
-		return nil, nil
-	}
-	token, err := auth.GenerateAuthToken(auth.ClaimsData{
-		Email:     &account.Email,
-		Phone:     &account.Phone,
-		UserID:    account.ID,
-		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Local().Add(time.Minute * time.Duration(10)).Unix(), //lasts for 10 mins
-		UserAgent: account.UserAgent,
-		FirstName: account.FirstName,
-		LastName: account.LastName,
-		DeviceID:   account.DeviceID,
-		AppVersion: account.AppVersion,
+		failedLogin(ctx, account, device.IPAddress, "wrong password")
+		return nil
+	}
+	auth.LoginGuard.Succeed(account.ID)
+	twoFactor, err := repository.TwoFactorRepo().FindOneByFilter(map[string]interface{}{
+		"userID": account.ID,
+		"enabled": true,
 	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if twoFactor != nil {
+		challenge := createTwoFactorChallenge(ctx, account.ID, device, false)
+		if challenge == nil {
+			return nil
+		}
+		return &LoginResult{
+			TwoFactorChallenge: challenge,
+		}
+	}
+	known, err := knownDevice(account, device.DeviceID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if !known {
+		// without two-factor a password alone is not enough to sign in somewhere new
+		if services.SendAccountOTP(ctx, account, auth.OTPLoginStepUp, account.ID, "Confirm it is you signing in") != nil {
+			return nil
+		}
+		challenge := createTwoFactorChallenge(ctx, account.ID, device, true)
+		if challenge == nil {
+			return nil
+		}
+		return &LoginResult{
+			TwoFactorChallenge: challenge,
+			StepUp: true,
+		}
+	}
+	return completeLogin(ctx, account, device)
+}
+
+// knownDevice reports whether the user has signed in on the device before.
+func knownDevice(account *entities.User, deviceID string) (bool, error) {
+	if account.DeviceID == deviceID {
+		return true, nil
+	}
+	device, err := repository.DeviceRepo().FindOneByFilter(map[string]interface{}{
+		"userID": account.ID,
+		"deviceID": deviceID,
+	})
+	return device != nil, err
+}
+
+// completeLogin records the device on the account and starts a session on it.
+func completeLogin(ctx any, account *entities.User, device LoginDevice) *LoginResult {
 	var updateAccountPayload = map[string]any{}
-	if account.UserAgent != userAgent{
-		updateAccountPayload["userAgent"] = userAgent
-		account.UserAgent = userAgent
-	}
-	if appVersion != account.AppVersion {
-		updateAccountPayload["appVersion"] = appVersion
-		account.AppVersion = appVersion
-	}
-	updateAccountPayload["deviceID"] = deviceID
-	account.DeviceID = deviceID
-	userRepo.UpdatePartialByID(account.ID,updateAccountPayload)
-	cache.Cache.CreateEntry(account.ID, *token, time.Minute * time.Duration(10)) // cache authentication token for 10 mins
-	return account, token
-}
\ No newline at end of file
+	if account.UserAgent != device.UserAgent{
+		updateAccountPayload["userAgent"] = device.UserAgent
+		account.UserAgent = device.UserAgent
+	}
+	if device.AppVersion != account.AppVersion {
+		updateAccountPayload["appVersion"] = device.AppVersion
+		account.AppVersion = device.AppVersion
+	}
+	updateAccountPayload["deviceID"] = device.DeviceID
+	account.DeviceID = device.DeviceID
+	repository.UserRepo().UpdatePartialByID(account.ID,updateAccountPayload)
+	accessToken, refreshToken := StartSession(ctx, account, device.IPAddress, device.Location)
+	if accessToken == nil {
+		return nil
+	}
+	return &LoginResult{
+		Account: account,
+		AccessToken: accessToken,
+		RefreshToken: refreshToken,
+	}
+}
+
+// failedLogin records a wrong password or two-factor code and tells the user when their account gets locked because of it.
+func failedLogin(ctx any, account *entities.User, ipAddress string, msg string) {
+	status := auth.LoginGuard.Fail(account.ID, ipAddress)
+	if status.AccountJustLocked {
+		err := events.Publish(nil, events.SecurityAlertPayload{
+			UserID: account.ID,
+			Email: account.Email,
+			FirstName: account.FirstName,
+			Title: "We locked your account after failed sign in attempts",
+			Body: "Someone tried to sign in to your Kego account with the wrong details several times. Sign ins are paused for a while. You can unlock your account now with a one time code sent to your email.",
+		})
+		if err != nil {
+			logger.Error(errors.New("could not publish account locked alert"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "userID",
+				Data: account.ID,
+			})
+		}
+	}
+	if status.Locked || status.RetryAfter != 0 {
+		apperrors.TooManyAttempts(ctx, status.Message(), status.RetryAfter, status.Locked)
+		return
+	}
+	apperrors.AuthenticationError(ctx, msg)
+}
@@ -0,0 +1,185 @@
+package authusecases
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+	"kego.com/infrastructure/database/repository/cache"
+	"kego.com/infrastructure/logger"
+)
+
+// StartSession signs the account in on its current device and returns an access token and a refresh token.
+// A device has one session at a time, so a session the device already had is ended.
+func StartSession(ctx any, account *entities.User, ipAddress string, location entities.SessionLocation) (*string, *string) {
+	sessionRepository := repository.SessionRepo()
+	previous, err := sessionRepository.FindMany(map[string]interface{}{
+		"userID": account.ID,
+		"deviceID": account.DeviceID,
+		"revokedAt": nil,
+	}, options.Find().SetProjection(map[string]any{
+		"_id": 1,
+	}))
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	for _, session := range *previous {
+		auth.SignOutUser(ctx, session.ID, "signed in again on the same device")
+	}
+	session := entities.Session{
+		UserID: account.ID,
+		DeviceID: account.DeviceID,
+		UserAgent: account.UserAgent,
+		AppVersion: account.AppVersion,
+		IPAddress: ipAddress,
+		Location: location,
+		ExpiresAt: time.Now().Add(constants.REFRESH_TOKEN_TTL),
+		LastUsedAt: time.Now(),
+	}
+	// the id is part of the refresh token so it is set before the session is saved
+	session.ID = utils.GenerateUUIDString()
+	session.CreatedAt = time.Now()
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken(session.ID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	session.RefreshTokenHash = refreshTokenHash
+	created, err := sessionRepository.CreateOne(nil, session)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	accessToken, err := issueAccessToken(account, created)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	return accessToken, &refreshToken
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token.
+// Refresh tokens can only be used once. A used token being presented again means it has leaked,
+// so the whole session is ended and the user has to sign in again.
+func RefreshSession(ctx any, refreshToken string, deviceID string, userAgent string, appVersion string) (*string, *string) {
+	sessionID, refreshTokenHash, err := auth.ParseRefreshToken(refreshToken)
+	if err != nil {
+		apperrors.AuthenticationError(ctx, "invalid refresh token")
+		return nil, nil
+	}
+	sessionRepository := repository.SessionRepo()
+	session, err := sessionRepository.FindByID(sessionID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if session == nil || !session.Active() {
+		apperrors.AuthenticationError(ctx, "this session has expired")
+		return nil, nil
+	}
+	if session.DeviceID != deviceID {
+		logger.Warning("refresh token used from a device other than the one it was issued to", logger.LoggerOptions{
+			Key: "sessionID",
+			Data: session.ID,
+		})
+		auth.SignOutUser(ctx, session.ID, "refresh token used from another device")
+		apperrors.AuthenticationError(ctx, "this session has expired")
+		return nil, nil
+	}
+	if session.RefreshTokenHash != refreshTokenHash {
+		logger.Warning("refresh token reuse detected", logger.LoggerOptions{
+			Key: "sessionID",
+			Data: session.ID,
+		})
+		auth.SignOutUser(ctx, session.ID, "refresh token reused")
+		apperrors.AuthenticationError(ctx, "this session has expired")
+		return nil, nil
+	}
+	account, err := repository.UserRepo().FindByID(session.UserID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if account == nil || account.Deactivated {
+		auth.SignOutUser(ctx, session.ID, "account no longer active")
+		apperrors.AuthenticationError(ctx, "this session has expired")
+		return nil, nil
+	}
+	newRefreshToken, newRefreshTokenHash, err := auth.GenerateRefreshToken(session.ID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	session.UserAgent = userAgent
+	session.AppVersion = appVersion
+	session.ExpiresAt = time.Now().Add(constants.REFRESH_TOKEN_TTL)
+	session.LastUsedAt = time.Now()
+	// the filter on the old hash makes sure only one of two requests racing with the same token wins
+	rotated, err := sessionRepository.UpdateManyWithOperator(map[string]interface{}{
+		"_id": session.ID,
+		"refreshTokenHash": refreshTokenHash,
+		"revokedAt": nil,
+	}, map[string]any{
+		"$set": map[string]any{
+			"refreshTokenHash": newRefreshTokenHash,
+			"userAgent": session.UserAgent,
+			"appVersion": session.AppVersion,
+			"expiresAt": session.ExpiresAt,
+			"lastUsedAt": session.LastUsedAt,
+			"updatedAt": time.Now(),
+		},
+		"$inc": map[string]any{
+			"generation": 1,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	if rotated == 0 {
+		auth.SignOutUser(ctx, session.ID, "refresh token reused")
+		apperrors.AuthenticationError(ctx, "this session has expired")
+		return nil, nil
+	}
+	// the authentication middleware checks the token against the app version and user agent of the device
+	account.UserAgent = userAgent
+	account.AppVersion = appVersion
+	account.DeviceID = deviceID
+	accessToken, err := issueAccessToken(account, session)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil
+	}
+	return accessToken, &newRefreshToken
+}
+
+// issueAccessToken creates a short lived access token for the session and caches it, replacing the session's previous one.
+func issueAccessToken(account *entities.User, session *entities.Session) (*string, error) {
+	token, err := auth.GenerateAuthToken(auth.ClaimsData{
+		Email:     &account.Email,
+		Phone:     &account.Phone,
+		UserID:    account.ID,
+		SessionID: session.ID,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Local().Add(constants.ACCESS_TOKEN_TTL).Unix(),
+		UserAgent: account.UserAgent,
+		FirstName: account.FirstName,
+		LastName: account.LastName,
+		DeviceID:   account.DeviceID,
+		AppVersion: account.AppVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !cache.Cache.CreateEntry(auth.AccessTokenCacheKey(session.ID), *token, constants.ACCESS_TOKEN_TTL) {
+		return nil, errors.New("could not cache access token")
+	}
+	return token, nil
+}
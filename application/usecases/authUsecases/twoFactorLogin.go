@@ -0,0 +1,104 @@
+package authusecases
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/application/utils"
+	"kego.com/infrastructure/auth"
+	"kego.com/infrastructure/database/repository/cache"
+)
+
+// A sign in waiting for a two-factor code, or for an otp when the user is signing in on a new device without
+// two-factor. It is kept in the cache under a random token given to the client.
+// Wrong codes are counted separately so concurrent guesses cannot overwrite each other's count.
+type twoFactorChallenge struct {
+	UserID 	 string 	 `json:"userID"`
+	Device 	 LoginDevice `json:"device"`
+	StepUp 	 bool 		 `json:"stepUp"`
+}
+
+func twoFactorChallengeKey(token string) string {
+	return fmt.Sprintf("%s-2fa-challenge", token)
+}
+
+func twoFactorChallengeAttemptsKey(token string) string {
+	return fmt.Sprintf("%s-attempts", twoFactorChallengeKey(token))
+}
+
+func createTwoFactorChallenge(ctx any, userID string, device LoginDevice, stepUp bool) *string {
+	token := utils.GenerateUUIDString()
+	if !saveTwoFactorChallenge(token, &twoFactorChallenge{
+		UserID: userID,
+		Device: device,
+		StepUp: stepUp,
+	}) {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	return &token
+}
+
+func saveTwoFactorChallenge(token string, challenge *twoFactorChallenge) bool {
+	payload, err := json.Marshal(challenge)
+	if err != nil {
+		return false
+	}
+	return cache.Cache.CreateEntry(twoFactorChallengeKey(token), string(payload), constants.TWO_FACTOR_CHALLENGE_TTL)
+}
+
+// CompleteTwoFactorLogin finishes a sign in that was waiting for a two-factor code or step-up otp. verify checks
+// the code the user entered, stepUp says whether it should be checked as an otp. The challenge is dropped after too many wrong codes so they have to enter their password again.
+func CompleteTwoFactorLogin(ctx any, challengeToken string, device LoginDevice, verify func(userID string, stepUp bool) (bool, error)) *LoginResult {
+	cached := cache.Cache.FindOne(twoFactorChallengeKey(challengeToken))
+	if cached == nil {
+		apperrors.AuthenticationError(ctx, "this sign in has expired, please sign in again")
+		return nil
+	}
+	var challenge twoFactorChallenge
+	if err := json.Unmarshal([]byte(*cached), &challenge); err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if challenge.Device.DeviceID != device.DeviceID {
+		apperrors.AuthenticationError(ctx, "this sign in has expired, please sign in again")
+		return nil
+	}
+	if status := auth.LoginGuard.Check(challenge.UserID, device.IPAddress); status != nil {
+		apperrors.TooManyAttempts(ctx, status.Message(), status.RetryAfter, status.Locked)
+		return nil
+	}
+	ok, err := verify(challenge.UserID, challenge.StepUp)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	account, err := repository.UserRepo().FindByID(challenge.UserID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil
+	}
+	if account == nil || account.Deactivated {
+		cache.Cache.DeleteOne(twoFactorChallengeKey(challengeToken))
+		apperrors.AuthenticationError(ctx, "this account can no longer sign in")
+		return nil
+	}
+	if !ok {
+		attempts, err := cache.Cache.Increment(twoFactorChallengeAttemptsKey(challengeToken), constants.TWO_FACTOR_CHALLENGE_TTL)
+		if err != nil || attempts >= int64(constants.TWO_FACTOR_CHALLENGE_MAX_ATTEMPTS) {
+			cache.Cache.DeleteOne(twoFactorChallengeKey(challengeToken))
+			cache.Cache.DeleteOne(twoFactorChallengeAttemptsKey(challengeToken))
+			failedLogin(ctx, account, device.IPAddress, "too many wrong codes, please sign in again")
+			return nil
+		}
+		failedLogin(ctx, account, device.IPAddress, "wrong two-factor code")
+		return nil
+	}
+	cache.Cache.DeleteOne(twoFactorChallengeKey(challengeToken))
+	cache.Cache.DeleteOne(twoFactorChallengeAttemptsKey(challengeToken))
+	// the rest of the sign in uses the device the password was entered on
+	return completeLogin(ctx, account, challenge.Device)
+}
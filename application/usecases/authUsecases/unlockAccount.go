@@ -0,0 +1,74 @@
+package authusecases
+
+import (
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/events"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/auth"
+)
+
+// RequestAccountUnlock sends an OTP that lifts a lock put on the account after failed sign ins.
+// Nothing is sent for an email without an account but the caller is not told so.
+func RequestAccountUnlock(ctx any, email string) bool {
+	account, err := repository.UserRepo().FindOneByFilter(map[string]interface{}{
+		"email": email,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	if account == nil {
+		return true
+	}
+	otp, blocked, err := auth.GenerateOTP(auth.OTPAccountUnlock, account.ID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx, blocked.Message(), blocked.RetryAfter, false)
+		return false
+	}
+	err = events.Publish(nil, events.OTPRequestedPayload{
+		UserID: account.ID,
+		Email: account.Email,
+		FirstName: account.FirstName,
+		OTP: *otp,
+		Subject: "Unlock your account",
+		// whoever locked the account may have the user's phone so the code only goes to their email
+		Channels: []entities.NotificationChannel{entities.EmailChannel},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	return true
+}
+
+// UnlockAccount lifts the sign in lock on the account once the user gives the OTP sent to them.
+func UnlockAccount(ctx any, email string, otp string, ipAddress string) bool {
+	account, err := repository.UserRepo().FindOneByFilter(map[string]interface{}{
+		"email": email,
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return false
+	}
+	if account == nil {
+		auth.OTPGuard.Fail("", ipAddress)
+		apperrors.ClientError(ctx, "wrong otp provided", nil)
+		return false
+	}
+	msg, success, blocked := auth.VerifyOTP(auth.OTPAccountUnlock, account.ID, otp, ipAddress)
+	if blocked != nil {
+		apperrors.TooManyAttempts(ctx, msg, blocked.RetryAfter, blocked.Locked)
+		return false
+	}
+	if !success {
+		apperrors.ClientError(ctx, msg, nil)
+		return false
+	}
+	auth.LoginGuard.Unlock(account.ID)
+	return true
+}
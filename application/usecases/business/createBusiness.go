@@ -3,10 +3,13 @@ package business
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	apperrors "kego.com/application/appErrors"
+	"kego.com/application/constants"
+	"kego.com/application/money"
 	"kego.com/application/repository"
 	walletUsecases "kego.com/application/usecases/wallet"
 	"kego.com/entities"
@@ -21,9 +24,23 @@ func CreateBusiness(ctx any, payload *entities.Business) (*entities.Business, *e
 		return nil, nil, errors.New("")
 	}
 	businessRepo := repository.BusinessRepo()
+	unverified, err := businessRepo.CountDocs(map[string]interface{}{
+		"userID": payload.UserID,
+		"kybStatus": map[string]any{
+			"$ne": entities.KYBApproved,
+		},
+	})
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return nil, nil, err
+	}
+	if unverified >= constants.MAX_UNVERIFIED_BUSINESSES {
+		err = fmt.Errorf("You can only have %d businesses that have not been verified. Verify one of your businesses to create another.", constants.MAX_UNVERIFIED_BUSINESSES)
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return nil, nil, err
+	}
 	var business *entities.Business
 	var wallet *entities.Wallet
-	var err error
 	businessRepo.StartTransaction(func(sc mongo.Session, c context.Context) error {
 		payload = payload.ParseModel().(*entities.Business)
 		walletPayload := &entities.Wallet{
@@ -31,8 +48,8 @@ func CreateBusiness(ctx any, payload *entities.Business) (*entities.Business, *e
 			UserID: payload.UserID,
 			BusinessName: &payload.Name,
 			Frozen: false,
-			Balance: 0,
-			LedgerBalance: 0,
+			Balance: money.Zero("NGN"),
+			LedgerBalance: money.Zero("NGN"),
 			Currency: "NGN",
 			LockedFundsLog: []entities.LockedFunds{},
 		}
@@ -34,6 +34,18 @@ func DeleteBusiness(ctx any, id string) error {
 			return errors.New("")
 		}
 		err = walletUsecases.DeleteWallet(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		_, err = repository.BusinessMemberRepo().DeleteMany(map[string]interface{}{
+			"businessID": id,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = repository.BusinessAPIKeyRepo().DeleteMany(map[string]interface{}{
+			"businessID": id,
+		})
 		return err
 	})
 
@@ -17,6 +17,21 @@ func UpdateBusiness(ctx any, payload *dto.UpdateBusinessDTO) error {
 	}
 
 	businessRepo := repository.BusinessRepo()
+	business, err := businessRepo.FindByID(payload.ID)
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
+	if business == nil {
+		apperrors.NotFoundError(ctx, "business does not exist")
+		return errors.New("")
+	}
+	// once KYB details are in, the business goes by the name it is registered with
+	if business.KYB != nil {
+		err = errors.New("This business goes by the name it is registered with and cannot be renamed")
+		apperrors.ClientError(ctx, err.Error(), nil)
+		return err
+	}
 	success, err := businessRepo.UpdatePartialByID(payload.ID, map[string]any{
 		"name": payload.Name,
 	})
@@ -28,5 +43,22 @@ func UpdateBusiness(ctx any, payload *dto.UpdateBusinessDTO) error {
 		apperrors.NotFoundError(ctx, "business does not exist")
 		return errors.New("")
 	}
+	// copies of the name kept so they can be shown without loading the business
+	_, err = repository.WalletRepo().UpdatePartialByFilter(map[string]interface{}{
+		"businessID": payload.ID,
+	}, map[string]any{
+		"businessName": payload.Name,
+	})
+	if err == nil {
+		_, err = repository.BusinessMemberRepo().UpdatePartialByFilter(map[string]interface{}{
+			"businessID": payload.ID,
+		}, map[string]any{
+			"businessName": payload.Name,
+		})
+	}
+	if err != nil {
+		apperrors.FatalServerError(ctx)
+		return err
+	}
 	return nil
 }
\ No newline at end of file
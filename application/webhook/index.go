@@ -1,6 +1,18 @@
 package webhook
 
-import "kego.com/application/interfaces"
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/interfaces"
+	"kego.com/application/services"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+	server_response "kego.com/infrastructure/serverResponse"
+)
 
 func PaystackWebhook(ctx *interfaces.ApplicationContext[CustomerVerificationDTO]){
 	if ctx.Body.Event == "customeridentification.success" {
@@ -8,4 +20,37 @@ func PaystackWebhook(ctx *interfaces.ApplicationContext[CustomerVerificationDTO]
 	}else if ctx.Body.Event == "customeridentification.failed" {
 
 	}
-}
\ No newline at end of file
+}
+
+// FlutterwaveTransferWebhook settles a local payout once Flutterwave reports how it ended.
+// Flutterwave sends the secret hash set on its dashboard in the verif-hash header.
+func FlutterwaveTransferWebhook(ctx *interfaces.ApplicationContext[TransferWebhookDTO]){
+	secretHash := os.Getenv("FLUTTERWAVE_WEBHOOK_HASH")
+	signature, _ := ctx.GetHeader("Verif-Hash").(string)
+	if secretHash == "" || subtle.ConstantTimeCompare([]byte(signature), []byte(secretHash)) != 1 {
+		apperrors.AuthenticationError(ctx.Ctx, "invalid webhook signature")
+		return
+	}
+	if ctx.Body.Event == "transfer.completed" {
+		status := entities.TransactionPending
+		switch ctx.Body.Data.Status {
+		case "SUCCESSFUL":
+			status = entities.TransactionSuccessful
+		case "FAILED":
+			status = entities.TransactionFailed
+		}
+		if err := services.SettleTransaction(ctx.Body.Data.Reference, status); err != nil {
+			logger.Error(errors.New("could not settle transfer from webhook"), logger.LoggerOptions{
+				Key: "transactionReference",
+				Data: ctx.Body.Data.Reference,
+			}, logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			})
+			// a non 2xx response makes Flutterwave retry
+			apperrors.FatalServerError(ctx.Ctx)
+			return
+		}
+	}
+	server_response.Responder.Respond(ctx.Ctx, http.StatusOK, "webhook received", nil, nil)
+}
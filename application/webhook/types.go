@@ -10,4 +10,13 @@ type CustomerVerificationDTOData struct {
 	CustomerCode string  `json:"customer_code"`
 	Email 		 string  `json:"email"`
 	Reason 		 string  `json:"reason"`
-}
\ No newline at end of file
+}
+type TransferWebhookDTO struct {
+	Event string 			  `json:"event"`
+	Data  TransferWebhookData `json:"data"`
+}
+
+type TransferWebhookData struct {
+	Reference string `json:"reference"`
+	Status 	  string `json:"status"`
+}
@@ -0,0 +1,40 @@
+// Command rotatejwtkeys adds a new key for signing access tokens and removes the oldest ones.
+//
+//	go run ./cmd/rotatejwtkeys -dir keys -keep 3
+//
+// The api reloads the keys folder every minute and starts signing with the new key a few minutes later,
+// once every instance has loaded it for verification. Keys are removed oldest first, so keep enough keys
+// to cover the tokens that are still valid.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"kego.com/infrastructure/auth"
+)
+
+func main() {
+	godotenv.Load()
+	dir := flag.String("dir", auth.KeysDir(), "folder the jwt keys are kept in, defaults to JWT_KEYS_DIR")
+	keep := flag.Int("keep", 3, "number of keys to keep after rotating, including the new one")
+	flag.Parse()
+
+	keyID, err := auth.GenerateKey(*dir, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not create key: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("created key %s\n", keyID)
+	removed, err := auth.PruneKeys(*dir, *keep)
+	for _, id := range removed {
+		fmt.Printf("removed key %s\n", id)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not remove old keys: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -6,10 +6,38 @@ import (
 	"kego.com/application/utils"
 )
 
+type KYBStatus string
+
+const (
+	KYBUnverified 		KYBStatus = "unverified"
+	KYBPendingReview 	KYBStatus = "pending_review"
+	KYBApproved 		KYBStatus = "approved"
+	KYBRejected 		KYBStatus = "rejected"
+)
+
+type BusinessDocumentType string
+
+const (
+	CertificateOfIncorporation 	BusinessDocumentType = "certificate_of_incorporation"
+	MemorandumAndArticles 		BusinessDocumentType = "memorandum_and_articles"
+	StatusReport 				BusinessDocumentType = "status_report"
+	ProofOfAddress 				BusinessDocumentType = "proof_of_address"
+	TINCertificate 				BusinessDocumentType = "tin_certificate"
+)
+
+// documents a business has to upload before it can be reviewed
+var RequiredBusinessDocuments = []BusinessDocumentType{CertificateOfIncorporation, ProofOfAddress}
+
 type Business struct {
 	Name      	string    `bson:"name" json:"name" validate:"required"`
 	UserID    	string    `bson:"userID" json:"userID" validate:"required"`
 	WalletID  	string    `bson:"walletID" json:"walletID"`
+	PricingPlanCode *string `bson:"pricingPlanCode" json:"pricingPlanCode"`
+	// businesses created before KYB have no status and are treated as unverified
+	KYBStatus 	KYBStatus 	 `bson:"kybStatus" json:"kybStatus"`
+	KYB 		*BusinessKYB `bson:"kyb" json:"kyb"`
+	// nil when any member who can send payouts can send them alone
+	PayoutApprovalPolicy *PayoutApprovalPolicy `bson:"payoutApprovalPolicy" json:"payoutApprovalPolicy"`
 
 	ID        string    `bson:"_id" json:"id"`
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
@@ -20,7 +48,52 @@ func (business Business) ParseModel() any {
 	if business.ID == "" {
 		business.CreatedAt = time.Now()
 		business.ID = utils.GenerateUUIDString()
+		if business.KYBStatus == "" {
+			business.KYBStatus = KYBUnverified
+		}
 	}
 	business.UpdatedAt = time.Now()
 	return &business
 }
+
+// What a business has told us about itself for Know-Your-Business checks and how the review went.
+type BusinessKYB struct {
+	RegistrationNumber 	string 				`bson:"registrationNumber" json:"registrationNumber"` // CAC number with its type in front, e.g. RC1234567
+	RegisteredName 		string 				`bson:"registeredName" json:"registeredName"` // as CAC has it
+	RegistrationDate 	string 				`bson:"registrationDate" json:"registrationDate"`
+	RegistrationStatus 	string 				`bson:"registrationStatus" json:"registrationStatus"`
+	LookupProvider 		string 				`bson:"lookupProvider" json:"-"`
+	TIN 				string 				`bson:"tin" json:"tin"`
+	RegisteredAddress 	BusinessAddress 	`bson:"registeredAddress" json:"registeredAddress"`
+	Directors 			[]BusinessPerson 	`bson:"directors" json:"directors"`
+	BeneficialOwners 	[]BusinessPerson 	`bson:"beneficialOwners" json:"beneficialOwners"`
+	Documents 			[]BusinessDocument 	`bson:"documents" json:"documents"`
+	SubmittedAt 		*time.Time 			`bson:"submittedAt" json:"submittedAt"`
+	ReviewedAt 			*time.Time 			`bson:"reviewedAt" json:"reviewedAt"`
+	ReviewedBy 			*string 			`bson:"reviewedBy" json:"-"`
+	RejectionReason 	*string 			`bson:"rejectionReason" json:"rejectionReason"`
+}
+
+type BusinessAddress struct {
+	Line1 		string 	`bson:"line1" json:"line1" validate:"required"`
+	Line2 		*string `bson:"line2" json:"line2"`
+	City 		string 	`bson:"city" json:"city" validate:"required"`
+	State 		string 	`bson:"state" json:"state" validate:"required"`
+	Country 	string 	`bson:"country" json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+// A director or beneficial owner of a business.
+type BusinessPerson struct {
+	FirstName 			string 	 `bson:"firstName" json:"firstName" validate:"required"`
+	LastName 			string 	 `bson:"lastName" json:"lastName" validate:"required"`
+	DateOfBirth 		string 	 `bson:"dateOfBirth" json:"dateOfBirth" validate:"required"`
+	Nationality 		string 	 `bson:"nationality" json:"nationality" validate:"required,iso3166_1_alpha2"`
+	OwnershipPercent 	*float64 `bson:"ownershipPercent" json:"ownershipPercent" validate:"omitempty,gt=0,lte=100"` // beneficial owners only
+}
+
+type BusinessDocument struct {
+	Type 		BusinessDocumentType 	`bson:"type" json:"type"`
+	FileName 	string 					`bson:"fileName" json:"fileName"`
+	URL 		string 					`bson:"url" json:"-"`
+	UploadedAt 	time.Time 				`bson:"uploadedAt" json:"uploadedAt"`
+}
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+// A key a business's own systems use to call the server-to-server routes. Only a hash of the key is kept,
+// so it is shown in full once, when it is created.
+type BusinessAPIKey struct {
+	BusinessID 		string 					`bson:"businessID" json:"businessID"`
+	Name 			string 					`bson:"name" json:"name"`
+	// the start of the key, shown so it can be told apart from the business's other keys
+	Prefix 			string 					`bson:"prefix" json:"prefix"`
+	KeyHash 		string 					`bson:"keyHash" json:"-"`
+	Scopes 			[]BusinessPermission 	`bson:"scopes" json:"scopes"`
+	// IP addresses or CIDR ranges the key can be used from. Empty means anywhere.
+	AllowedIPs 		[]string 				`bson:"allowedIPs" json:"allowedIPs"`
+	CreatedBy 		string 					`bson:"createdBy" json:"createdBy"`
+	LastUsedAt 		*time.Time 				`bson:"lastUsedAt" json:"lastUsedAt"`
+	LastUsedIP 		*string 				`bson:"lastUsedIP" json:"lastUsedIP"`
+	RevokedAt 		*time.Time 				`bson:"revokedAt" json:"revokedAt"`
+	RevokedBy 		*string 				`bson:"revokedBy" json:"revokedBy"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Can reports whether the key was given the permission when it was created.
+func (key BusinessAPIKey) Can(permission BusinessPermission) bool {
+	for _, scope := range key.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (key BusinessAPIKey) ParseModel() any {
+	if key.ID == "" {
+		key.CreatedAt = time.Now()
+		key.ID = utils.GenerateUUIDString()
+	}
+	key.UpdatedAt = time.Now()
+	return &key
+}
@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type BusinessRole string
+
+const (
+	// the user who created the business. Each business has one and it is not held through a membership.
+	BusinessOwner 	BusinessRole = "owner"
+	BusinessAdmin 	BusinessRole = "admin"
+	BusinessFinance BusinessRole = "finance"
+	BusinessViewer 	BusinessRole = "viewer"
+)
+
+type BusinessPermission string
+
+const (
+	ViewBalances 		BusinessPermission = "view_balances"
+	SendPayouts 		BusinessPermission = "send_payouts"
+	ManageBeneficiaries BusinessPermission = "manage_beneficiaries"
+	ManageSettings 		BusinessPermission = "manage_settings"
+	ManageMembers 		BusinessPermission = "manage_members"
+	CloseBusiness 		BusinessPermission = "close_business"
+	// only the owner decides who has to sign off payouts, so no one can remove the check on themselves
+	ManagePayoutApprovals BusinessPermission = "manage_payout_approvals"
+	// API keys can move money without a PIN, so only the owner can create them
+	ManageAPIKeys 		BusinessPermission = "manage_api_keys"
+)
+
+var businessRolePermissions = map[BusinessRole][]BusinessPermission{
+	BusinessOwner: 		{ViewBalances, SendPayouts, ManageBeneficiaries, ManageSettings, ManageMembers, CloseBusiness, ManagePayoutApprovals, ManageAPIKeys},
+	BusinessAdmin: 		{ViewBalances, SendPayouts, ManageBeneficiaries, ManageSettings, ManageMembers},
+	BusinessFinance: 	{ViewBalances, SendPayouts, ManageBeneficiaries},
+	BusinessViewer: 	{ViewBalances},
+}
+
+// Can reports whether the role allows the permission.
+func (role BusinessRole) Can(permission BusinessPermission) bool {
+	for _, p := range businessRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type BusinessMemberStatus string
+
+const (
+	BusinessMemberInvited BusinessMemberStatus = "invited"
+	BusinessMemberActive  BusinessMemberStatus = "active"
+)
+
+// Someone other than the owner who has been given access to a business.
+// Invites are sent to an email address and are accepted by the user signed in with that address.
+type BusinessMember struct {
+	BusinessID 		string 					`bson:"businessID" json:"businessID"`
+	BusinessName 	string 					`bson:"businessName" json:"businessName"`
+	UserID 			*string 				`bson:"userID" json:"userID"` // set once the invite is accepted
+	Email 			string 					`bson:"email" json:"email"`
+	FirstName 		*string 				`bson:"firstName" json:"firstName"`
+	LastName 		*string 				`bson:"lastName" json:"lastName"`
+	Role 			BusinessRole 			`bson:"role" json:"role"`
+	Status 			BusinessMemberStatus 	`bson:"status" json:"status"`
+	InvitedBy 		string 					`bson:"invitedBy" json:"invitedBy"`
+	InviteExpiresAt *time.Time 				`bson:"inviteExpiresAt" json:"inviteExpiresAt"`
+	JoinedAt 		*time.Time 				`bson:"joinedAt" json:"joinedAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (member BusinessMember) ParseModel() any {
+	if member.ID == "" {
+		member.CreatedAt = time.Now()
+		member.ID = utils.GenerateUUIDString()
+	}
+	member.UpdatedAt = time.Now()
+	return &member
+}
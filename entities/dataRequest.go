@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type DataRequestType string
+
+const (
+	DataExportRequest 	DataRequestType = "export"
+	DataErasureRequest 	DataRequestType = "erasure"
+)
+
+type DataRequestStatus string
+
+const (
+	DataRequestPending 		DataRequestStatus = "pending"
+	DataRequestProcessing 	DataRequestStatus = "processing"
+	DataRequestCompleted 	DataRequestStatus = "completed"
+	DataRequestFailed 		DataRequestStatus = "failed"
+	DataRequestCancelled 	DataRequestStatus = "cancelled"
+)
+
+// A request from a user for a copy of their data or for their data to be erased. Requests are carried out
+// in the background once ScheduledFor has passed.
+type DataRequest struct {
+	UserID 			string 				`bson:"userID" json:"userID"`
+	Type 			DataRequestType 	`bson:"type" json:"type"`
+	Status 			DataRequestStatus 	`bson:"status" json:"status"`
+	ScheduledFor 	time.Time 			`bson:"scheduledFor" json:"scheduledFor"`
+	LockedUntil 	*time.Time 			`bson:"lockedUntil" json:"-"`
+	FileName 		*string 			`bson:"fileName" json:"-"` // the archive of an export, relative to the exports folder
+	ExpiresAt 		*time.Time 			`bson:"expiresAt" json:"expiresAt"` // when an export's archive is deleted
+	CompletedAt 	*time.Time 			`bson:"completedAt" json:"completedAt"`
+	FailureReason 	*string 			`bson:"failureReason" json:"failureReason"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (request DataRequest) ParseModel() any {
+	if request.ID == "" {
+		request.CreatedAt = time.Now()
+		request.ID = utils.GenerateUUIDString()
+	}
+	request.UpdatedAt = time.Now()
+	return &request
+}
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type DevicePlatform string
+
+const (
+	AndroidPlatform DevicePlatform = "android"
+	IOSPlatform     DevicePlatform = "ios"
+	UnknownPlatform DevicePlatform = "unknown"
+)
+
+func PlatformFromUserAgent(userAgent string) DevicePlatform {
+	if strings.Contains(userAgent, "Android") {
+		return AndroidPlatform
+	}
+	if strings.Contains(userAgent, "iOS") {
+		return IOSPlatform
+	}
+	return UnknownPlatform
+}
+
+// A device the user has signed in on. Push notifications go to every device with a push token
+// that has been seen recently.
+type Device struct {
+	UserID 		string 			`bson:"userID" json:"-" validate:"required"`
+	DeviceID 	string 			`bson:"deviceID" json:"deviceID" validate:"required"`
+	PushToken 	*string 		`bson:"pushToken" json:"-"`
+	Platform 	DevicePlatform 	`bson:"platform" json:"platform"`
+	AppVersion 	string 			`bson:"appVersion" json:"appVersion"`
+	UserAgent 	string 			`bson:"userAgent" json:"userAgent"`
+	LastSeenAt 	time.Time 		`bson:"lastSeenAt" json:"lastSeenAt"`
+	Trusted 	bool 			`bson:"trusted" json:"trusted"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (device Device) ParseModel() any {
+	if device.ID == "" {
+		device.CreatedAt = time.Now()
+		device.ID = utils.GenerateUUIDString()
+	}
+	device.UpdatedAt = time.Now()
+	return &device
+}
@@ -1,28 +1,138 @@
 package entities
 
+import (
+	"fmt"
+	"time"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
 type ExchangeRates struct{
-	USDNGN 		 float32		`json:"USDNGN"`
-	USDCAD 		 float32		`json:"USDCAD"`
-	USDGHS 		 float32		`json:"USDGHS"`
-	USDINR 		 float32		`json:"USDINR"`
-	USDKES 		 float32		`json:"USDKES"`
-	USDZAR 		 float32		`json:"USDZAR"`
-	USDGBP 		 float32		`json:"USDGBP"`
-
-}
-
-func (er *ExchangeRates) FormatAllRates() map[string]float32 {
-	formatedRates := map[string]float32{}
-	formatedRates["American Dollar (US) - Naira"] = er.USDNGN
-	formatedRates["Canadian Dollar (CA) - Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDCAD)
-	formatedRates["British Pounds - Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDGBP)
-	formatedRates["South African Rand (ZA)- Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDZAR)
-	formatedRates["Ghanaian Cedis (GH) - Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDGHS)
-	formatedRates["Indian Rupees (IN) - Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDINR)
-	formatedRates["Kenyan Shilling (KE) - Naira"] = er.FormatAgainstNGN(er.USDNGN, er.USDKES)
+	USDNGN 		 float64		`bson:"USDNGN" json:"USDNGN"`
+	USDCAD 		 float64		`bson:"USDCAD" json:"USDCAD"`
+	USDGHS 		 float64		`bson:"USDGHS" json:"USDGHS"`
+	USDINR 		 float64		`bson:"USDINR" json:"USDINR"`
+	USDKES 		 float64		`bson:"USDKES" json:"USDKES"`
+	USDZAR 		 float64		`bson:"USDZAR" json:"USDZAR"`
+	USDGBP 		 float64		`bson:"USDGBP" json:"USDGBP"`
+
+}
+
+// The rates are only used for display. Conversions go through NGNRate which keeps them exact.
+func (er *ExchangeRates) FormatAllRates() map[string]float64 {
+	formatedRates := map[string]float64{}
+	labels := map[string]string{
+		"USD": "American Dollar (US) - Naira",
+		"CAD": "Canadian Dollar (CA) - Naira",
+		"GBP": "British Pounds - Naira",
+		"ZAR": "South African Rand (ZA)- Naira",
+		"GHS": "Ghanaian Cedis (GH) - Naira",
+		"INR": "Indian Rupees (IN) - Naira",
+		"KES": "Kenyan Shilling (KE) - Naira",
+	}
+	for currency, label := range labels {
+		rate, err := er.NGNRate(currency)
+		if err != nil {
+			continue
+		}
+		formatedRates[label] = rate.Float64()
+	}
 	 return formatedRates
 }
 
-func (er *ExchangeRates) FormatAgainstNGN(ngnusdRate float32, usdotherRate float32) float32 {
-	return ngnusdRate * (1 / usdotherRate)
-}
\ No newline at end of file
+// NGNRate returns the number of naira in one unit of the currency.
+// Rates other than USD are crossed through the dollar.
+func (er *ExchangeRates) NGNRate(currency string) (money.Rate, error) {
+	usdngn, err := money.RateFromFloat(er.USDNGN)
+	if err != nil {
+		return money.Rate{}, err
+	}
+	var usdother float64
+	switch currency {
+	case "NGN":
+		return money.ParseRate("1")
+	case "USD":
+		return usdngn, nil
+	case "CAD":
+		usdother = er.USDCAD
+	case "GHS":
+		usdother = er.USDGHS
+	case "INR":
+		usdother = er.USDINR
+	case "KES":
+		usdother = er.USDKES
+	case "ZAR":
+		usdother = er.USDZAR
+	case "GBP":
+		usdother = er.USDGBP
+	default:
+		return money.Rate{}, fmt.Errorf("Currency %s is not supported", currency)
+	}
+	rate, err := money.RateFromFloat(usdother)
+	if err != nil {
+		return money.Rate{}, err
+	}
+	return usdngn.Divide(rate)
+}
+
+type CurrencyConversion struct {
+	Rate 		 string 		`json:"rate"`
+	Amount 		 money.Money 	`json:"amount"`
+	ValueInNGN 	 money.Money 	`json:"valueInNGN"`
+	ValueInUSD 	 money.Money 	`json:"valueInUSD"`
+}
+
+// Converts an amount into naira and dollars.
+func (er *ExchangeRates) Convert(amount money.Money) (*CurrencyConversion, error) {
+	rate, err := er.NGNRate(amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	usdngn, err := er.NGNRate("USD")
+	if err != nil {
+		return nil, err
+	}
+	usdRate, err := rate.Divide(usdngn)
+	if err != nil {
+		return nil, err
+	}
+	valueInNGN, err := amount.Convert(rate, "NGN")
+	if err != nil {
+		return nil, err
+	}
+	valueInUSD, err := amount.Convert(usdRate, "USD")
+	if err != nil {
+		return nil, err
+	}
+	return &CurrencyConversion{
+		Rate: rate.String(),
+		Amount: amount,
+		ValueInNGN: valueInNGN,
+		ValueInUSD: valueInUSD,
+	}, nil
+}
+
+// A copy of the rates fetched from a provider at a point in time.
+type ExchangeRateSnapshot struct {
+	Provider 	 string 		`bson:"provider" json:"provider" validate:"required"`
+	Rates 		 ExchangeRates 	`bson:"rates" json:"rates" validate:"required"`
+	FetchedAt 	 time.Time 		`bson:"fetchedAt" json:"fetchedAt" validate:"required"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (snapshot ExchangeRateSnapshot) ParseModel() any {
+	if snapshot.ID == "" {
+		snapshot.CreatedAt = time.Now()
+		snapshot.ID = utils.GenerateUUIDString()
+	}
+	snapshot.UpdatedAt = time.Now()
+	return &snapshot
+}
+
+func (snapshot *ExchangeRateSnapshot) Age() time.Duration {
+	return time.Since(snapshot.FetchedAt)
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type IdentityDocumentType string
+
+const (
+	BVNDocument 			IdentityDocumentType = "bvn"
+	NINDocument 			IdentityDocumentType = "nin"
+	PassportDocument 		IdentityDocumentType = "passport"
+	DriversLicenceDocument 	IdentityDocumentType = "drivers_licence"
+)
+
+type IdentityVerificationStatus string
+
+const (
+	IdentityVerified 	IdentityVerificationStatus = "verified"
+	IdentityRejected 	IdentityVerificationStatus = "rejected"
+)
+
+type KYCTier int
+
+const (
+	// BVN checked against a picture of the user
+	KYCTierBasic 	KYCTier = 1
+	// a government issued id that agrees with the BVN as well
+	KYCTierFull 	KYCTier = 2
+)
+
+// Evidence of an identity document being checked for a user, kept whether or not the check passed.
+// The document number is only stored masked and hashed.
+type IdentityVerification struct {
+	UserID 					string 						`bson:"userID" json:"userID"`
+	DocumentType 			IdentityDocumentType 		`bson:"documentType" json:"documentType"`
+	DocumentNumber 			string 						`bson:"documentNumber" json:"documentNumber"` // all but the last 4 characters masked
+	DocumentNumberHash 		string 						`bson:"documentNumberHash" json:"-"` // used to find the same document on another account
+	Provider 				string 						`bson:"provider" json:"provider"`
+	Status 					IdentityVerificationStatus 	`bson:"status" json:"status"`
+	FirstName 				string 						`bson:"firstName" json:"firstName"`
+	MiddleName 				*string 					`bson:"middleName" json:"middleName"`
+	LastName 				string 						`bson:"lastName" json:"lastName"`
+	DateOfBirth 			string 						`bson:"dateOfBirth" json:"dateOfBirth"`
+	ExpiryDate 				*string 					`bson:"expiryDate" json:"expiryDate"`
+	NameMatched 			bool 						`bson:"nameMatched" json:"nameMatched"` // whether the name agrees with the BVN record
+	DateOfBirthMatched 		bool 						`bson:"dateOfBirthMatched" json:"dateOfBirthMatched"`
+	FaceMatchConfidence 	*float32 					`bson:"faceMatchConfidence" json:"faceMatchConfidence"`
+	DocumentImage 			*string 					`bson:"documentImage" json:"-"`
+	RejectionReason 		*string 					`bson:"rejectionReason" json:"rejectionReason"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (verification IdentityVerification) ParseModel() any {
+	if verification.ID == "" {
+		verification.CreatedAt = time.Now()
+		verification.ID = utils.GenerateUUIDString()
+	}
+	verification.UpdatedAt = time.Now()
+	return &verification
+}
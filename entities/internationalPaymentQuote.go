@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
+// A locked exchange rate and fee breakdown for an international payout.
+// The payout endpoint consumes the quote so the user is charged exactly what they were shown.
+type InternationalPaymentQuote struct {
+	UserID          		string   		 `bson:"userID" json:"userID" validate:"required"`
+	BusinessID      		string   		 `bson:"businessID" json:"businessID" validate:"required"`
+	DestinationCountryCode  string 			 `bson:"destinationCountryCode" json:"destinationCountryCode" validate:"iso3166_1_alpha2"`
+	Amount               	money.Money      `bson:"amount" json:"amount" validate:"required"`
+	Rate               		string           `bson:"rate" json:"rate" validate:"required"`
+	ExchangeRateSnapshotID  string           `bson:"exchangeRateSnapshotID" json:"exchangeRateSnapshotID" validate:"required"`
+	ValueInUSD              money.Money      `bson:"valueInUSD" json:"valueInUSD" validate:"required"`
+	AmountInNGN          	money.Money      `bson:"amountInNGN" json:"amountInNGN" validate:"required"`
+	ProcessorFee         	money.Money      `bson:"processorFee" json:"processorFee"`
+	Fee          		 	money.Money      `bson:"fee" json:"fee"`
+	PricingPlan          	AppliedPricingPlan `bson:"pricingPlan" json:"pricingPlan"`
+	TotalAmount          	money.Money      `bson:"totalAmount" json:"totalAmount" validate:"required"`
+	Consumed                bool             `bson:"consumed" json:"consumed"`
+	ExpiresAt               time.Time        `bson:"expiresAt" json:"expiresAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (quote InternationalPaymentQuote) ParseModel() any {
+	if quote.ID == "" {
+		quote.CreatedAt = time.Now()
+		quote.ID = utils.GenerateUUIDString()
+	}
+	quote.UpdatedAt = time.Now()
+	return &quote
+}
+
+func (quote *InternationalPaymentQuote) Expired() bool {
+	return time.Now().After(quote.ExpiresAt)
+}
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type NotificationLinkType string
+
+const (
+	TransactionNotificationLink NotificationLinkType = "transaction"
+	BusinessNotificationLink    NotificationLinkType = "business"
+	BusinessInviteNotificationLink NotificationLinkType = "business_invite"
+	PayoutApprovalNotificationLink NotificationLinkType = "payout_approval"
+)
+
+// Where the app should take the user when they open a notification
+type NotificationLink struct {
+	Type 		NotificationLinkType  `bson:"type" json:"type"`
+	ID 			string 				  `bson:"id" json:"id"`
+	BusinessID 	*string 			  `bson:"businessID" json:"businessID"`
+}
+
+// A notification in a user's inbox. Every notification we push or email is kept here so users can find it later.
+type Notification struct {
+	UserID 		string 				`bson:"userID" json:"userID" validate:"required"`
+	EventID 	string 				`bson:"eventID" json:"-" validate:"required"` // the outbox event the notification was created from
+	Type 		string 				`bson:"type" json:"type" validate:"required"`
+	Title 		string 				`bson:"title" json:"title" validate:"required"`
+	Body 		string 				`bson:"body" json:"body" validate:"required"`
+	Link 		*NotificationLink 	`bson:"link" json:"link"`
+	Read 		bool 				`bson:"read" json:"read"`
+	ReadAt 		*time.Time 			`bson:"readAt" json:"readAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (notification Notification) ParseModel() any {
+	if notification.ID == "" {
+		notification.CreatedAt = time.Now()
+		notification.ID = utils.GenerateUUIDString()
+	}
+	notification.UpdatedAt = time.Now()
+	return &notification
+}
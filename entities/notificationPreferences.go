@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type NotificationCategory string
+
+const (
+	SecurityNotification     NotificationCategory = "security"
+	TransactionsNotification NotificationCategory = "transactions"
+	MarketingNotification    NotificationCategory = "marketing"
+	ProductNotification      NotificationCategory = "product"
+)
+
+type NotificationChannel string
+
+const (
+	EmailChannel NotificationChannel = "email"
+	PushChannel  NotificationChannel = "push"
+	SMSChannel   NotificationChannel = "sms"
+)
+
+// Where a user's OTPs are sent.
+type OTPDelivery string
+
+const (
+	OTPByEmail OTPDelivery = "email"
+	OTPBySMS   OTPDelivery = "sms"
+	OTPByBoth  OTPDelivery = "both"
+)
+
+func (delivery OTPDelivery) Channels() []NotificationChannel {
+	switch delivery {
+	case OTPBySMS:
+		return []NotificationChannel{SMSChannel}
+	case OTPByBoth:
+		return []NotificationChannel{EmailChannel, SMSChannel}
+	}
+	return []NotificationChannel{EmailChannel}
+}
+
+// The categories a user wants to hear about on a channel. Security is always on.
+type ChannelPreferences struct {
+	Security 	 bool `bson:"security" json:"security"`
+	Transactions bool `bson:"transactions" json:"transactions"`
+	Marketing 	 bool `bson:"marketing" json:"marketing"`
+	Product 	 bool `bson:"product" json:"product"`
+}
+
+func (preferences *ChannelPreferences) Allows(category NotificationCategory) bool {
+	switch category {
+	case SecurityNotification:
+		return true
+	case TransactionsNotification:
+		return preferences.Transactions
+	case MarketingNotification:
+		return preferences.Marketing
+	case ProductNotification:
+		return preferences.Product
+	}
+	return false
+}
+
+type NotificationPreferences struct {
+	UserID  string 				`bson:"userID" json:"userID" validate:"required"`
+	Email 	ChannelPreferences 	`bson:"email" json:"email"`
+	Push 	ChannelPreferences 	`bson:"push" json:"push"`
+	SMS 	ChannelPreferences 	`bson:"sms" json:"sms"`
+	OTPDelivery OTPDelivery 	`bson:"otpDelivery" json:"otpDelivery" validate:"oneof=email sms both"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (preferences NotificationPreferences) ParseModel() any {
+	if preferences.ID == "" {
+		preferences.CreatedAt = time.Now()
+		preferences.ID = utils.GenerateUUIDString()
+	}
+	preferences.UpdatedAt = time.Now()
+	return &preferences
+}
+
+// Users who have never changed their preferences get everything except marketing,
+// and only security messages by SMS since those cost us per message.
+func DefaultNotificationPreferences(userID string) NotificationPreferences {
+	defaults := ChannelPreferences{
+		Security: true,
+		Transactions: true,
+		Marketing: false,
+		Product: true,
+	}
+	return NotificationPreferences{
+		UserID: userID,
+		Email: defaults,
+		Push: defaults,
+		SMS: ChannelPreferences{
+			Security: true,
+		},
+		OTPDelivery: OTPByEmail,
+	}
+}
+
+// Allows reports whether the user wants notifications of the category on the channel.
+// Channels without preferences, like the in-app inbox, are always allowed.
+func (preferences *NotificationPreferences) Allows(channel NotificationChannel, category NotificationCategory) bool {
+	switch channel {
+	case EmailChannel:
+		return preferences.Email.Allows(category)
+	case PushChannel:
+		return preferences.Push.Allows(category)
+	case SMSChannel:
+		return preferences.SMS.Allows(category)
+	}
+	return true
+}
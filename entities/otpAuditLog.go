@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+type OTPAuditAction string
+
+const (
+	OTPIssued   	OTPAuditAction = "issued"
+	OTPConsumed 	OTPAuditAction = "consumed"
+	// the otp was thrown away after too many wrong guesses
+	OTPInvalidated 	OTPAuditAction = "invalidated"
+)
+
+// A record of an OTP being sent or used, kept so support can see what happened on an account.
+// The code itself is never stored.
+type OTPAuditLog struct {
+	Purpose 	string 			`bson:"purpose" json:"purpose"`
+	Subject 	string 			`bson:"subject" json:"subject"` // the email or user id the otp was sent for
+	Action 		OTPAuditAction 	`bson:"action" json:"action"`
+	IPAddress 	*string 		`bson:"ipAddress" json:"ipAddress"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (log OTPAuditLog) ParseModel() any {
+	if log.ID == "" {
+		log.CreatedAt = time.Now()
+		log.ID = utils.GenerateUUIDString()
+	}
+	log.UpdatedAt = time.Now()
+	return &log
+}
@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"kego.com/application/utils"
+)
+
+type OutboxEventStatus string
+
+const (
+	OutboxEventPending   OutboxEventStatus = "pending"
+	OutboxEventDelivered OutboxEventStatus = "delivered"
+	OutboxEventSkipped   OutboxEventStatus = "skipped" // the user opted out of the event on the consumer's channel
+	OutboxEventDead      OutboxEventStatus = "dead"
+)
+
+// An event waiting to be delivered to one consumer. Publishing an event writes one of these
+// for every consumer subscribed to it so a failing consumer is retried without repeating the others.
+type OutboxEvent struct {
+	EventID 		string 				`bson:"eventID" json:"eventID" validate:"required"` // shared by every consumer's copy of the event
+	Type 			string 				`bson:"type" json:"type" validate:"required"`
+	UserID 			string 				`bson:"userID" json:"userID"`
+	Category 		NotificationCategory `bson:"category" json:"category" validate:"required"`
+	Consumer 		string 				`bson:"consumer" json:"consumer" validate:"required"`
+	Payload 		bson.Raw 			`bson:"payload" json:"-"`
+	Sensitive 		bool 				`bson:"sensitive" json:"sensitive"` // the payload is removed once the event is delivered or dead
+	Status 			OutboxEventStatus 	`bson:"status" json:"status"`
+	Attempts 		int 				`bson:"attempts" json:"attempts"`
+	LastError 		*string 			`bson:"lastError" json:"lastError"`
+	NextAttemptAt 	time.Time 			`bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil 	*time.Time 			`bson:"lockedUntil" json:"lockedUntil"`
+	DeliveredAt 	*time.Time 			`bson:"deliveredAt" json:"deliveredAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (event OutboxEvent) ParseModel() any {
+	if event.ID == "" {
+		event.CreatedAt = time.Now()
+		event.ID = utils.GenerateUUIDString()
+	}
+	event.UpdatedAt = time.Now()
+	return &event
+}
+
+// An event a consumer could not handle after every retry. Ops can requeue it once the cause is fixed.
+type DeadLetterEvent struct {
+	OutboxEventID 	string 		`bson:"outboxEventID" json:"outboxEventID" validate:"required"`
+	EventID 		string 		`bson:"eventID" json:"eventID" validate:"required"`
+	Type 			string 		`bson:"type" json:"type" validate:"required"`
+	Consumer 		string 		`bson:"consumer" json:"consumer" validate:"required"`
+	Attempts 		int 		`bson:"attempts" json:"attempts"`
+	LastError 		*string 	`bson:"lastError" json:"lastError"`
+	Requeued 		bool 		`bson:"requeued" json:"requeued"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (event DeadLetterEvent) ParseModel() any {
+	if event.ID == "" {
+		event.CreatedAt = time.Now()
+		event.ID = utils.GenerateUUIDString()
+	}
+	event.UpdatedAt = time.Now()
+	return &event
+}
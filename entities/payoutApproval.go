@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+// How many people have to approve a business's payouts of at least Threshold kobo, and who can approve them.
+type PayoutApprovalRule struct {
+	Threshold 		int64 			`bson:"threshold" json:"threshold" validate:"min=0"` // kobo, fees included
+	Approvals 		int 			`bson:"approvals" json:"approvals" validate:"min=1,max=5"`
+	ApproverRoles 	[]BusinessRole 	`bson:"approverRoles" json:"approverRoles" validate:"required,min=1,dive,oneof=owner admin finance"`
+}
+
+// Payouts that need approval before they are sent. The rule with the highest threshold the payout reaches applies.
+type PayoutApprovalPolicy struct {
+	Rules 				[]PayoutApprovalRule 	`bson:"rules" json:"rules"`
+	// how long approvers have before the payout is cancelled and its funds returned
+	ExpiresAfterHours 	int 					`bson:"expiresAfterHours" json:"expiresAfterHours"`
+	UpdatedBy 			string 					`bson:"updatedBy" json:"updatedBy"`
+	UpdatedAt 			time.Time 				`bson:"updatedAt" json:"updatedAt"`
+}
+
+// RuleFor returns the rule that applies to a payout of amount kobo, or nil if it can be sent straight away.
+func (policy *PayoutApprovalPolicy) RuleFor(amount int64) *PayoutApprovalRule {
+	var applies *PayoutApprovalRule
+	for i, rule := range policy.Rules {
+		if amount >= rule.Threshold && (applies == nil || rule.Threshold > applies.Threshold) {
+			applies = &policy.Rules[i]
+		}
+	}
+	return applies
+}
+
+type PayoutApprovalStatus string
+
+const (
+	PayoutAwaitingApproval 	PayoutApprovalStatus = "pending"
+	// enough approvals are in and the payout is being sent
+	PayoutApproved 			PayoutApprovalStatus = "approved"
+	PayoutApprovalSent 		PayoutApprovalStatus = "sent"
+	PayoutRejected 			PayoutApprovalStatus = "rejected"
+	PayoutApprovalExpired 	PayoutApprovalStatus = "expired"
+	// approved but the payment provider would not take it, so its funds were returned
+	PayoutApprovalFailed 	PayoutApprovalStatus = "failed"
+)
+
+type PayoutApprover struct {
+	UserID 		string 			`bson:"userID" json:"userID"`
+	Name 		string 			`bson:"name" json:"name"`
+	Role 		BusinessRole 	`bson:"role" json:"role"`
+	ApprovedAt 	time.Time 		`bson:"approvedAt" json:"approvedAt"`
+}
+
+// A business payout held until enough members approve it. Its funds stay locked in the wallet until it is
+// sent, rejected or expires.
+type PayoutApproval struct {
+	BusinessID 			string 					`bson:"businessID" json:"businessID"`
+	WalletID 			string 					`bson:"walletID" json:"walletID"`
+	RequestedBy 		string 					`bson:"requestedBy" json:"requestedBy"`
+	Rule 				PayoutApprovalRule 		`bson:"rule" json:"rule"`
+	Status 				PayoutApprovalStatus 	`bson:"status" json:"status"`
+	LockedFunds 		LockedFunds 			`bson:"lockedFunds" json:"lockedFunds"`
+	// the transaction as it will be recorded once the payout is sent
+	Transaction 		Transaction 			`bson:"transaction" json:"transaction"`
+	// when the exchange rate an international payout was quoted at stops being honoured. It is quoted again
+	// if it is approved after this.
+	QuoteExpiresAt 		*time.Time 				`bson:"quoteExpiresAt" json:"quoteExpiresAt"`
+	Approvers 			[]PayoutApprover 		`bson:"approvers" json:"approvers"`
+	RejectedBy 			*string 				`bson:"rejectedBy" json:"rejectedBy"`
+	RejectionReason 	*string 				`bson:"rejectionReason" json:"rejectionReason"`
+	TransactionID 		*string 				`bson:"transactionID" json:"transactionID"`
+	ExpiresAt 			time.Time 				`bson:"expiresAt" json:"expiresAt"`
+	DecidedAt 			*time.Time 				`bson:"decidedAt" json:"decidedAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (approval PayoutApproval) ParseModel() any {
+	if approval.ID == "" {
+		approval.CreatedAt = time.Now()
+		approval.ID = utils.GenerateUUIDString()
+	}
+	approval.UpdatedAt = time.Now()
+	return &approval
+}
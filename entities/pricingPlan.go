@@ -0,0 +1,175 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
+type FeeType string
+
+const (
+	FlatFee 	  FeeType = "flat"
+	PercentageFee FeeType = "percentage"
+	TieredFee 	  FeeType = "tiered"
+)
+
+// Who the fee is collected for
+type FeeComponent string
+
+const (
+	ProcessorFeeComponent FeeComponent = "processor"
+	PlatformFeeComponent  FeeComponent = "platform"
+)
+
+// Amounts in tiers and rules are in minor units of the currency being priced.
+type FeeTier struct {
+	UpTo            *int64     `bson:"upTo" json:"upTo"` // inclusive upper bound of the tier. The last tier has no upper bound
+	Flat            int64      `bson:"flat" json:"flat" validate:"min=0"`
+	RateBasisPoints int64      `bson:"rateBasisPoints" json:"rateBasisPoints" validate:"min=0"`
+}
+
+type FeeRule struct {
+	Intent          TransactionIntent  `bson:"intent" json:"intent" validate:"required"`
+	Corridor        *string            `bson:"corridor" json:"corridor" validate:"omitempty,iso3166_1_alpha2"` // destination country. Applies to every corridor when empty
+	Component       FeeComponent       `bson:"component" json:"component" validate:"required,oneof=processor platform"`
+	Type            FeeType            `bson:"type" json:"type" validate:"required,oneof=flat percentage tiered"`
+	Flat            int64              `bson:"flat" json:"flat" validate:"min=0"`
+	RateBasisPoints int64              `bson:"rateBasisPoints" json:"rateBasisPoints" validate:"min=0"` // 100 basis points is 1%
+	Tiers           []FeeTier          `bson:"tiers" json:"tiers" validate:"required_if=Type tiered,dive"`
+	Cap             *int64             `bson:"cap" json:"cap"`
+	Floor           *int64             `bson:"floor" json:"floor"`
+	VATBasisPoints  int64              `bson:"vatBasisPoints" json:"vatBasisPoints" validate:"min=0"`
+}
+
+// Calculate returns the fee charged by this rule and the VAT on it, in the amount's currency.
+// Percentages use banker's rounding. Caps and floors apply before VAT.
+func (rule *FeeRule) Calculate(amount money.Money) (fee money.Money, vat money.Money, err error) {
+	fee = money.Zero(amount.Currency)
+	switch rule.Type {
+	case FlatFee:
+		fee = money.New(rule.Flat, amount.Currency)
+	case PercentageFee:
+		fee, err = amount.ApplyBasisPoints(rule.RateBasisPoints)
+	case TieredFee:
+		for _, tier := range rule.Tiers {
+			if tier.UpTo == nil || amount.Amount <= *tier.UpTo {
+				fee, err = amount.ApplyBasisPoints(tier.RateBasisPoints)
+				if err == nil {
+					fee, err = fee.Add(money.New(tier.Flat, amount.Currency))
+				}
+				break
+			}
+		}
+	}
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	if rule.Floor != nil && fee.Amount < *rule.Floor {
+		fee = money.New(*rule.Floor, amount.Currency)
+	}
+	if rule.Cap != nil && fee.Amount > *rule.Cap {
+		fee = money.New(*rule.Cap, amount.Currency)
+	}
+	vat, err = fee.ApplyBasisPoints(rule.VATBasisPoints)
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	return fee, vat, nil
+}
+
+func (rule *FeeRule) Matches(intent TransactionIntent, corridor string) bool {
+	if rule.Intent != intent {
+		return false
+	}
+	return rule.Corridor == nil || *rule.Corridor == corridor
+}
+
+// A versioned fee schedule. Every version of a plan is stored as its own document so
+// transactions can always be traced back to the exact rules that priced them.
+type PricingPlan struct {
+	Code 		 string 	`bson:"code" json:"code" validate:"required,alphanum"`
+	Name 		 string 	`bson:"name" json:"name" validate:"required"`
+	Description  string 	`bson:"description" json:"description"`
+	Version 	 uint 		`bson:"version" json:"version"`
+	Rules 		 []FeeRule 	`bson:"rules" json:"rules" validate:"required,dive"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (plan PricingPlan) ParseModel() any {
+	if plan.ID == "" {
+		plan.CreatedAt = time.Now()
+		plan.ID = utils.GenerateUUIDString()
+	}
+	plan.UpdatedAt = time.Now()
+	return &plan
+}
+
+// Calculate applies the rules for the intent and corridor to the amount.
+// Corridor specific rules take precedence over rules that apply to every corridor.
+func (plan *PricingPlan) Calculate(intent TransactionIntent, corridor string, amount money.Money) (*FeeBreakdown, error) {
+	selected := map[FeeComponent]FeeRule{}
+	for _, rule := range plan.Rules {
+		if !rule.Matches(intent, corridor) {
+			continue
+		}
+		if current, ok := selected[rule.Component]; ok && current.Corridor != nil {
+			continue
+		}
+		selected[rule.Component] = rule
+	}
+	breakdown := FeeBreakdown{
+		ProcessorFee: money.Zero(amount.Currency),
+		PlatformFee: money.Zero(amount.Currency),
+		VAT: money.Zero(amount.Currency),
+		PricingPlan: AppliedPricingPlan{
+			PlanID: plan.ID,
+			Code: plan.Code,
+			Version: plan.Version,
+		},
+	}
+	for component, rule := range selected {
+		fee, vat, err := rule.Calculate(amount)
+		if err != nil {
+			return nil, err
+		}
+		breakdown.VAT, err = breakdown.VAT.Add(vat)
+		if err != nil {
+			return nil, err
+		}
+		total, err := fee.Add(vat)
+		if err != nil {
+			return nil, err
+		}
+		if component == ProcessorFeeComponent {
+			breakdown.ProcessorFee = total
+		} else {
+			breakdown.PlatformFee = total
+		}
+	}
+	return &breakdown, nil
+}
+
+// The plan version that priced a transaction
+type AppliedPricingPlan struct {
+	PlanID 	 string 	`bson:"planID" json:"planID"`
+	Code 	 string 	`bson:"code" json:"code"`
+	Version  uint 		`bson:"version" json:"version"`
+}
+
+// Fees are VAT inclusive. VAT holds the portion of both fees that is VAT.
+type FeeBreakdown struct {
+	ProcessorFee  money.Money 		  `bson:"processorFee" json:"processorFee"`
+	PlatformFee   money.Money 		  `bson:"platformFee" json:"platformFee"`
+	VAT 		  money.Money 		  `bson:"vat" json:"vat"`
+	PricingPlan   AppliedPricingPlan  `bson:"pricingPlan" json:"pricingPlan"`
+}
+
+// Total returns the amount charged on top of the amount being sent.
+func (breakdown *FeeBreakdown) Total(amount money.Money) (money.Money, error) {
+	return amount.Sum(breakdown.ProcessorFee, breakdown.PlatformFee)
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
+type ReconciliationRunStatus string
+
+const (
+	ReconciliationRunning   ReconciliationRunStatus = "running"
+	ReconciliationCompleted ReconciliationRunStatus = "completed"
+	ReconciliationFailed    ReconciliationRunStatus = "failed"
+)
+
+// Where the processor's side of a reconciliation came from
+type ReconciliationSource string
+
+const (
+	ReconciliationSourceAPI    ReconciliationSource = "api"
+	ReconciliationSourceUpload ReconciliationSource = "upload"
+)
+
+type ReconciliationSummary struct {
+	ProcessorRecords  int `bson:"processorRecords" json:"processorRecords"`
+	LedgerRecords     int `bson:"ledgerRecords" json:"ledgerRecords"`
+	Matched           int `bson:"matched" json:"matched"`
+	MissingInLedger   int `bson:"missingInLedger" json:"missingInLedger"`
+	MissingAtProcessor int `bson:"missingAtProcessor" json:"missingAtProcessor"`
+	Duplicates        int `bson:"duplicates" json:"duplicates"`
+	AmountMismatches  int `bson:"amountMismatches" json:"amountMismatches"`
+	StatusMismatches  int `bson:"statusMismatches" json:"statusMismatches"`
+}
+
+// A comparison of our transactions against a processor's records for a period.
+type ReconciliationRun struct {
+	Provider 	 string 					`bson:"provider" json:"provider" validate:"required,oneof=flutterwave chimoney"`
+	Source 		 ReconciliationSource 		`bson:"source" json:"source" validate:"required"`
+	FileName 	 *string 					`bson:"fileName" json:"fileName"`
+	PeriodStart  time.Time 					`bson:"periodStart" json:"periodStart" validate:"required"`
+	PeriodEnd 	 time.Time 					`bson:"periodEnd" json:"periodEnd" validate:"required"`
+	Status 		 ReconciliationRunStatus 	`bson:"status" json:"status"`
+	Summary 	 ReconciliationSummary 		`bson:"summary" json:"summary"`
+	Error 		 *string 					`bson:"error" json:"error"`
+	InitiatedBy  string 					`bson:"initiatedBy" json:"initiatedBy" validate:"required"`
+	CompletedAt  *time.Time 				`bson:"completedAt" json:"completedAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (run ReconciliationRun) ParseModel() any {
+	if run.ID == "" {
+		run.CreatedAt = time.Now()
+		run.ID = utils.GenerateUUIDString()
+	}
+	run.UpdatedAt = time.Now()
+	return &run
+}
+
+type ReconciliationDiscrepancy string
+
+const (
+	MissingInLedger    ReconciliationDiscrepancy = "missing_in_ledger"
+	MissingAtProcessor ReconciliationDiscrepancy = "missing_at_processor"
+	DuplicateRecord    ReconciliationDiscrepancy = "duplicate"
+	AmountMismatch     ReconciliationDiscrepancy = "amount_mismatch"
+	StatusMismatch     ReconciliationDiscrepancy = "status_mismatch"
+)
+
+type ReconciliationResolution string
+
+const (
+	ReconciliationItemOpen     ReconciliationResolution = "open"
+	ReconciliationItemResolved ReconciliationResolution = "resolved"
+	ReconciliationItemIgnored  ReconciliationResolution = "ignored"
+)
+
+// A single discrepancy found in a run that ops need to look into.
+type ReconciliationItem struct {
+	RunID 			 string 					 `bson:"runID" json:"runID" validate:"required"`
+	Discrepancy 	 ReconciliationDiscrepancy 	 `bson:"discrepancy" json:"discrepancy" validate:"required"`
+	Reference 		 string 					 `bson:"reference" json:"reference"`
+	TransactionIDs 	 []string 					 `bson:"transactionIDs" json:"transactionIDs"`
+	ProcessorAmount  *money.Money 				 `bson:"processorAmount" json:"processorAmount"`
+	LedgerAmount 	 *money.Money 				 `bson:"ledgerAmount" json:"ledgerAmount"`
+	ProcessorStatus  *string 					 `bson:"processorStatus" json:"processorStatus"`
+	LedgerStatus 	 *TransactionStatus 		 `bson:"ledgerStatus" json:"ledgerStatus"`
+	Occurrences 	 int 						 `bson:"occurrences" json:"occurrences"`
+	Resolution 		 ReconciliationResolution 	 `bson:"resolution" json:"resolution"`
+	ResolutionNote 	 *string 					 `bson:"resolutionNote" json:"resolutionNote"`
+	ResolvedBy 		 *string 					 `bson:"resolvedBy" json:"resolvedBy"`
+	ResolvedAt 		 *time.Time 				 `bson:"resolvedAt" json:"resolvedAt"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (item ReconciliationItem) ParseModel() any {
+	if item.ID == "" {
+		item.CreatedAt = time.Now()
+		item.ID = utils.GenerateUUIDString()
+	}
+	item.UpdatedAt = time.Now()
+	return &item
+}
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/utils"
+)
+
+// A signed in device. Each session has one refresh token at a time which is replaced every time it is used.
+type Session struct {
+	UserID 				string 		`bson:"userID" json:"-" validate:"required"`
+	DeviceID 			string 		`bson:"deviceID" json:"deviceID" validate:"required"`
+	UserAgent 			string 		`bson:"userAgent" json:"userAgent"`
+	AppVersion 			string 		`bson:"appVersion" json:"appVersion"`
+	IPAddress 			string 		`bson:"ipAddress" json:"ipAddress"`
+	Location 			SessionLocation `bson:"location" json:"location"`
+	RefreshTokenHash 	string 		`bson:"refreshTokenHash" json:"-"`
+	// incremented every time the refresh token is rotated
+	Generation 			int 		`bson:"generation" json:"-"`
+	ExpiresAt 			time.Time 	`bson:"expiresAt" json:"expiresAt"`
+	LastUsedAt 			time.Time 	`bson:"lastUsedAt" json:"lastUsedAt"`
+	RevokedAt 			*time.Time 	`bson:"revokedAt" json:"revokedAt"`
+	RevokedReason 		*string 	`bson:"revokedReason" json:"revokedReason"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Where the session was started, as reported by the proxy in front of the api. Empty when it is not known.
+type SessionLocation struct {
+	Country string `bson:"country" json:"country"`
+	City 	string `bson:"city" json:"city"`
+}
+
+func (session Session) ParseModel() any {
+	if session.ID == "" {
+		session.CreatedAt = time.Now()
+		session.ID = utils.GenerateUUIDString()
+	}
+	session.UpdatedAt = time.Now()
+	return &session
+}
+
+func (session *Session) Active() bool {
+	return session.RevokedAt == nil && time.Now().Before(session.ExpiresAt)
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"time"
 
+	"kego.com/application/money"
 	"kego.com/application/utils"
 )
 
@@ -16,6 +17,14 @@ const (
 	FlutterwaveDebitLocal         TransactionIntent = "flutterwave_debit_local"
 )
 
+type TransactionStatus string
+
+const (
+	TransactionPending    TransactionStatus = "pending"
+	TransactionSuccessful TransactionStatus = "successful"
+	TransactionFailed     TransactionStatus = "failed"
+)
+
 type DeviceInfo struct {
 	IPAddress  string    `bson:"ipAddress" json:"ipAddress" validate:"required,ip"`
 	DeviceID   string    `bson:"deviceID" json:"deviceID" validate:"required"`
@@ -40,13 +49,14 @@ type TransactionRecepient struct {
 
 type Transaction struct {
 	TransactionReference string               `bson:"transactionReference" json:"transactionReference" validate:"required"`
-	Amount               uint64               `bson:"amount" json:"amount" validate:"required"`
-	AmountInNGN          uint64          	  `bson:"amountInNGN" json:"amountInNGN" validate:"required"`
-	Fee          		 uint64          	  `bson:"fee" json:"fee" validate:"required"`
-	ProcessorFee         uint64          	  `bson:"processorFee" json:"processorFee" validate:"required"`
-	AmountInUSD          *uint64              `bson:"amountInUSD" json:"amountInUSD" validate:"required"`
-	Currency             string               `bson:"currency" json:"currency" validate:"iso4217"`
-	ProcessorFeeCurrency string               `bson:"processorFeeCurrency" json:"processorFeeCurrency" validate:"iso4217"`
+	Amount               money.Money          `bson:"amount" json:"amount" validate:"required"`
+	AmountInNGN          money.Money          `bson:"amountInNGN" json:"amountInNGN" validate:"required"`
+	Fee          		 money.Money          `bson:"fee" json:"fee" validate:"required"`
+	ProcessorFee         money.Money          `bson:"processorFee" json:"processorFee" validate:"required"`
+	PricingPlan          *AppliedPricingPlan  `bson:"pricingPlan" json:"pricingPlan"`
+	AmountInUSD          *money.Money         `bson:"amountInUSD" json:"amountInUSD"`
+	ExchangeRate         *string              `bson:"exchangeRate" json:"exchangeRate"` // exact decimal, naira per unit of the amount's currency
+	ExchangeRateSnapshotID *string            `bson:"exchangeRateSnapshotID" json:"exchangeRateSnapshotID"`
 	WalletID             string               `bson:"walletID" json:"walletID" validate:"required"`
 	UserID               string               `bson:"userID" json:"userID" validate:"required"`
 	BusinessID           *string              `bson:"businessID" json:"businessID"`
@@ -54,9 +64,13 @@ type Transaction struct {
 	MetaData          	 any               	  `bson:"metadata" json:"metadata" validate:"required"`
 	Location             Location          	  `bson:"location" json:"location" validate:"required"`
 	Intent               TransactionIntent 	  `bson:"intent" json:"intent" validate:"required"`
+	Status               TransactionStatus 	  `bson:"status" json:"status"`
 	DeviceInfo           DeviceInfo        	  `bson:"deviceInfo" json:"deviceInfo" validate:"required"`
 	Sender               TransactionSender 	  `bson:"transactionSender" json:"transactionSender" validate:"required"`
 	Recepient            TransactionRecepient `bson:"transactionRcepient" json:"transactionRcepient" validate:"required"`
+	APIKeyID             *string              `bson:"apiKeyID" json:"apiKeyID"` // set when a business's systems made the payout with an API key
+	LockedFundsID        *string              `bson:"lockedFundsID" json:"lockedFundsID"` // the wallet lock holding the payout's funds until it settles
+	SettledAt            *time.Time           `bson:"settledAt" json:"settledAt"`
 
 	ID        string    `bson:"_id" json:"id"`
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
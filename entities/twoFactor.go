@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"kego.com/application/money"
+	"kego.com/application/utils"
+)
+
+// A user's TOTP two-factor authentication settings. The secret is stored encrypted
+// and the recovery codes are stored hashed, so neither is ever sent back to the client.
+type TwoFactor struct {
+	UserID 			string 			`bson:"userID" json:"-" validate:"required"`
+	Secret 			string 			`bson:"secret" json:"-"`
+	Enabled 		bool 			`bson:"enabled" json:"enabled"`
+	EnabledAt 		*time.Time 		`bson:"enabledAt" json:"enabledAt"`
+	// the time step of the last code accepted, so a code cannot be used twice
+	LastUsedStep 	int64 			`bson:"lastUsedStep" json:"-"`
+	RecoveryCodes 	[]string 		`bson:"recoveryCodes" json:"-"`
+	// payouts of this amount or more need a code. nil means payouts never do.
+	PayoutThreshold *money.Money 	`bson:"payoutThreshold" json:"payoutThreshold"`
+
+	ID        string    `bson:"_id" json:"id"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (twoFactor TwoFactor) ParseModel() any {
+	if twoFactor.ID == "" {
+		twoFactor.CreatedAt = time.Now()
+		twoFactor.ID = utils.GenerateUUIDString()
+	}
+	twoFactor.UpdatedAt = time.Now()
+	return &twoFactor
+}
@@ -15,14 +15,18 @@ type User struct {
 	Phone             				PhoneNumber  `bson:"phone" json:"phone"`
 	Password          				string       `bson:"password" json:"-" validate:"password"`
 	TransactionPin    				string       `bson:"transactionPin" json:"-" validate:"password"`
+	TransactionPinResetAt 			*time.Time 	 `bson:"transactionPinResetAt" json:"transactionPinResetAt"` // payouts are paused for a while after a forgotten pin is reset
 	UserAgent        				string    	 `bson:"userAgent" json:"userAgent" validate:"user_agent,required"`
 	DeviceID          				string       `bson:"deviceID" json:"deviceID"`
 	AppVersion          			string       `bson:"appVersion" json:"appVersion"`
 	WalletID  						string    	 `bson:"walletID" json:"walletID"`
 	KYCCompleted   					bool         `bson:"kycCompleted" json:"kycCompleted"`
+	KYCTier   						KYCTier      `bson:"kycTier" json:"kycTier"`
 	EmailVerified     				bool         `bson:"emailVerified" json:"emailVerified"`
 	AccountRestricted 				bool         `bson:"accountRestricted" json:"accountRestricted"`
 	Deactivated 					bool         `bson:"deactivated" json:"deactivated"`
+	ErasedAt 						*time.Time 	 `bson:"erasedAt" json:"erasedAt"` // set once the user's personal data has been erased at their request
+	Admin 							bool         `bson:"admin" json:"-"`
 	BVN		  		  				string 	  	 `bson:"bvn" json:"bvn" validate:"required"`
 	Gender		  		  			string 	  	 `bson:"gender" json:"gender"`
 	DOB		  		  				string 	  	 `bson:"dob" json:"dob"`
@@ -3,11 +3,12 @@ package entities
 import (
 	"time"
 
+	"kego.com/application/money"
 	"kego.com/application/utils"
 )
 
 type LockedFunds struct {
-	Amount               uint64            	`bson:"amount" json:"amount" validate:"required"`
+	Amount               money.Money        `bson:"amount" json:"amount" validate:"required"`
 	Reason           	 TransactionIntent  `bson:"reason" json:"reason" validate:"required"`
 	LockedFundsID        string    		  	`bson:"lockedFundsID" json:"lockedFundsID" validate:"required"`
 	LockedAt 			 time.Time 			`bson:"lockedAt" json:"lockedAt" validate:"required"`
@@ -18,8 +19,8 @@ type Wallet struct {
 	BusinessID      	*string   		 `bson:"businessID" json:"businessID"`
 	BusinessName      	*string   		 `bson:"businessName" json:"businessName"`
 	Frozen          	bool     		 `bson:"frozen" json:"frozen"`
-	LedgerBalance 		uint64   		 `bson:"ledgerBalance" json:"ledgerBalance"`
-	Balance         	uint64    	 	 `bson:"balance" json:"balance"`
+	LedgerBalance 		money.Money   	 `bson:"ledgerBalance" json:"ledgerBalance"`
+	Balance         	money.Money    	 `bson:"balance" json:"balance"`
 	Currency         	string   		 `bson:"currency" json:"currency" validate:"iso4217"`
 	LockedFundsLog      []LockedFunds    `bson:"lockedFundsLog" json:"lockedFundsLog"`
 
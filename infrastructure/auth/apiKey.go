@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+const apiKeyPrefix = "kgp_"
+
+// GenerateAPIKey returns a new business API key, the part of it that is safe to show again and the hash to store.
+func GenerateAPIKey() (key string, displayPrefix string, hash string, err error) {
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = fmt.Sprintf("%s%s", apiKeyPrefix, base64.RawURLEncoding.EncodeToString(secret))
+	return key, key[:len(apiKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// API keys are as random as refresh tokens so they are hashed the same way.
+func HashAPIKey(key string) string {
+	return HashRefreshToken(key)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"kego.com/application/constants"
+	"kego.com/infrastructure/database/repository/cache"
+)
+
+// An AttemptGuard slows down and then stops the guessing of a secret such as a password or an OTP.
+// Failures are counted for the account being guessed and for the IP address the guesses come from, so
+// spreading guesses over many accounts or many addresses does not get around it.
+type AttemptGuard struct {
+	Name 				string
+	MaxAccountFailures 	int64
+	MaxIPFailures 		int64
+}
+
+var (
+	LoginGuard = AttemptGuard{
+		Name: "login",
+		MaxAccountFailures: constants.MAX_ACCOUNT_LOGIN_FAILURES,
+		MaxIPFailures: constants.MAX_IP_LOGIN_FAILURES,
+	}
+	OTPGuard = AttemptGuard{
+		Name: "otp",
+		MaxAccountFailures: constants.MAX_ACCOUNT_OTP_FAILURES,
+		MaxIPFailures: constants.MAX_IP_OTP_FAILURES,
+	}
+	// codes from an authenticator app or recovery codes asked for by signed in users
+	TwoFactorGuard = AttemptGuard{
+		Name: "2fa",
+		MaxAccountFailures: constants.MAX_ACCOUNT_TWO_FACTOR_FAILURES,
+		MaxIPFailures: constants.MAX_IP_TWO_FACTOR_FAILURES,
+	}
+)
+
+type AttemptStatus struct {
+	RetryAfter time.Duration
+	Locked 	   bool
+	// set only by the failure that locked the account so the user is told about it once
+	AccountJustLocked bool
+	// replaces the default message, e.g. when an otp was requested again too soon
+	reason string
+}
+
+// Message is what the user is told when they have to wait.
+func (status AttemptStatus) Message() string {
+	wait := status.RetryAfter.Round(time.Second)
+	if status.reason != "" {
+		return fmt.Sprintf("%s. Try again in %s", status.reason, wait)
+	}
+	if status.Locked {
+		return fmt.Sprintf("Too many failed attempts. This has been locked for %s", wait)
+	}
+	return fmt.Sprintf("Too many failed attempts. Try again in %s", wait)
+}
+
+func (guard AttemptGuard) key(scope string, id string, kind string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", guard.Name, scope, id, kind)
+}
+
+// Check returns how long the caller has to wait before another attempt, or nil if they can try now.
+// account may be empty when the account being guessed is not known.
+func (guard AttemptGuard) Check(account string, ipAddress string) *AttemptStatus {
+	var status *AttemptStatus
+	for scope, id := range guard.scopes(account, ipAddress) {
+		for _, kind := range []string{"locked", "backoff"} {
+			ttl := cache.Cache.TimeToLive(guard.key(scope, id, kind))
+			if ttl == 0 {
+				continue
+			}
+			if status == nil {
+				status = &AttemptStatus{}
+			}
+			if ttl > status.RetryAfter {
+				status.RetryAfter = ttl
+			}
+			status.Locked = status.Locked || kind == "locked"
+		}
+	}
+	return status
+}
+
+// Fail records a failed attempt. After a few free attempts each further one has to wait twice as long
+// as the one before, and the account or IP address is locked once it reaches its limit.
+func (guard AttemptGuard) Fail(account string, ipAddress string) AttemptStatus {
+	status := AttemptStatus{}
+	limits := map[string]int64{
+		"account": guard.MaxAccountFailures,
+		"ip": guard.MaxIPFailures,
+	}
+	for scope, id := range guard.scopes(account, ipAddress) {
+		failures, err := cache.Cache.Increment(guard.key(scope, id, "failures"), constants.AUTH_ATTEMPT_WINDOW)
+		if err != nil {
+			continue
+		}
+		if failures >= limits[scope] {
+			cache.Cache.CreateEntry(guard.key(scope, id, "locked"), 1, constants.AUTH_LOCK_DURATION)
+			cache.Cache.DeleteOne(guard.key(scope, id, "failures"))
+			status.Locked = true
+			status.RetryAfter = constants.AUTH_LOCK_DURATION
+			status.AccountJustLocked = status.AccountJustLocked || scope == "account"
+			continue
+		}
+		if failures <= constants.FREE_AUTH_ATTEMPTS {
+			continue
+		}
+		wait := backoff(failures - constants.FREE_AUTH_ATTEMPTS)
+		cache.Cache.CreateEntry(guard.key(scope, id, "backoff"), 1, wait)
+		if wait > status.RetryAfter {
+			status.RetryAfter = wait
+		}
+	}
+	return status
+}
+
+// Succeed forgets the failures on an account once the right secret is given. Failures from the IP address are kept.
+func (guard AttemptGuard) Succeed(account string) {
+	cache.Cache.DeleteOne(guard.key("account", account, "failures"))
+	cache.Cache.DeleteOne(guard.key("account", account, "backoff"))
+}
+
+// Unlock lifts a lock on the account once the user has proven who they are another way.
+func (guard AttemptGuard) Unlock(account string) {
+	guard.Succeed(account)
+	cache.Cache.DeleteOne(guard.key("account", account, "locked"))
+}
+
+func (guard AttemptGuard) scopes(account string, ipAddress string) map[string]string {
+	scopes := map[string]string{}
+	if account != "" {
+		scopes["account"] = account
+	}
+	if ipAddress != "" {
+		scopes["ip"] = ipAddress
+	}
+	return scopes
+}
+
+// backoff is one second for the first attempt past the free ones and doubles from there.
+func backoff(attempts int64) time.Duration {
+	wait := time.Second
+	for i := int64(1); i < attempts; i++ {
+		wait *= 2
+		if wait >= constants.AUTH_ATTEMPT_MAX_BACKOFF {
+			return constants.AUTH_ATTEMPT_MAX_BACKOFF
+		}
+	}
+	return wait
+}
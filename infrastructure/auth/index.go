@@ -2,68 +2,31 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
-	"kego.com/infrastructure/cryptography"
+	"kego.com/application/repository"
 	"kego.com/infrastructure/database/repository/cache"
 	"kego.com/infrastructure/logger"
 )
 
 
-const otpChars = "1234567890"
-
-func GenerateOTP(length int, email string) (*string, error) {
-	buffer := make([]byte, length)
-	_, err := rand.Read(buffer)
+func GenerateAuthToken(claimsData ClaimsData) (*string, error) {
+	key, err := KeySet.signing()
 	if err != nil {
 		return nil, err
 	}
-	otpCharsLength := len(otpChars)
-	for i := 0; i < length; i++ {
-		buffer[i] = otpChars[int(buffer[i])%otpCharsLength]
-	}
-	otp := string(buffer)
-	otpSaved := saveOTP(email, otp)
-	if !otpSaved {
-		return nil, errors.New("could not save otp")
-	}
-	return &otp, nil
-}
-
-func saveOTP(email string, otp string) bool {
-	hashedOTP, err := cryptography.CryptoHahser.HashString(otp)
-	if err != nil {
-		logger.Error(errors.New("auth module error - error while saving otp"),logger.LoggerOptions{
-			Key: "error",
-			Data: err,
-		})
-		return false
-	}
-	return cache.Cache.CreateEntry(fmt.Sprintf("%s-otp", email), string(hashedOTP), 10*time.Minute) // otp is valid for 10 mins
-}
-
-func VerifyOTP(key string, otp string) (string, bool) {
-	data := cache.Cache.FindOne(fmt.Sprintf("%s-otp", key))
-	if data == nil {
-		logger.Info(fmt.Sprintf("%s otp not found", key),)
-		return "this otp has expired", false
-	}
-	success := cryptography.CryptoHahser.VerifyData(*data, otp)
-	if !success {
-		return "wrong otp provided", false
-	}
-	cache.Cache.DeleteOne(fmt.Sprintf("%s-otp", key))
-	return "", true
-}
-
-func GenerateAuthToken(claimsData ClaimsData) (*string, error) {
-	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
 		"iss":        os.Getenv("JWT_ISSUER"),
 		"userID":     claimsData.UserID,
+		"sessionID":  claimsData.SessionID,
 		"exp":        claimsData.ExpiresAt,
 		"email":      claimsData.Email,
 		"phone":      claimsData.Phone,
@@ -73,7 +36,9 @@ func GenerateAuthToken(claimsData ClaimsData) (*string, error) {
 		"deviceID":   claimsData.DeviceID,
 		"userAgent": claimsData.UserAgent,
 		"appVersion": claimsData.AppVersion,
-	}).SignedString([]byte(os.Getenv("JWT_SIGNING_KEY")))
+	})
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +47,12 @@ func GenerateAuthToken(claimsData ClaimsData) (*string, error) {
 
 func DecodeAuthToken(tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SIGNING_KEY")), nil
+		// only accept the algorithm we sign with, so a token cannot pick a weaker one
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		keyID, _ := t.Header["kid"].(string)
+		return KeySet.verificationKey(keyID)
 	})
 	if err != nil {
 		if err == jwt.ErrSignatureInvalid {
@@ -104,16 +74,66 @@ func DecodeAuthToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
-func SignOutUser(ctx any, id string, reason string){
+// AccessTokenCacheKey is where the current access token of a session is cached. A token is only accepted while it is cached.
+func AccessTokenCacheKey(sessionID string) string {
+	return fmt.Sprintf("%s-access-token", sessionID)
+}
+
+// GenerateRefreshToken creates a refresh token for the session. Only the hash should be stored.
+func GenerateRefreshToken(sessionID string) (token string, hash string, err error) {
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return fmt.Sprintf("%s.%s", sessionID, encoded), HashRefreshToken(encoded), nil
+}
+
+// ParseRefreshToken splits a refresh token into the session it belongs to and the hash of its secret.
+func ParseRefreshToken(token string) (sessionID string, hash string, err error) {
+	sessionID, secret, found := strings.Cut(token, ".")
+	if !found || sessionID == "" || secret == "" {
+		return "", "", errors.New("invalid refresh token")
+	}
+	return sessionID, HashRefreshToken(secret), nil
+}
+
+// Refresh tokens are long random values so a fast hash is enough to keep them useless if the database leaks.
+func HashRefreshToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignOutUser revokes the session so neither its access token nor its refresh token can be used again.
+func SignOutUser(ctx any, sessionID string, reason string){
 	logger.Info("system user signout initiated", logger.LoggerOptions{
 		Key: "reason",
 		Data: reason,
+	}, logger.LoggerOptions{
+		Key: "sessionID",
+		Data: sessionID,
+	})
+	now := time.Now()
+	_, err := repository.SessionRepo().UpdateManyWithOperator(map[string]interface{}{
+		"_id": sessionID,
+		"revokedAt": nil,
+	}, map[string]any{
+		"$set": map[string]any{
+			"revokedAt": now,
+			"revokedReason": reason,
+			"updatedAt": now,
+		},
 	})
-	deleted := cache.Cache.DeleteOne(id)
-	if !deleted {
-		logger.Error(errors.New("failed to sign out user"), logger.LoggerOptions{
-			Key: "id",
-			Data: id,
+	if err != nil {
+		logger.Error(errors.New("failed to revoke session"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "sessionID",
+			Data: sessionID,
 		})
 	}
-}
\ No newline at end of file
+	// nothing is deleted if the access token has already expired
+	cache.Cache.DeleteOne(AccessTokenCacheKey(sessionID))
+}
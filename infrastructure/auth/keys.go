@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kego.com/infrastructure/logger"
+)
+
+const (
+	keyIDLayout = "20060102T150405Z"
+	// a new key only starts signing tokens once every instance has had time to load it for verification
+	keyActivationDelay = 5 * time.Minute
+	keyReloadInterval  = time.Minute
+	keyBits            = 2048
+)
+
+var ErrUnknownKey = errors.New("token was signed with an unknown key")
+
+type signingKey struct {
+	ID         string
+	CreatedAt  time.Time
+	PrivateKey *rsa.PrivateKey
+}
+
+// The keys tokens are signed and verified with. Each key is a PEM encoded RSA private key in the keys folder
+// named after its key id, which is the time it was created. Every key in the folder is accepted when verifying,
+// and the newest key that has been active for long enough signs new tokens.
+type keySet struct {
+	mu   sync.RWMutex
+	dir  string
+	keys []signingKey
+}
+
+var KeySet = &keySet{}
+
+// InitialiseKeySet loads the keys in JWT_KEYS_DIR and keeps them up to date as keys are rotated.
+// Outside of release mode a key is created if there are none, so the api can run locally without setup.
+func InitialiseKeySet() error {
+	KeySet.dir = KeysDir()
+	if os.Getenv("GIN_MODE") != "release" {
+		keys, err := loadKeys(KeySet.dir)
+		if err == nil && len(keys) == 0 {
+			if _, err = GenerateKey(KeySet.dir, time.Now().Add(-keyActivationDelay)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := KeySet.reload(); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(keyReloadInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := KeySet.reload(); err != nil {
+				logger.Error(errors.New("could not reload jwt keys, keeping the keys already loaded"), logger.LoggerOptions{
+					Key: "error",
+					Data: err,
+				})
+			}
+		}
+	}()
+	return nil
+}
+
+// KeysDir is the folder the signing keys are kept in.
+func KeysDir() string {
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		return dir
+	}
+	return "keys"
+}
+
+func (ks *keySet) reload() error {
+	keys, err := loadKeys(ks.dir)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no jwt keys found in %s", ks.dir)
+	}
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+// signing returns the newest key that has been active for long enough, or the oldest key if none has.
+func (ks *keySet) signing() (*signingKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(ks.keys) == 0 {
+		return nil, errors.New("no jwt keys have been loaded")
+	}
+	activeBefore := time.Now().Add(-keyActivationDelay)
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if ks.keys[i].CreatedAt.Before(activeBefore) {
+			return &ks.keys[i], nil
+		}
+	}
+	return &ks.keys[0], nil
+}
+
+func (ks *keySet) verificationKey(keyID string) (*rsa.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for _, key := range ks.keys {
+		if key.ID == keyID {
+			return &key.PrivateKey.PublicKey, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS lists the public half of every key so other services can verify our tokens.
+func (ks *keySet) JWKS() JSONWebKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range ks.keys {
+		publicKey := key.PrivateKey.PublicKey
+		set.Keys = append(set.Keys, JSONWebKey{
+			KeyType: "RSA",
+			Use: "sig",
+			Algorithm: "RS256",
+			KeyID: key.ID,
+			Modulus: base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		})
+	}
+	return set
+}
+
+// loadKeys reads every key in the folder, oldest first.
+func loadKeys(dir string) ([]signingKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	keys := []signingKey{}
+	for _, file := range files {
+		keyID := strings.TrimSuffix(filepath.Base(file), ".pem")
+		createdAt, err := time.Parse(keyIDLayout, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s is not named after the time it was created", file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := parsePrivateKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("could not read jwt key %s: %w", file, err)
+		}
+		keys = append(keys, signingKey{
+			ID: keyID,
+			CreatedAt: createdAt,
+			PrivateKey: privateKey,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func parsePrivateKey(content []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an rsa key")
+	}
+	return rsaKey, nil
+}
+
+// GenerateKey adds a new key to the folder and returns its id. It starts signing tokens once the activation delay has passed.
+func GenerateKey(dir string, now time.Time) (string, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	keyID := now.UTC().Format(keyIDLayout)
+	content := pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	file, err := os.OpenFile(filepath.Join(dir, keyID+".pem"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = file.Write(content); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+// PruneKeys removes all but the newest keep keys and returns the ids of the keys removed.
+// Tokens signed with a removed key stop being accepted, so keep at least the key that was signing before the last rotation.
+func PruneKeys(dir string, keep int) ([]string, error) {
+	if keep < 2 {
+		return nil, errors.New("at least two keys must be kept so tokens signed before the last rotation stay valid")
+	}
+	keys, err := loadKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for i := 0; i < len(keys)-keep; i++ {
+		if err = os.Remove(filepath.Join(dir, keys[i].ID+".pem")); err != nil {
+			return removed, err
+		}
+		removed = append(removed, keys[i].ID)
+	}
+	return removed, nil
+}
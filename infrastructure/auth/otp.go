@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"kego.com/application/constants"
+	"kego.com/application/repository"
+	"kego.com/entities"
+	"kego.com/infrastructure/cryptography"
+	"kego.com/infrastructure/database/repository/cache"
+	"kego.com/infrastructure/logger"
+)
+
+// What an otp was sent for. An otp can only be used for the purpose it was sent for.
+type OTPPurpose string
+
+const (
+	OTPEmailVerification 	OTPPurpose = "email_verification"
+	OTPPasswordReset 		OTPPurpose = "password_reset"
+	OTPPinReset 			OTPPurpose = "pin_reset"
+	OTPAccountUnlock 		OTPPurpose = "account_unlock"
+	OTPLoginStepUp 			OTPPurpose = "login_step_up"
+	OTPPayoutConfirmation 	OTPPurpose = "payout_confirmation"
+)
+
+const otpChars = "1234567890"
+
+const otpLength = 6
+
+func otpKey(purpose OTPPurpose, subject string) string {
+	return fmt.Sprintf("%s-%s-otp", purpose, subject)
+}
+
+// GenerateOTP creates an otp for the purpose and saves it for the subject, the email or user id it is sent to.
+// Requesting a new one replaces the last. blocked is set when the last one was sent too recently.
+func GenerateOTP(purpose OTPPurpose, subject string) (otp *string, blocked *AttemptStatus, err error) {
+	key := otpKey(purpose, subject)
+	if wait := cache.Cache.TimeToLive(fmt.Sprintf("%s-cooldown", key)); wait != 0 {
+		return nil, &AttemptStatus{
+			RetryAfter: wait,
+			reason: "An otp was sent recently",
+		}, nil
+	}
+	buffer := make([]byte, otpLength)
+	_, err = rand.Read(buffer)
+	if err != nil {
+		return nil, nil, err
+	}
+	otpCharsLength := len(otpChars)
+	for i := 0; i < otpLength; i++ {
+		buffer[i] = otpChars[int(buffer[i])%otpCharsLength]
+	}
+	code := string(buffer)
+	if !saveOTP(key, code) {
+		return nil, nil, errors.New("could not save otp")
+	}
+	cache.Cache.CreateEntry(fmt.Sprintf("%s-cooldown", key), 1, constants.OTP_RESEND_COOLDOWN)
+	auditOTP(purpose, subject, entities.OTPIssued, "")
+	return &code, nil, nil
+}
+
+func saveOTP(key string, otp string) bool {
+	hashedOTP, err := cryptography.CryptoHahser.HashString(otp)
+	if err != nil {
+		logger.Error(errors.New("auth module error - error while saving otp"),logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		return false
+	}
+	// a new otp gets a fresh set of attempts
+	cache.Cache.DeleteOne(fmt.Sprintf("%s-attempts", key))
+	return cache.Cache.CreateEntry(key, string(hashedOTP), constants.OTP_TTL)
+}
+
+// VerifyOTP checks an otp sent to the subject for the purpose and uses it up if it is right.
+// Each otp can only be guessed a few times before it is thrown away. Guesses are counted before they are checked
+// so a burst of them cannot get past the limit. Wrong otps are also counted by OTPGuard against the subject and
+// the IP address they come from. blocked is set when the caller has to wait before trying again.
+func VerifyOTP(purpose OTPPurpose, subject string, otp string, ipAddress string) (msg string, success bool, blocked *AttemptStatus) {
+	key := otpKey(purpose, subject)
+	attemptsKey := fmt.Sprintf("%s-attempts", key)
+	if status := OTPGuard.Check(key, ipAddress); status != nil {
+		return status.Message(), false, status
+	}
+	attempts, err := cache.Cache.Increment(attemptsKey, constants.OTP_TTL)
+	if err != nil {
+		return "could not verify otp, please try again", false, nil
+	}
+	if attempts > constants.OTP_MAX_VERIFY_ATTEMPTS {
+		if cache.Cache.DeleteOne(key) {
+			auditOTP(purpose, subject, entities.OTPInvalidated, ipAddress)
+		}
+		return "too many wrong otps, request a new one", false, nil
+	}
+	data := cache.Cache.FindOne(key)
+	if data == nil {
+		logger.Info(fmt.Sprintf("%s otp not found", key),)
+		return "this otp has expired", false, nil
+	}
+	success = cryptography.CryptoHahser.VerifyData(*data, otp)
+	if !success {
+		status := OTPGuard.Fail(key, ipAddress)
+		if status.Locked || attempts >= constants.OTP_MAX_VERIFY_ATTEMPTS {
+			cache.Cache.DeleteOne(key)
+			cache.Cache.DeleteOne(attemptsKey)
+			auditOTP(purpose, subject, entities.OTPInvalidated, ipAddress)
+		}
+		if status.Locked {
+			return status.Message(), false, &status
+		}
+		if attempts >= constants.OTP_MAX_VERIFY_ATTEMPTS {
+			return "too many wrong otps, request a new one", false, nil
+		}
+		return "wrong otp provided", false, nil
+	}
+	// only the request that takes the otp out of the cache gets to use it
+	consumed := cache.Cache.FindAndDeleteOne(key)
+	if consumed == nil || *consumed != *data {
+		return "this otp has expired", false, nil
+	}
+	OTPGuard.Succeed(key)
+	cache.Cache.DeleteOne(attemptsKey)
+	auditOTP(purpose, subject, entities.OTPConsumed, ipAddress)
+	return "", true, nil
+}
+
+// auditOTP records the otp being sent or used. A failure to record it is logged but does not stop the otp working.
+func auditOTP(purpose OTPPurpose, subject string, action entities.OTPAuditAction, ipAddress string) {
+	log := entities.OTPAuditLog{
+		Purpose: string(purpose),
+		Subject: subject,
+		Action: action,
+	}
+	if ipAddress != "" {
+		log.IPAddress = &ipAddress
+	}
+	_, err := repository.OTPAuditLogRepo().CreateOne(nil, log)
+	if err != nil {
+		logger.Error(errors.New("could not record otp audit log"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "purpose",
+			Data: purpose,
+		}, logger.LoggerOptions{
+			Key: "action",
+			Data: action,
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpIssuer = "Kego"
+	totpPeriod = 30
+	// codes from one step either side of now are accepted to allow for clock drift
+	totpSkew = 1
+	recoveryCodeChars = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+type TOTPEnrollment struct {
+	Secret 			string `json:"secret"`
+	ProvisioningURI string `json:"provisioningURI"`
+	// a png of the provisioning uri as a data uri, ready to show in an image tag
+	QRCode 			string `json:"qrCode"`
+}
+
+// GenerateTOTP creates a new TOTP secret for the account and everything needed to add it to an authenticator app.
+func GenerateTOTP(accountName string) (*TOTPEnrollment, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer: totpIssuer,
+		AccountName: accountName,
+		Period: totpPeriod,
+		Digits: otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	image, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = png.Encode(&buffer, image); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret: key.Secret(),
+		ProvisioningURI: key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+	}, nil
+}
+
+// ValidateTOTP checks the code against the secret and returns the time step it was generated for.
+// Codes for steps at or before lastUsedStep are rejected so a code cannot be used twice.
+func ValidateTOTP(secret string, code string, lastUsedStep int64) (int64, bool) {
+	now := time.Now()
+	current := now.Unix() / totpPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		if step <= lastUsedStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period: totpPeriod,
+			Digits: otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes creates one time codes the user can sign in with if they lose their authenticator.
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := []string{}
+	for i := 0; i < count; i++ {
+		buffer := make([]byte, 10)
+		if _, err := rand.Read(buffer); err != nil {
+			return nil, err
+		}
+		for j := range buffer {
+			buffer[j] = recoveryCodeChars[int(buffer[j])%len(recoveryCodeChars)]
+		}
+		codes = append(codes, string(buffer[:5])+"-"+string(buffer[5:]))
+	}
+	return codes, nil
+}
+
+// NormaliseRecoveryCode lets users type recovery codes without the dash or in capitals.
+func NormaliseRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != 10 {
+		return code
+	}
+	return code[:5] + "-" + code[5:]
+}
@@ -5,6 +5,7 @@ import "kego.com/entities"
 type ClaimsData struct {
     Issuer       string
     UserID       string
+    SessionID    string
     FirstName    string
     LastName     string
     Email        *string
@@ -1,3 +1,5 @@
 package cryptography
 
-var CryptoHahser Hasher = argonHasher{}
\ No newline at end of file
+var CryptoHahser Hasher = argonHasher{}
+
+var DataEncrypter Encrypter = aesEncrypter{}
\ No newline at end of file
@@ -3,4 +3,9 @@ package cryptography
 type Hasher interface{
 	HashString(data string) ([]byte, error)
 	VerifyData(hash string, data string) bool
-}
\ No newline at end of file
+}
+
+type Encrypter interface{
+	Encrypt(data string) (string, error)
+	Decrypt(data string) (string, error)
+}
@@ -0,0 +1,152 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"kego.com/application/utils"
+	"kego.com/entities"
+	"kego.com/infrastructure/logger"
+)
+
+// Amounts used to be stored as bare numbers of minor units, with the currency kept in a separate field.
+// They are now stored as {amount, currency} documents. migrateAmounts rewrites any documents still in the
+// old shape so they can be read again. Documents already in the new shape are left alone, so it is safe to
+// run on every start.
+func migrateAmounts(db *mongo.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	migrations := []struct {
+		collection string
+		filter     bson.M
+		update     bson.M
+	}{{
+		collection: "Wallets",
+		filter: bson.M{"$or": bson.A{
+			bson.M{"balance": bson.M{"$type": "number"}},
+			bson.M{"ledgerBalance": bson.M{"$type": "number"}},
+			bson.M{"lockedFundsLog.amount": bson.M{"$type": "number"}},
+		}},
+		update: bson.M{
+			"balance": legacyAmount("$balance", "$currency"),
+			"ledgerBalance": legacyAmount("$ledgerBalance", "$currency"),
+			"lockedFundsLog": bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$lockedFundsLog", bson.A{}}},
+				"as": "lock",
+				"in": bson.M{"$mergeObjects": bson.A{"$$lock", bson.M{
+					"amount": legacyAmount("$$lock.amount", "$currency"),
+				}}},
+			}},
+		},
+	}, {
+		collection: "Transactions",
+		filter: bson.M{"$or": bson.A{
+			bson.M{"amount": bson.M{"$type": "number"}},
+			bson.M{"amountInNGN": bson.M{"$type": "number"}},
+			bson.M{"fee": bson.M{"$type": "number"}},
+			bson.M{"processorFee": bson.M{"$type": "number"}},
+			bson.M{"amountInUSD": bson.M{"$type": "number"}},
+			bson.M{"exchangeRate": bson.M{"$type": "number"}},
+		}},
+		update: bson.M{
+			"amount": legacyAmount("$amount", legacyTransactionCurrency()),
+			"amountInNGN": legacyAmount("$amountInNGN", "NGN"),
+			"fee": legacyAmount("$fee", "NGN"),
+			// processorFeeCurrency said USD for international payouts but the fee was always worked out in kobo
+			"processorFee": legacyAmount("$processorFee", "NGN"),
+			"amountInUSD": legacyAmount("$amountInUSD", "USD"),
+			"exchangeRate": legacyDecimal("$exchangeRate"),
+		},
+	}, {
+		// payouts made before statuses were recorded were never settled, so they are still pending until
+		// their processor says otherwise
+		collection: "Transactions",
+		filter: bson.M{"status": bson.M{"$in": bson.A{nil, ""}}},
+		update: bson.M{
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$intent", bson.A{entities.PaystackDVACredit, entities.FlutterwaveDVACredit}}},
+				entities.TransactionSuccessful,
+				entities.TransactionPending,
+			}},
+		},
+	}, {
+		collection: "InternationalPaymentQuotes",
+		filter: bson.M{"amount": bson.M{"$type": "number"}},
+		update: bson.M{
+			"amount": legacyAmount("$amount", "$currency"),
+			"amountInNGN": legacyAmount("$amountInNGN", "NGN"),
+			"processorFee": legacyAmount("$processorFee", "NGN"),
+			"fee": legacyAmount("$fee", "NGN"),
+			"totalAmount": legacyAmount("$totalAmount", "NGN"),
+			// was a float number of cents
+			"valueInUSD": legacyAmount("$valueInUSD", "USD"),
+			"rate": legacyDecimal("$rate"),
+		},
+	}}
+	for _, migration := range migrations {
+		result, err := db.Collection(migration.collection).UpdateMany(ctx, migration.filter, mongo.Pipeline{{{Key: "$set", Value: migration.update}}})
+		if err != nil {
+			logger.Error(errors.New("could not migrate stored amounts"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "collection",
+				Data: migration.collection,
+			})
+			// documents left in the old shape cannot be read, so do not serve requests against them
+			panic(err)
+		}
+		if result.ModifiedCount != 0 {
+			logger.Info("migrated stored amounts", logger.LoggerOptions{
+				Key: "collection",
+				Data: migration.collection,
+			}, logger.LoggerOptions{
+				Key: "documents",
+				Data: result.ModifiedCount,
+			})
+		}
+	}
+}
+
+// legacyAmount turns a bare number of minor units into a money document, rounding any that were kept as floats.
+// Currencies that were never recorded were naira, the only currency amounts were kept in before.
+func legacyAmount(field string, currency any) bson.M {
+	return bson.M{"$cond": bson.A{
+		isNumber(field),
+		bson.M{"amount": bson.M{"$toLong": bson.M{"$round": bson.A{field, 0}}}, "currency": bson.M{"$ifNull": bson.A{currency, "NGN"}}},
+		field,
+	}}
+}
+
+// legacyDecimal turns a float rate into the decimal string rates are now stored as.
+func legacyDecimal(field string) bson.M {
+	return bson.M{"$cond": bson.A{isNumber(field), bson.M{"$toString": field}, field}}
+}
+
+// International payouts recorded the currency symbol looked up from the destination country code, which
+// always came out empty. The currency is worked out again from the payout's intent and destination.
+func legacyTransactionCurrency() bson.M {
+	destinationCurrency := bson.A{}
+	for _, country := range []string{"NG", "IN", "US", "KE", "ZA", "GB", "CA", "GH"} {
+		destinationCurrency = append(destinationCurrency, bson.M{
+			"case": bson.M{"$eq": bson.A{"$transactionRcepient.country", country}},
+			"then": utils.CountryCodeToCurrencyCode(country),
+		})
+	}
+	return bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$currency", ""}}, ""}},
+		bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$intent", entities.ChimoneyDebitInternational}},
+			bson.M{"$switch": bson.M{"branches": destinationCurrency, "default": nil}},
+			"NGN",
+		}},
+		"$currency",
+	}}
+}
+
+func isNumber(field string) bson.M {
+	return bson.M{"$in": bson.A{bson.M{"$type": field}, bson.A{"double", "int", "long", "decimal"}}}
+}
@@ -18,6 +18,24 @@ var (
 	WalletModel *mongo.Collection
 	FrozenWalletLogModel *mongo.Collection
 	BusinessModel *mongo.Collection
+	InternationalPaymentQuoteModel *mongo.Collection
+	ExchangeRateSnapshotModel *mongo.Collection
+	PricingPlanModel *mongo.Collection
+	ReconciliationRunModel *mongo.Collection
+	ReconciliationItemModel *mongo.Collection
+	OutboxEventModel *mongo.Collection
+	DeadLetterEventModel *mongo.Collection
+	NotificationModel *mongo.Collection
+	NotificationPreferencesModel *mongo.Collection
+	DeviceModel *mongo.Collection
+	SessionModel *mongo.Collection
+	TwoFactorModel *mongo.Collection
+	OTPAuditLogModel *mongo.Collection
+	DataRequestModel *mongo.Collection
+	IdentityVerificationModel *mongo.Collection
+	BusinessMemberModel *mongo.Collection
+	PayoutApprovalModel *mongo.Collection
+	BusinessAPIKeyModel *mongo.Collection
 )
 
 func connectMongo() *context.CancelFunc {
@@ -39,6 +57,7 @@ func connectMongo() *context.CancelFunc {
 
 	db := client.Database(os.Getenv("DB_NAME"))
 	setUpIndexes(ctx, db)
+	migrateAmounts(db)
 
 	logger.Info("connected to mongodb successfully")
 	return &cancel
@@ -71,11 +90,162 @@ func setUpIndexes(ctx context.Context, db *mongo.Database) {
 	},{
 		Keys:    bson.D{{Key: "userID", Value: 1}},
 		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "kyb.registrationNumber", Value: 1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "kybStatus", Value: 1}, {Key: "kyb.submittedAt", Value: 1}},
+		Options: options.Index(),
 	}})
 
 	FrozenWalletLogModel = db.Collection("FrozenWalletLogs")
 
 	TransactionModel = db.Collection("Transactions")
+	TransactionModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "transactionReference", Value: 1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "intent", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	InternationalPaymentQuoteModel = db.Collection("InternationalPaymentQuotes")
+	InternationalPaymentQuoteModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+
+	ExchangeRateSnapshotModel = db.Collection("ExchangeRateSnapshots")
+	ExchangeRateSnapshotModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "fetchedAt", Value: -1}},
+		Options: options.Index(),
+	}})
+
+	PricingPlanModel = db.Collection("PricingPlans")
+	PricingPlanModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "code", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	}})
+
+	ReconciliationRunModel = db.Collection("ReconciliationRuns")
+	ReconciliationRunModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	}})
+
+	ReconciliationItemModel = db.Collection("ReconciliationItems")
+	ReconciliationItemModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "runID", Value: 1}, {Key: "resolution", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	OutboxEventModel = db.Collection("OutboxEvents")
+	OutboxEventModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "eventID", Value: 1}, {Key: "consumer", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+
+	DeadLetterEventModel = db.Collection("DeadLetterEvents")
+	DeadLetterEventModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	}})
+
+	NotificationModel = db.Collection("Notifications")
+	NotificationModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "read", Value: 1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "eventID", Value: 1}, {Key: "userID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+
+	NotificationPreferencesModel = db.Collection("NotificationPreferences")
+	NotificationPreferencesModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+
+	DeviceModel = db.Collection("Devices")
+	DeviceModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "deviceID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},{
+		Keys:    bson.D{{Key: "pushToken", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	SessionModel = db.Collection("Sessions")
+	SessionModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "deviceID", Value: 1}, {Key: "revokedAt", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	TwoFactorModel = db.Collection("TwoFactorSettings")
+	TwoFactorModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+
+	OTPAuditLogModel = db.Collection("OTPAuditLogs")
+	OTPAuditLogModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "subject", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	}})
+
+	DataRequestModel = db.Collection("DataRequests")
+	DataRequestModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "scheduledFor", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	IdentityVerificationModel = db.Collection("IdentityVerifications")
+	IdentityVerificationModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "documentNumberHash", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	BusinessMemberModel = db.Collection("BusinessMembers")
+	BusinessMemberModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "businessID", Value: 1}, {Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "email", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	PayoutApprovalModel = db.Collection("PayoutApprovals")
+	PayoutApprovalModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "businessID", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	},{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+		Options: options.Index(),
+	}})
+
+	BusinessAPIKeyModel = db.Collection("BusinessAPIKeys")
+	BusinessAPIKeyModel.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "keyHash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},{
+		Keys:    bson.D{{Key: "businessID", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index(),
+	}})
 	
 	logger.Info("mongodb indexes set up successfully")
 }
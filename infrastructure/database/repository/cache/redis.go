@@ -103,6 +103,32 @@ func (redisRepo *RedisRepository) DeleteOne(key string) bool {
 	return true
 }
 
+// FindAndDeleteOne returns the value at key and deletes it in one step, so only one caller can get it.
+func (redisRepo *RedisRepository) FindAndDeleteOne(key string) *string {
+	redisRepo.preRequest()
+	c, cancel := generateContext()
+	defer cancel()
+
+	result, err := redisRepo.Clinet.GetDel(c, key).Result()
+
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		logger.Error(errors.New("redis error occured while running FindAndDeleteOne"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "key",
+			Data: key,
+		})
+		return nil
+	}
+
+	logger.Info("redis FindAndDeleteOne completed")
+	return &result
+}
+
 func (redisRepo *RedisRepository) CreateInSet(key string, score float64, member interface{}) bool {
 	redisRepo.preRequest()
 	c, cancel := generateContext()
@@ -157,3 +183,70 @@ func (redisRepo *RedisRepository) FindSet(key string) *[]string {
 	val := result.Val()
 	return &val
 }
+
+// Increment adds one to the counter at key and returns the new count. The ttl is only set when the counter
+// is created so it counts over a fixed window.
+func (redisRepo *RedisRepository) Increment(key string, ttl time.Duration) (int64, error) {
+	return redisRepo.IncrementBy(key, 1, ttl)
+}
+
+// IncrementBy adds amount, which can be negative, to the counter at key and returns the new total. The ttl is only
+// set when the counter is created so it counts over a fixed window.
+func (redisRepo *RedisRepository) IncrementBy(key string, amount int64, ttl time.Duration) (int64, error) {
+	redisRepo.preRequest()
+	c, cancel := generateContext()
+	defer cancel()
+
+	count, err := redisRepo.Clinet.IncrBy(c, key, amount).Result()
+	if err != nil {
+		logger.Error(errors.New("redis error occured while running IncrementBy"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "key",
+			Data: key,
+		})
+		return 0, err
+	}
+	if count == amount {
+		err = redisRepo.Clinet.Expire(c, key, ttl).Err()
+		if err != nil {
+			logger.Error(errors.New("redis error occured while setting ttl in IncrementBy"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "key",
+				Data: key,
+			})
+			return count, err
+		}
+	}
+
+	logger.Info("redis IncrementBy completed")
+	return count, nil
+}
+
+// TimeToLive returns how long until the key expires. It is zero when the key does not exist or never expires.
+func (redisRepo *RedisRepository) TimeToLive(key string) time.Duration {
+	redisRepo.preRequest()
+	c, cancel := generateContext()
+	defer cancel()
+
+	ttl, err := redisRepo.Clinet.TTL(c, key).Result()
+	if err != nil {
+		logger.Error(errors.New("redis error occured while running TimeToLive"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "key",
+			Data: key,
+		})
+		return 0
+	}
+	if ttl < 0 {
+		return 0
+	}
+
+	logger.Info("redis TimeToLive completed")
+	return ttl
+}
@@ -2,12 +2,15 @@ package infrastructure
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	apperrors "kego.com/application/appErrors"
+	"kego.com/infrastructure/auth"
 	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/logger/metrics"
 	middlewares "kego.com/infrastructure/middleware"
@@ -31,23 +34,56 @@ func (s *ginServer)Start(){
 	server := gin.Default()
 	server.MaxMultipartMemory =  15 << 20  // 8 MiB
 
+	// rate limits, sign in lockouts and API key IP allowlists rely on the client's address. X-Forwarded-For is only
+	// believed when it comes from one of TRUSTED_PROXIES, otherwise the address of the connection is used.
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	for _, proxy := range trustedProxies {
+		// trusting every address would let anyone set their own address
+		if _, network, err := net.ParseCIDR(strings.TrimSpace(proxy)); err == nil {
+			if ones, _ := network.Mask.Size(); ones == 0 {
+				panic(fmt.Sprintf("TRUSTED_PROXIES cannot trust every address: %s", proxy))
+			}
+		}
+	}
+	err = server.SetTrustedProxies(trustedProxies)
+	if err != nil {
+		panic(fmt.Sprintf("invalid TRUSTED_PROXIES: %s", err))
+	}
+
 	server.Use(metrics.MetricMonitor.MetricMiddleware().(func (*gin.Context)))
-	server.Use(middlewares.UserAgentMiddleware())
+	// registered before the user agent middleware since the services verifying our tokens are not the app
+	server.GET("/.well-known/jwks.json", func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, auth.KeySet.JWKS())
+	})
 
 	v1 := server.Group("/api",)
 
 	{
-		routerV1 := v1.Group("/v1")
+		// the app's routes. Only the app can call them.
+		routerV1 := v1.Group("/v1", middlewares.UserAgentMiddleware())
 		{
 			authroutev1.AuthRouter(routerV1)
 			authroutev1.InfoRouter(routerV1)
 			authroutev1.UserRouter(routerV1)
 			authroutev1.BusinessRouter(routerV1)
 			authroutev1.WalletRouter(routerV1)
+			authroutev1.NotificationRouter(routerV1)
+			authroutev1.AdminRouter(routerV1)
+		}
+		// called by businesses' own systems with an API key instead of the app's headers and tokens
+		s2sRouterV1 := v1.Group("/s2s/v1")
+		{
+			authroutev1.S2SRouter(s2sRouterV1)
 		}
+		// called by payment processors, which authenticate with a shared secret
+		authroutev1.WebhookRouter(v1)
 	}
 
-	server.GET("/ping", func(ctx *gin.Context) {
+	server.GET("/ping", middlewares.UserAgentMiddleware(), func(ctx *gin.Context) {
 		server_response.Responder.Respond(ctx, http.StatusOK, "pong!", nil, nil)
 	})
 
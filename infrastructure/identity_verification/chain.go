@@ -0,0 +1,106 @@
+package identityverification
+
+import (
+	"errors"
+
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+	"kego.com/infrastructure/logger"
+)
+
+type NamedProvider struct {
+	Name     string
+	Verifier identity_verification_types.IdentityVerifierType
+}
+
+// ProviderChain asks each provider in turn, moving on only when one is unavailable.
+// An answer from a provider, even a rejection, is final.
+type ProviderChain struct {
+	Providers []NamedProvider
+}
+
+func (pc *ProviderChain) FetchBVNDetails(bvn string) (*identity_verification_types.BVNData, error) {
+	var data *identity_verification_types.BVNData
+	provider, err := pc.try("FetchBVNDetails", func(verifier identity_verification_types.IdentityVerifierType) (err error) {
+		data, err = verifier.FetchBVNDetails(bvn)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalised := data.Normalise()
+	normalised.Provider = provider
+	return &normalised, nil
+}
+
+func (pc *ProviderChain) FetchNINDetails(nin string) (*identity_verification_types.DocumentData, error) {
+	var data *identity_verification_types.DocumentData
+	provider, err := pc.try("FetchNINDetails", func(verifier identity_verification_types.IdentityVerifierType) (err error) {
+		data, err = verifier.FetchNINDetails(nin)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalised := data.Normalise()
+	normalised.Provider = provider
+	return &normalised, nil
+}
+
+func (pc *ProviderChain) VerifyDocument(lookup identity_verification_types.DocumentLookup) (*identity_verification_types.DocumentData, error) {
+	var data *identity_verification_types.DocumentData
+	provider, err := pc.try("VerifyDocument", func(verifier identity_verification_types.IdentityVerifierType) (err error) {
+		data, err = verifier.VerifyDocument(lookup)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalised := data.Normalise()
+	normalised.Provider = provider
+	return &normalised, nil
+}
+
+func (pc *ProviderChain) FetchCompanyDetails(registrationNumber string) (*identity_verification_types.CompanyData, error) {
+	var data *identity_verification_types.CompanyData
+	provider, err := pc.try("FetchCompanyDetails", func(verifier identity_verification_types.IdentityVerifierType) (err error) {
+		data, err = verifier.FetchCompanyDetails(registrationNumber)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalised := data.Normalise()
+	normalised.Provider = provider
+	return &normalised, nil
+}
+
+func (pc *ProviderChain) FaceMatch(img1 string, img2 string) (*float32, error) {
+	var confidence *float32
+	_, err := pc.try("FaceMatch", func(verifier identity_verification_types.IdentityVerifierType) (err error) {
+		confidence, err = verifier.FaceMatch(img1, img2)
+		return err
+	})
+	return confidence, err
+}
+
+// try returns the name of the provider that answered.
+func (pc *ProviderChain) try(operation string, call func(identity_verification_types.IdentityVerifierType) error) (string, error) {
+	if len(pc.Providers) == 0 {
+		return "", errors.New("no identity verification provider has been set up")
+	}
+	var err error
+	for _, provider := range pc.Providers {
+		err = call(provider.Verifier)
+		if !errors.Is(err, identity_verification_types.ErrProviderUnavailable) {
+			return provider.Name, err
+		}
+		logger.Warning("identity verification provider unavailable, trying the next one", logger.LoggerOptions{
+			Key: "provider",
+			Data: provider.Name,
+		}, logger.LoggerOptions{
+			Key: "operation",
+			Data: operation,
+		})
+	}
+	return "", identity_verification_types.ErrProviderUnavailable
+}
@@ -0,0 +1,125 @@
+package fixture_identity_verification
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+// Fixtures decides every answer the fixture provider gives.
+type Fixtures struct {
+	BVNs                        map[string]identity_verification_types.BVNData `json:"bvns"`
+	NINs                        map[string]identity_verification_types.DocumentData `json:"nins"`
+	// passports and driver's licences by document number
+	Documents                   map[string]identity_verification_types.DocumentData `json:"documents"`
+	// businesses by CAC registration number, e.g. RC1234567
+	Companies                   map[string]identity_verification_types.CompanyData `json:"companies"`
+	// confidence returned when either image contains the key. Keys are tried in alphabetical order.
+	// Any other pair of images gets the default, which the shipped fixtures set low enough to fail.
+	FaceMatches                 map[string]float32                             `json:"faceMatches"`
+	DefaultFaceMatchConfidence  float32                                        `json:"defaultFaceMatchConfidence"`
+	// pretends the provider is down so fallback providers can be tried out
+	Unavailable                 bool                                           `json:"unavailable"`
+}
+
+// FixtureIdentityVerification stands in for an identity provider during local development and testing.
+// It never makes a network call and always gives the same answer for the same input.
+type FixtureIdentityVerification struct {
+	Fixtures Fixtures
+}
+
+// NewFixtureIdentityVerification loads fixtures from path, or the ones shipped with the app if path is empty.
+func NewFixtureIdentityVerification(path string) (*FixtureIdentityVerification, error) {
+	data := defaultFixtures
+	if path != "" {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data = file
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, err
+	}
+	return &FixtureIdentityVerification{
+		Fixtures: fixtures,
+	}, nil
+}
+
+func (fiv *FixtureIdentityVerification) FetchBVNDetails(bvn string) (*identity_verification_types.BVNData, error) {
+	if fiv.Fixtures.Unavailable {
+		return nil, fmt.Errorf("fixture provider is set to be unavailable: %w", identity_verification_types.ErrProviderUnavailable)
+	}
+	data, ok := fiv.Fixtures.BVNs[bvn]
+	if !ok {
+		return nil, errors.New("The BVN provided could not be verified. Check that it is correct and try again.")
+	}
+	return &data, nil
+}
+
+func (fiv *FixtureIdentityVerification) FetchNINDetails(nin string) (*identity_verification_types.DocumentData, error) {
+	if fiv.Fixtures.Unavailable {
+		return nil, fmt.Errorf("fixture provider is set to be unavailable: %w", identity_verification_types.ErrProviderUnavailable)
+	}
+	data, ok := fiv.Fixtures.NINs[nin]
+	if !ok {
+		return nil, errors.New("The NIN provided could not be verified. Check that it is correct and try again.")
+	}
+	data.Type = identity_verification_types.NINDocument
+	data.Number = nin
+	return &data, nil
+}
+
+func (fiv *FixtureIdentityVerification) VerifyDocument(lookup identity_verification_types.DocumentLookup) (*identity_verification_types.DocumentData, error) {
+	if fiv.Fixtures.Unavailable {
+		return nil, fmt.Errorf("fixture provider is set to be unavailable: %w", identity_verification_types.ErrProviderUnavailable)
+	}
+	data, ok := fiv.Fixtures.Documents[lookup.Number]
+	if !ok || data.Type != lookup.Type {
+		return nil, errors.New("The document number provided could not be verified. Check that it is correct and try again.")
+	}
+	data.Number = lookup.Number
+	return &data, nil
+}
+
+func (fiv *FixtureIdentityVerification) FetchCompanyDetails(registrationNumber string) (*identity_verification_types.CompanyData, error) {
+	if fiv.Fixtures.Unavailable {
+		return nil, fmt.Errorf("fixture provider is set to be unavailable: %w", identity_verification_types.ErrProviderUnavailable)
+	}
+	registrationNumber = identity_verification_types.NormaliseRegistrationNumber(registrationNumber)
+	data, ok := fiv.Fixtures.Companies[registrationNumber]
+	if !ok {
+		return nil, errors.New("The registration number provided could not be found with CAC. Check that it is correct and try again.")
+	}
+	data.RegistrationNumber = registrationNumber
+	return &data, nil
+}
+
+func (fiv *FixtureIdentityVerification) FaceMatch(img1 string, img2 string) (*float32, error) {
+	if fiv.Fixtures.Unavailable {
+		return nil, fmt.Errorf("fixture provider is set to be unavailable: %w", identity_verification_types.ErrProviderUnavailable)
+	}
+	confidence := fiv.Fixtures.DefaultFaceMatchConfidence
+	keys := make([]string, 0, len(fiv.Fixtures.FaceMatches))
+	for key := range fiv.Fixtures.FaceMatches {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.Contains(img1, key) || strings.Contains(img2, key) {
+			confidence = fiv.Fixtures.FaceMatches[key]
+			break
+		}
+	}
+	return &confidence, nil
+}
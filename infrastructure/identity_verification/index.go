@@ -1,21 +1,81 @@
 package identityverification
 
 import (
+	"errors"
 	"os"
+	"strings"
 
+	fixture_identity_verification "kego.com/infrastructure/identity_verification/fixture"
 	prembly_identity_verification "kego.com/infrastructure/identity_verification/prembly"
 	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+	youverify_identity_verification "kego.com/infrastructure/identity_verification/youverify"
+	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/network"
 )
 
 var IdentityVerifier identity_verification_types.IdentityVerifierType
 
-func InitialiseIdentityVerifier(){
-	IdentityVerifier = &prembly_identity_verification.PremblyIdentityVerification{
-		Network: &network.NetworkController{
-			BaseUrl: os.Getenv("PREMBLY_BASE_URL"),
-		},
-		API_KEY: os.Getenv("PREMBLY_API_KEY"),
-		APP_ID: os.Getenv("PREMBLY_APP_ID"),
+// InitialiseIdentityVerifier uses the provider named by IDENTITY_VERIFICATION_PROVIDER: prembly (the default), youverify,
+// or fixture to answer from IDENTITY_VERIFICATION_FIXTURES without calling anyone.
+// IDENTITY_VERIFICATION_FALLBACKS is a comma separated list of providers to try in order while it is down.
+// The fixture provider passes checks without making them, so it is refused in release mode.
+func InitialiseIdentityVerifier() error {
+	names := []string{os.Getenv("IDENTITY_VERIFICATION_PROVIDER")}
+	if names[0] == "" {
+		names[0] = "prembly"
 	}
-}
\ No newline at end of file
+	for _, name := range strings.Split(os.Getenv("IDENTITY_VERIFICATION_FALLBACKS"), ",") {
+		if name = strings.TrimSpace(name); name != "" && name != names[0] {
+			names = append(names, name)
+		}
+	}
+	chain := &ProviderChain{}
+	for _, name := range names {
+		if name == "fixture" && os.Getenv("GIN_MODE") == "release" {
+			return errors.New("the fixture identity verification provider cannot be used in release mode")
+		}
+		provider, err := newProvider(name)
+		if err != nil {
+			logger.Error(errors.New("could not set up identity verification provider"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			}, logger.LoggerOptions{
+				Key: "provider",
+				Data: name,
+			})
+			continue
+		}
+		chain.Providers = append(chain.Providers, NamedProvider{
+			Name: name,
+			Verifier: provider,
+		})
+	}
+	if len(chain.Providers) == 0 {
+		return errors.New("no identity verification provider could be set up")
+	}
+	IdentityVerifier = chain
+	return nil
+}
+
+func newProvider(name string) (identity_verification_types.IdentityVerifierType, error) {
+	switch name {
+	case "prembly":
+		return &prembly_identity_verification.PremblyIdentityVerification{
+			Network: &network.NetworkController{
+				BaseUrl: os.Getenv("PREMBLY_BASE_URL"),
+			},
+			API_KEY: os.Getenv("PREMBLY_API_KEY"),
+			APP_ID: os.Getenv("PREMBLY_APP_ID"),
+		}, nil
+	case "youverify":
+		return &youverify_identity_verification.YouverifyIdentityVerification{
+			Network: &network.NetworkController{
+				BaseUrl: os.Getenv("YOUVERIFY_BASE_URL"),
+			},
+			API_KEY: os.Getenv("YOUVERIFY_API_KEY"),
+		}, nil
+	case "fixture":
+		return fixture_identity_verification.NewFixtureIdentityVerification(os.Getenv("IDENTITY_VERIFICATION_FIXTURES"))
+	}
+	return nil, errors.New("unknown identity verification provider")
+}
@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"unicode"
 
 	"kego.com/application/constants"
 	"kego.com/application/utils"
@@ -20,21 +21,14 @@ type PremblyIdentityVerification struct {
 }
 
 func (piv *PremblyIdentityVerification) FetchBVNDetails(bvn string) (*identity_verification_types.BVNData, error) {
-	response, _, err := piv.Network.Post("/identitypass/verification/bvn", &map[string]string{
-		"x-api-key": piv.API_KEY,
-		"app-id": piv.APP_ID,
-	}, map[string]string{
+	response, err := piv.post("/identitypass/verification/bvn", map[string]string{
 		"number": bvn,
-	}, nil)
-	var premblyResponse PremblyBVNResponse
-	json.Unmarshal(*response, &premblyResponse)
+	}, "retrieving bvn data")
 	if err != nil {
-		logger.Error(errors.New("error retireving bvn data from prembly"), logger.LoggerOptions{
-			Key: "error",
-			Data: err,
-		})
-		return nil, errors.New("something went wrong while retireving bvn data from prembly")
+		return nil, err
 	}
+	var premblyResponse PremblyBVNResponse
+	json.Unmarshal(*response, &premblyResponse)
 	if !premblyResponse.Status {
 		logger.Error(errors.New(premblyResponse.Message), logger.LoggerOptions{
 			Key: "error",
@@ -46,30 +40,29 @@ func (piv *PremblyIdentityVerification) FetchBVNDetails(bvn string) (*identity_v
 		return nil, errors.New(premblyResponse.Message)
 	}
 	logger.Info("BVN information retireved by Prembly")
-	return &premblyResponse.Data, nil
+	return &identity_verification_types.BVNData{
+		Gender: premblyResponse.Data.Gender,
+		WatchListed: premblyResponse.Data.WatchListed,
+		FirstName: premblyResponse.Data.FirstName,
+		MiddleName: premblyResponse.Data.MiddleName,
+		LastName: premblyResponse.Data.LastName,
+		DateOfBirth: premblyResponse.Data.DateOfBirth,
+		PhoneNumber: premblyResponse.Data.PhoneNumber,
+		Nationality: premblyResponse.Data.Nationality,
+		Base64Image: premblyResponse.Data.Base64Image,
+	}, nil
 }
 
 func (piv *PremblyIdentityVerification) FaceMatch(img1 string, img2 string) (*float32, error) {
-	if os.Getenv("GIN_MODE") != "production" {
-		img1 = "https://res.cloudinary.com/dh3i1wodq/image/upload/v1675417496/cbimage_3_drqdoc.jpg"
-		img2 = "https://res.cloudinary.com/dh3i1wodq/image/upload/v1677955197/face_image_tkmmwz.jpg"
-	}
-	response, _, err := piv.Network.Post("/identitypass/verification/biometrics/face/comparison", &map[string]string{
-		"x-api-key": piv.API_KEY,
-		"app-id": piv.APP_ID,
-	}, map[string]string{
+	response, err := piv.post("/identitypass/verification/biometrics/face/comparison", map[string]string{
 		"image_one": img1,
 		"image_two": img2,
-	}, nil)
-	var premblyResponse PremblyFaceMatchResponse
-	json.Unmarshal(*response, &premblyResponse)
+	}, "performing face match")
 	if err != nil {
-		logger.Error(errors.New("error performing face match on prembly"), logger.LoggerOptions{
-			Key: "error",
-			Data: err,
-		})
-		return nil, errors.New("something went wrong while performing face match")
+		return nil, err
 	}
+	var premblyResponse PremblyFaceMatchResponse
+	json.Unmarshal(*response, &premblyResponse)
 	if !premblyResponse.Status {
 		logger.Error(errors.New(premblyResponse.Message), logger.LoggerOptions{
 			Key: "error",
@@ -85,4 +78,123 @@ func (piv *PremblyIdentityVerification) FaceMatch(img1 string, img2 string) (*fl
 	}
 	logger.Info("Face Match completed by Prembly")
 	return &premblyResponse.Confidence, nil
-}
\ No newline at end of file
+}
+
+func (piv *PremblyIdentityVerification) FetchNINDetails(nin string) (*identity_verification_types.DocumentData, error) {
+	response, err := piv.post("/identitypass/verification/nin", map[string]string{
+		"number": nin,
+	}, "retrieving nin data")
+	if err != nil {
+		return nil, err
+	}
+	var premblyResponse PremblyNINResponse
+	json.Unmarshal(*response, &premblyResponse)
+	if !premblyResponse.Status {
+		logger.Error(errors.New(premblyResponse.Detail), logger.LoggerOptions{
+			Key: "message",
+			Data: premblyResponse.Message,
+		})
+		return nil, errors.New("The NIN provided could not be verified. Check that it is correct and try again.")
+	}
+	logger.Info("NIN information retrieved by Prembly")
+	return &identity_verification_types.DocumentData{
+		Type: identity_verification_types.NINDocument,
+		Number: nin,
+		FirstName: premblyResponse.Data.FirstName,
+		MiddleName: &premblyResponse.Data.MiddleName,
+		LastName: premblyResponse.Data.Surname,
+		DateOfBirth: premblyResponse.Data.BirthDate,
+		Gender: premblyResponse.Data.Gender,
+		Base64Image: premblyResponse.Data.Photo,
+	}, nil
+}
+
+// VerifyDocument has Prembly read the document in the image. The number printed on it has to be the one the user gave.
+func (piv *PremblyIdentityVerification) VerifyDocument(lookup identity_verification_types.DocumentLookup) (*identity_verification_types.DocumentData, error) {
+	docType, ok := premblyDocumentTypes[lookup.Type]
+	if !ok {
+		return nil, fmt.Errorf("prembly cannot verify documents of type %s", lookup.Type)
+	}
+	response, err := piv.post("/identitypass/verification/document", map[string]string{
+		"doc_type": docType,
+		"doc_country": "NG",
+		"doc_image": lookup.ImageURL,
+	}, "verifying document")
+	if err != nil {
+		return nil, err
+	}
+	var premblyResponse PremblyDocumentResponse
+	json.Unmarshal(*response, &premblyResponse)
+	if !premblyResponse.Status {
+		logger.Error(errors.New(premblyResponse.Detail), logger.LoggerOptions{
+			Key: "message",
+			Data: premblyResponse.Message,
+		})
+		return nil, errors.New("We could not read your document. Make sure the whole document is in the picture and try again.")
+	}
+	if !strings.EqualFold(strings.TrimSpace(premblyResponse.Data.DocumentNumber), strings.TrimSpace(lookup.Number)) {
+		return nil, errors.New("The document number you entered does not match the one on your document.")
+	}
+	logger.Info("Document verified by Prembly")
+	return &identity_verification_types.DocumentData{
+		Type: lookup.Type,
+		Number: premblyResponse.Data.DocumentNumber,
+		FirstName: premblyResponse.Data.FirstName,
+		MiddleName: &premblyResponse.Data.MiddleName,
+		LastName: premblyResponse.Data.LastName,
+		DateOfBirth: premblyResponse.Data.DateOfBirth,
+		Gender: premblyResponse.Data.Sex,
+		ExpiryDate: &premblyResponse.Data.ExpiryDate,
+		Base64Image: premblyResponse.Data.Portrait,
+	}, nil
+}
+
+func (piv *PremblyIdentityVerification) FetchCompanyDetails(registrationNumber string) (*identity_verification_types.CompanyData, error) {
+	registrationNumber = identity_verification_types.NormaliseRegistrationNumber(registrationNumber)
+	// prembly takes the type and the number separately
+	number := strings.TrimLeftFunc(registrationNumber, unicode.IsLetter)
+	companyType := strings.TrimSuffix(registrationNumber, number)
+	response, err := piv.post("/identitypass/verification/cac", map[string]string{
+		"rc_number": number,
+		"company_type": companyType,
+	}, "retrieving company data")
+	if err != nil {
+		return nil, err
+	}
+	var premblyResponse PremblyCompanyResponse
+	json.Unmarshal(*response, &premblyResponse)
+	if !premblyResponse.Status {
+		logger.Error(errors.New(premblyResponse.Detail), logger.LoggerOptions{
+			Key: "message",
+			Data: premblyResponse.Message,
+		})
+		return nil, errors.New("The registration number provided could not be found with CAC. Check that it is correct and try again.")
+	}
+	logger.Info("Company information retrieved by Prembly")
+	return &identity_verification_types.CompanyData{
+		RegistrationNumber: registrationNumber,
+		Name: premblyResponse.Data.CompanyName,
+		RegistrationDate: premblyResponse.Data.DateOfRegistration,
+		Address: premblyResponse.Data.Address,
+		Status: premblyResponse.Data.CompanyStatus,
+	}, nil
+}
+
+// post calls Prembly, treating anything that means Prembly is having trouble as it being unavailable.
+func (piv *PremblyIdentityVerification) post(path string, body any, action string) (*[]byte, error) {
+	response, statusCode, err := piv.Network.Post(path, &map[string]string{
+		"x-api-key": piv.API_KEY,
+		"app-id": piv.APP_ID,
+	}, body, nil)
+	if err != nil || *statusCode >= 500 || *statusCode == 429 {
+		logger.Error(fmt.Errorf("error %s on prembly", action), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		})
+		return nil, fmt.Errorf("something went wrong while %s on prembly: %w", action, identity_verification_types.ErrProviderUnavailable)
+	}
+	return response, nil
+}
@@ -2,11 +2,28 @@ package prembly_identity_verification
 
 import identity_verification_types "kego.com/infrastructure/identity_verification/types"
 
+var premblyDocumentTypes = map[identity_verification_types.DocumentType]string{
+	identity_verification_types.PassportDocument: "PP",
+	identity_verification_types.DriversLicenceDocument: "DL",
+}
+
 type PremblyBVNResponse struct {
-	Status      	bool    							`json:"status"`
-	Detail      	string  							`json:"detail"`
-	Message		 	string 							`json:"message"`
-	Data        	identity_verification_types.BVNData `json:"data"`
+	Status      	bool    			`json:"status"`
+	Detail      	string  			`json:"detail"`
+	Message		 	string 				`json:"message"`
+	Data        	PremblyBVNData 		`json:"data"`
+}
+
+type PremblyBVNData struct {
+	Gender            string    `json:"gender"`
+	WatchListed       string    `json:"watchListed"`
+	FirstName         string    `json:"firstName"`
+	MiddleName        *string   `json:"middleName"`
+	LastName          string    `json:"lastName"`
+	DateOfBirth       string    `json:"dateOfBirth"`
+	PhoneNumber       string    `json:"phoneNumber1"`
+	Nationality       string    `json:"nationality"`
+	Base64Image       string    `json:"base64Image"`
 }
 
 type PremblyFaceMatchResponse struct {
@@ -15,3 +32,51 @@ type PremblyFaceMatchResponse struct {
 	Message		 	string 		`json:"message"`
 	Confidence      float32 		`json:"confidence"`
 }
+
+type PremblyNINResponse struct {
+	Status      	bool    			`json:"status"`
+	Detail      	string  			`json:"detail"`
+	Message		 	string 				`json:"message"`
+	Data        	PremblyNINData 		`json:"nin_data"`
+}
+
+type PremblyNINData struct {
+	FirstName         string    `json:"firstname"`
+	MiddleName        string    `json:"middlename"`
+	Surname           string    `json:"surname"`
+	BirthDate         string    `json:"birthdate"`
+	Gender            string    `json:"gender"`
+	Photo             string    `json:"photo"`
+}
+
+type PremblyDocumentResponse struct {
+	Status      	bool    				`json:"status"`
+	Detail      	string  				`json:"detail"`
+	Message		 	string 					`json:"message"`
+	Data        	PremblyDocumentData 	`json:"data"`
+}
+
+type PremblyDocumentData struct {
+	DocumentNumber    string    `json:"document_number"`
+	FirstName         string    `json:"first_name"`
+	MiddleName        string    `json:"middle_name"`
+	LastName          string    `json:"last_name"`
+	DateOfBirth       string    `json:"date_of_birth"`
+	Sex               string    `json:"sex"`
+	ExpiryDate        string    `json:"expiry_date"`
+	Portrait          string    `json:"portrait"`
+}
+
+type PremblyCompanyResponse struct {
+	Status      	bool    				`json:"status"`
+	Detail      	string  				`json:"detail"`
+	Message		 	string 					`json:"message"`
+	Data        	PremblyCompanyData 		`json:"data"`
+}
+
+type PremblyCompanyData struct {
+	CompanyName         string    `json:"company_name"`
+	DateOfRegistration  string    `json:"date_of_registration"`
+	Address             string    `json:"address"`
+	CompanyStatus       string    `json:"company_status"`
+}
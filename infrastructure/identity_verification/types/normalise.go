@@ -0,0 +1,137 @@
+package identity_verification_types
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// date layouts providers have been seen to send dates in
+var dateLayouts = []string{
+	"2006-01-02",
+	"02-Jan-2006",
+	"02-01-2006",
+	"02/01/2006",
+	"2006/01/02",
+	"January 2, 2006",
+	time.RFC3339,
+}
+
+// Normalise puts the data in the same shape whichever provider it came from, so users verified through
+// different providers can be compared with each other.
+func (data BVNData) Normalise() BVNData {
+	data.FirstName = NormaliseName(data.FirstName)
+	data.LastName = NormaliseName(data.LastName)
+	data.MiddleName = normaliseMiddleName(data.MiddleName)
+	data.Gender = NormaliseGender(data.Gender)
+	data.DateOfBirth = NormaliseDate(data.DateOfBirth)
+	data.PhoneNumber = NormalisePhoneNumber(data.PhoneNumber)
+	data.Nationality = NormaliseName(data.Nationality)
+	switch strings.ToUpper(strings.TrimSpace(data.WatchListed)) {
+	case "YES", "TRUE", "Y":
+		data.WatchListed = "YES"
+	default:
+		data.WatchListed = "NO"
+	}
+	return data
+}
+
+func (data DocumentData) Normalise() DocumentData {
+	data.Number = strings.ToUpper(strings.Join(strings.Fields(data.Number), ""))
+	data.FirstName = NormaliseName(data.FirstName)
+	data.LastName = NormaliseName(data.LastName)
+	data.MiddleName = normaliseMiddleName(data.MiddleName)
+	data.Gender = NormaliseGender(data.Gender)
+	data.DateOfBirth = NormaliseDate(data.DateOfBirth)
+	if data.ExpiryDate != nil {
+		expiryDate := NormaliseDate(*data.ExpiryDate)
+		data.ExpiryDate = &expiryDate
+		if expiryDate == "" {
+			data.ExpiryDate = nil
+		}
+	}
+	return data
+}
+
+func (data CompanyData) Normalise() CompanyData {
+	data.RegistrationNumber = NormaliseRegistrationNumber(data.RegistrationNumber)
+	data.Name = NormaliseCompanyName(data.Name)
+	data.RegistrationDate = NormaliseDate(data.RegistrationDate)
+	data.Address = strings.Join(strings.Fields(data.Address), " ")
+	data.Status = strings.ToUpper(strings.TrimSpace(data.Status))
+	return data
+}
+
+// NormaliseRegistrationNumber returns a CAC number with its type in front, e.g. "rc 12345" becomes RC12345.
+// Numbers without a type are taken to be companies (RC).
+func NormaliseRegistrationNumber(number string) string {
+	number = strings.ToUpper(strings.Join(strings.Fields(number), ""))
+	if number != "" && unicode.IsDigit(rune(number[0])) {
+		return "RC" + number
+	}
+	return number
+}
+
+// NormaliseCompanyName upper cases the name and collapses spaces, the way CAC keeps names.
+func NormaliseCompanyName(name string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
+
+func normaliseMiddleName(middleName *string) *string {
+	if middleName == nil {
+		return nil
+	}
+	normalised := NormaliseName(*middleName)
+	if normalised == "" {
+		return nil
+	}
+	return &normalised
+}
+
+// NormaliseName trims and title cases a name, e.g. "  JOHN-PAUL " becomes "John-Paul".
+func NormaliseName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	runes := []rune(strings.ToLower(name))
+	for i := range runes {
+		if i == 0 || runes[i-1] == ' ' || runes[i-1] == '-' {
+			runes[i] = unicode.ToUpper(runes[i])
+		}
+	}
+	return string(runes)
+}
+
+// NormaliseGender returns Male, Female or an empty string if the gender could not be read.
+func NormaliseGender(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "m", "male":
+		return "Male"
+	case "f", "female":
+		return "Female"
+	}
+	return ""
+}
+
+// NormaliseDate returns the date as YYYY-MM-DD. Dates in a layout we do not know are returned as they are.
+func NormaliseDate(date string) string {
+	date = strings.TrimSpace(date)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed.Format("2006-01-02")
+		}
+	}
+	return date
+}
+
+// NormalisePhoneNumber returns the nigerian number without the country code or leading zero, e.g. 8012345678.
+func NormalisePhoneNumber(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) == 13 {
+		digits = strings.TrimPrefix(digits, "234")
+	}
+	return strings.TrimPrefix(digits, "0")
+}
@@ -1,19 +1,71 @@
 package identity_verification_types
 
+import "errors"
+
 type IdentityVerifierType interface {
 	FetchBVNDetails(string) (*BVNData, error)
+	FetchNINDetails(string) (*DocumentData, error)
+	// VerifyDocument reads the details off an uploaded document and checks them with the issuer where the provider can.
+	VerifyDocument(DocumentLookup) (*DocumentData, error)
 	FaceMatch(string, string) (*float32, error)
+	// FetchCompanyDetails looks a business up with the Corporate Affairs Commission by its RC, BN or IT number.
+	FetchCompanyDetails(string) (*CompanyData, error)
 }
 
+// ErrProviderUnavailable is wrapped by providers when they could not be reached or failed on their end,
+// as opposed to giving an answer we do not like. Only these errors move on to the next provider.
+var ErrProviderUnavailable = errors.New("We are unable to verify your identity at the moment. Please try again later.")
 
+// BVNData is what every provider's BVN lookup is turned into.
 type BVNData struct {
 	Gender            string    `json:"gender"`
 	WatchListed       string    `json:"watchListed"`
 	FirstName         string    `json:"firstName"`
-	MiddleName        *string    `json:"middleName"`
+	MiddleName        *string   `json:"middleName"`
 	LastName          string    `json:"lastName"`
 	DateOfBirth       string    `json:"dateOfBirth"`
-	PhoneNumber       string     `json:"phoneNumber1"`
+	PhoneNumber       string    `json:"phoneNumber"`
 	Nationality       string    `json:"nationality"`
 	Base64Image       string    `json:"base64Image"`
-}
\ No newline at end of file
+	Provider          string    `json:"provider"`
+}
+
+type DocumentType string
+
+const (
+	NINDocument 			DocumentType = "nin"
+	PassportDocument 		DocumentType = "passport"
+	DriversLicenceDocument 	DocumentType = "drivers_licence"
+)
+
+type DocumentLookup struct {
+	Type       DocumentType
+	Number     string
+	ImageURL   string
+	// some issuers will only look a document up alongside the holder's surname
+	LastName   string
+}
+
+// DocumentData is what every provider's NIN lookup or document check is turned into.
+type DocumentData struct {
+	Type              DocumentType  `json:"type"`
+	Number            string        `json:"number"`
+	FirstName         string        `json:"firstName"`
+	MiddleName        *string       `json:"middleName"`
+	LastName          string        `json:"lastName"`
+	DateOfBirth       string        `json:"dateOfBirth"`
+	Gender            string        `json:"gender"`
+	ExpiryDate        *string       `json:"expiryDate"`
+	Base64Image       string        `json:"base64Image"`
+	Provider          string        `json:"provider"`
+}
+
+// CompanyData is what every provider's CAC lookup is turned into.
+type CompanyData struct {
+	RegistrationNumber  string    `json:"registrationNumber"`
+	Name                string    `json:"name"`
+	RegistrationDate    string    `json:"registrationDate"`
+	Address             string    `json:"address"`
+	Status              string    `json:"status"` // e.g. ACTIVE or INACTIVE
+	Provider            string    `json:"provider"`
+}
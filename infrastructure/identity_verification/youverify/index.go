@@ -0,0 +1,188 @@
+package youverify_identity_verification
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	identity_verification_types "kego.com/infrastructure/identity_verification/types"
+	"kego.com/infrastructure/logger"
+	"kego.com/infrastructure/network"
+)
+
+type YouverifyIdentityVerification struct {
+	Network *network.NetworkController
+	API_KEY string
+}
+
+func (yiv *YouverifyIdentityVerification) FetchBVNDetails(bvn string) (*identity_verification_types.BVNData, error) {
+	response, statusCode, err := yiv.post("/v2/api/identity/ng/bvn", YouverifyLookupPayload{
+		ID: bvn,
+		IsSubjectConsent: true,
+	}, "retrieving bvn data")
+	if err != nil {
+		return nil, err
+	}
+	var youverifyResponse YouverifyBVNResponse
+	json.Unmarshal(*response, &youverifyResponse)
+	if !youverifyResponse.Success || youverifyResponse.Data.Status != "found" {
+		logger.Error(errors.New("youverify could not verify bvn"), logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		}, logger.LoggerOptions{
+			Key: "message",
+			Data: youverifyResponse.Message,
+		})
+		return nil, errors.New("The BVN provided could not be verified. Check that it is correct and try again.")
+	}
+	logger.Info("BVN information retrieved by Youverify")
+	watchListed := "NO"
+	if youverifyResponse.Data.WatchListed {
+		watchListed = "YES"
+	}
+	return &identity_verification_types.BVNData{
+		Gender: youverifyResponse.Data.Gender,
+		WatchListed: watchListed,
+		FirstName: youverifyResponse.Data.FirstName,
+		MiddleName: &youverifyResponse.Data.MiddleName,
+		LastName: youverifyResponse.Data.LastName,
+		DateOfBirth: youverifyResponse.Data.DateOfBirth,
+		PhoneNumber: youverifyResponse.Data.Mobile,
+		Nationality: youverifyResponse.Data.Nationality,
+		Base64Image: youverifyResponse.Data.Image,
+	}, nil
+}
+
+func (yiv *YouverifyIdentityVerification) FaceMatch(img1 string, img2 string) (*float32, error) {
+	response, statusCode, err := yiv.post("/v2/api/identity/compare-image", YouverifyCompareImagePayload{
+		Image1: img1,
+		Image2: img2,
+	}, "performing face match")
+	if err != nil {
+		return nil, err
+	}
+	var youverifyResponse YouverifyCompareImageResponse
+	json.Unmarshal(*response, &youverifyResponse)
+	if !youverifyResponse.Success {
+		logger.Error(errors.New("youverify could not compare images"), logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		}, logger.LoggerOptions{
+			Key: "message",
+			Data: youverifyResponse.Message,
+		})
+		return nil, errors.New("We could not compare your picture. Make sure your face is clearly visible and try again.")
+	}
+	logger.Info("Face Match completed by Youverify")
+	confidence := youverifyResponse.Data.ImageComparison.ConfidenceLevel
+	return &confidence, nil
+}
+
+func (yiv *YouverifyIdentityVerification) FetchNINDetails(nin string) (*identity_verification_types.DocumentData, error) {
+	return yiv.lookupDocument("/v2/api/identity/ng/nin", identity_verification_types.DocumentLookup{
+		Type: identity_verification_types.NINDocument,
+		Number: nin,
+	})
+}
+
+// VerifyDocument looks the document up with its issuer through Youverify. The details on record are used
+// rather than ones read off the picture, which is only used for face matching.
+func (yiv *YouverifyIdentityVerification) VerifyDocument(lookup identity_verification_types.DocumentLookup) (*identity_verification_types.DocumentData, error) {
+	switch lookup.Type {
+	case identity_verification_types.PassportDocument:
+		return yiv.lookupDocument("/v2/api/identity/ng/passport", lookup)
+	case identity_verification_types.DriversLicenceDocument:
+		return yiv.lookupDocument("/v2/api/identity/ng/drivers-license", lookup)
+	}
+	return nil, fmt.Errorf("youverify cannot verify documents of type %s", lookup.Type)
+}
+
+func (yiv *YouverifyIdentityVerification) lookupDocument(path string, lookup identity_verification_types.DocumentLookup) (*identity_verification_types.DocumentData, error) {
+	response, statusCode, err := yiv.post(path, YouverifyLookupPayload{
+		ID: lookup.Number,
+		LastName: lookup.LastName,
+		IsSubjectConsent: true,
+	}, fmt.Sprintf("looking up %s", lookup.Type))
+	if err != nil {
+		return nil, err
+	}
+	var youverifyResponse YouverifyDocumentResponse
+	json.Unmarshal(*response, &youverifyResponse)
+	if !youverifyResponse.Success || youverifyResponse.Data.Status != "found" {
+		logger.Error(fmt.Errorf("youverify could not verify %s", lookup.Type), logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		}, logger.LoggerOptions{
+			Key: "message",
+			Data: youverifyResponse.Message,
+		})
+		return nil, errors.New("The document number provided could not be verified. Check that it is correct and try again.")
+	}
+	logger.Info(fmt.Sprintf("%s verified by Youverify", lookup.Type))
+	data := youverifyResponse.Data
+	var expiryDate *string
+	if data.ExpiredDate != "" {
+		expiryDate = &data.ExpiredDate
+	}
+	return &identity_verification_types.DocumentData{
+		Type: lookup.Type,
+		Number: lookup.Number,
+		FirstName: data.FirstName,
+		MiddleName: &data.MiddleName,
+		LastName: data.LastName,
+		DateOfBirth: data.DateOfBirth,
+		Gender: data.Gender,
+		ExpiryDate: expiryDate,
+		Base64Image: data.Image,
+	}, nil
+}
+
+func (yiv *YouverifyIdentityVerification) FetchCompanyDetails(registrationNumber string) (*identity_verification_types.CompanyData, error) {
+	registrationNumber = identity_verification_types.NormaliseRegistrationNumber(registrationNumber)
+	response, statusCode, err := yiv.post("/v2/api/verifications/global/company-advance-check", YouverifyCompanyPayload{
+		RegistrationNumber: registrationNumber,
+		CountryCode: "NG",
+		IsConsent: true,
+	}, "retrieving company data")
+	if err != nil {
+		return nil, err
+	}
+	var youverifyResponse YouverifyCompanyResponse
+	json.Unmarshal(*response, &youverifyResponse)
+	if !youverifyResponse.Success || youverifyResponse.Data.Status != "found" {
+		logger.Error(errors.New("youverify could not find company"), logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		}, logger.LoggerOptions{
+			Key: "message",
+			Data: youverifyResponse.Message,
+		})
+		return nil, errors.New("The registration number provided could not be found with CAC. Check that it is correct and try again.")
+	}
+	logger.Info("Company information retrieved by Youverify")
+	return &identity_verification_types.CompanyData{
+		RegistrationNumber: registrationNumber,
+		Name: youverifyResponse.Data.Name,
+		RegistrationDate: youverifyResponse.Data.RegistrationDate,
+		Address: youverifyResponse.Data.Address,
+		Status: youverifyResponse.Data.CompanyStatus,
+	}, nil
+}
+
+// post calls Youverify, treating anything that means Youverify is having trouble as it being unavailable.
+func (yiv *YouverifyIdentityVerification) post(path string, body any, action string) (*[]byte, int, error) {
+	response, statusCode, err := yiv.Network.Post(path, &map[string]string{
+		"token": yiv.API_KEY,
+	}, body, nil)
+	if err != nil || *statusCode >= 500 || *statusCode == 429 {
+		logger.Error(fmt.Errorf("error %s on youverify", action), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "statusCode",
+			Data: statusCode,
+		})
+		return nil, 0, fmt.Errorf("something went wrong while %s on youverify: %w", action, identity_verification_types.ErrProviderUnavailable)
+	}
+	return response, *statusCode, nil
+}
@@ -0,0 +1,82 @@
+package youverify_identity_verification
+
+type YouverifyLookupPayload struct {
+	ID                 string    `json:"id"`
+	LastName           string    `json:"lastName,omitempty"` // required to look up passports
+	IsSubjectConsent   bool      `json:"isSubjectConsent"`
+}
+
+type YouverifyBVNResponse struct {
+	Success     bool              `json:"success"`
+	Message     string            `json:"message"`
+	Data        YouverifyBVNData  `json:"data"`
+}
+
+type YouverifyBVNData struct {
+	// found or not_found
+	Status          string    `json:"status"`
+	FirstName       string    `json:"firstName"`
+	MiddleName      string    `json:"middleName"`
+	LastName        string    `json:"lastName"`
+	DateOfBirth     string    `json:"dateOfBirth"`
+	Mobile          string    `json:"mobile"`
+	Gender          string    `json:"gender"`
+	Nationality     string    `json:"nationality"`
+	Image           string    `json:"image"`
+	WatchListed     bool      `json:"watchListed"`
+}
+
+type YouverifyCompareImagePayload struct {
+	Image1    string    `json:"image1"`
+	Image2    string    `json:"image2"`
+}
+
+type YouverifyCompareImageResponse struct {
+	Success     bool    `json:"success"`
+	Message     string  `json:"message"`
+	Data        struct {
+		ImageComparison struct {
+			Match             bool      `json:"match"`
+			ConfidenceLevel   float32   `json:"confidenceLevel"`
+		} `json:"imageComparison"`
+	} `json:"data"`
+}
+
+type YouverifyDocumentResponse struct {
+	Success     bool                   `json:"success"`
+	Message     string                 `json:"message"`
+	Data        YouverifyDocumentData  `json:"data"`
+}
+
+type YouverifyDocumentData struct {
+	// found or not_found
+	Status          string    `json:"status"`
+	FirstName       string    `json:"firstName"`
+	MiddleName      string    `json:"middleName"`
+	LastName        string    `json:"lastName"`
+	DateOfBirth     string    `json:"dateOfBirth"`
+	Gender          string    `json:"gender"`
+	ExpiredDate     string    `json:"expiredDate"`
+	Image           string    `json:"image"`
+}
+
+type YouverifyCompanyPayload struct {
+	RegistrationNumber   string    `json:"registrationNumber"`
+	CountryCode          string    `json:"countryCode"`
+	IsConsent            bool      `json:"isConsent"`
+}
+
+type YouverifyCompanyResponse struct {
+	Success     bool                  `json:"success"`
+	Message     string                `json:"message"`
+	Data        YouverifyCompanyData  `json:"data"`
+}
+
+type YouverifyCompanyData struct {
+	// found or not_found
+	Status             string    `json:"status"`
+	Name               string    `json:"name"`
+	RegistrationDate   string    `json:"registrationDate"`
+	Address            string    `json:"address"`
+	CompanyStatus      string    `json:"companyStatus"`
+}
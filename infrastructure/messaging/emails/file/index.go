@@ -0,0 +1,27 @@
+package file_email
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"kego.com/infrastructure/messaging/emails/types"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// FileEmailProvider writes every email to Dir instead of sending it, so they can be opened in a browser during local testing.
+type FileEmailProvider struct {
+	Dir string
+}
+
+func (f *FileEmailProvider) Send(email *types.Email) error {
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.html", time.Now().Format("20060102T150405.000000000"), unsafeFileNameChars.ReplaceAllString(email.To, "_"))
+	content := fmt.Sprintf("<!--\nFrom: %s <%s>\nTo: %s\nSubject: %s\n-->\n%s", email.FromName, email.From, email.To, email.Subject, email.HTML)
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(content), 0600)
+}
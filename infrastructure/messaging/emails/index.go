@@ -1,3 +1,95 @@
 package emails
 
-var EmailService =  SendGridService{}
\ No newline at end of file
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+
+	file_email "kego.com/infrastructure/messaging/emails/file"
+	sendgrid_email "kego.com/infrastructure/messaging/emails/sendgrid"
+	smtp_email "kego.com/infrastructure/messaging/emails/smtp"
+	"kego.com/infrastructure/messaging/emails/types"
+	"kego.com/infrastructure/logger"
+)
+
+var EmailService = Mailer{}
+
+// Mailer renders templates and hands the result to the configured provider.
+type Mailer struct {
+	Provider  types.EmailProviderType
+	FromName  string
+	From      string
+	templates map[string]*template.Template
+}
+
+// InitialiseEmailService parses the templates and picks the provider named by EMAIL_PROVIDER:
+// sendgrid (the default), smtp, or file to write emails to EMAIL_OUTPUT_DIR.
+// A template that does not parse is an error, rather than something found when the first email using it is sent.
+func InitialiseEmailService() error {
+	templates, err := parseTemplates(templatesDir)
+	if err != nil {
+		return err
+	}
+	EmailService.templates = templates
+	EmailService.FromName = "Kego"
+	EmailService.From = os.Getenv("KEGO_EMAIL")
+	switch os.Getenv("EMAIL_PROVIDER") {
+	case "smtp":
+		EmailService.Provider = &smtp_email.SMTPEmailProvider{
+			Host: os.Getenv("SMTP_HOST"),
+			Port: os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}
+	case "file":
+		outputDir := os.Getenv("EMAIL_OUTPUT_DIR")
+		if outputDir == "" {
+			outputDir = "mailbox"
+		}
+		EmailService.Provider = &file_email.FileEmailProvider{
+			Dir: outputDir,
+		}
+	default:
+		EmailService.Provider = &sendgrid_email.SendGridEmailProvider{
+			API_KEY: os.Getenv("SENDGRID_API_KEY"),
+		}
+	}
+	return nil
+}
+
+func (m *Mailer) SendEmail(toEmail string, subject string, templateName string, opts interface{}) error {
+	if m.Provider == nil {
+		return errors.New("email service has not been initialised")
+	}
+	html, err := render(m.templates, templateName, opts)
+	if err != nil {
+		logger.Error(errors.New("could not render email"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		}, logger.LoggerOptions{
+			Key: "templateName",
+			Data: templateName,
+		})
+		return err
+	}
+	err = m.Provider.Send(&types.Email{
+		FromName: m.FromName,
+		From: m.From,
+		To: toEmail,
+		Subject: subject,
+		HTML: html,
+	})
+	if err != nil {
+		logger.Error(err, logger.LoggerOptions{
+			Key: "to",
+			Data: toEmail,
+		}, logger.LoggerOptions{
+			Key: "templateName",
+			Data: templateName,
+		})
+		return err
+	}
+	logger.Info(fmt.Sprintf("email sent to %s successfully", toEmail))
+	return nil
+}
@@ -0,0 +1,27 @@
+package sendgrid_email
+
+import (
+	"fmt"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"kego.com/infrastructure/messaging/emails/types"
+)
+
+type SendGridEmailProvider struct {
+	API_KEY string
+}
+
+func (s *SendGridEmailProvider) Send(email *types.Email) error {
+	from := mail.NewEmail(email.FromName, email.From)
+	to := mail.NewEmail(email.To, email.To)
+	message := mail.NewSingleEmail(from, email.Subject, to, "", email.HTML)
+	response, err := sendgrid.NewSendClient(s.API_KEY).Send(message)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != 202 {
+		return fmt.Errorf("sendgrid responded with status %d: %s", response.StatusCode, response.Body)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package smtp_email
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+
+	"kego.com/infrastructure/messaging/emails/types"
+)
+
+// SMTPEmailProvider sends through any SMTP server, e.g. a local Mailpit or MailHog instance.
+// Leave Username empty for servers that do not need authentication.
+type SMTPEmailProvider struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+func (s *SMTPEmailProvider) Send(email *types.Email) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", (&mailAddress{email.FromName, email.From}).String())
+	fmt.Fprintf(&message, "To: %s\r\n", email.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n\r\n")
+	message.WriteString(email.HTML)
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, email.From, []string{email.To}, message.Bytes())
+}
+
+type mailAddress struct {
+	name    string
+	address string
+}
+
+func (a *mailAddress) String() string {
+	if a.name == "" {
+		return a.address
+	}
+	return fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", a.name), a.address)
+}
@@ -0,0 +1,46 @@
+package emails
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrTemplateNotFound = errors.New("email template not found")
+
+var dir, _ = os.Getwd()
+
+var templatesDir = filepath.Join(dir, "/infrastructure/messaging/emails/templates")
+
+// parseTemplates reads every template in the templates folder once, keyed by file name without the extension.
+func parseTemplates(folder string) (map[string]*template.Template, error) {
+	files, err := filepath.Glob(filepath.Join(folder, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	templates := map[string]*template.Template{}
+	for _, file := range files {
+		parsed, err := template.ParseFiles(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse email template %s: %w", file, err)
+		}
+		templates[strings.TrimSuffix(filepath.Base(file), ".html")] = parsed
+	}
+	return templates, nil
+}
+
+func render(templates map[string]*template.Template, templateName string, opts interface{}) (string, error) {
+	tmpl, ok := templates[templateName]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrTemplateNotFound, templateName)
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, opts); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,14 @@
+package types
+
+type Email struct {
+	FromName string
+	From     string
+	To       string
+	Subject  string
+	HTML     string
+}
+
+// An email provider delivers emails that have already been rendered.
+type EmailProviderType interface {
+	Send(email *Email) error
+}
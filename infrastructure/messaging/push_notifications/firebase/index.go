@@ -47,23 +47,40 @@ func (fbpn *FireBasePushNotification) InitialiseClient() *FireBasePushNotificati
 }
 
 
-func (fbpn *FireBasePushNotification) PushOne(deviceID string, title string, body string) {
-	_, err := fbpn.MessagingClient.Send(context.Background(), &messaging.Message{
-		Notification: &messaging.Notification{
-			Title: title,
-			Body: body,
-		},
-		Token: deviceID,
-	})
-	if err != nil {
+func (fbpn *FireBasePushNotification) PushMany(tokens []string, title string, body string) ([]string, error) {
+	if fbpn == nil || fbpn.MessagingClient == nil {
+		return nil, errors.New("firebase messaging client is not initialised")
+	}
+	invalidTokens := []string{}
+	delivered := 0
+	var lastErr error
+	// each token is sent on its own since FCM no longer supports batch sends
+	for _, token := range tokens {
+		_, err := fbpn.MessagingClient.Send(context.Background(), &messaging.Message{
+			Notification: &messaging.Notification{
+				Title: title,
+				Body: body,
+			},
+			Token: token,
+		})
+		if err == nil {
+			delivered++
+			continue
+		}
+		// INVALID_ARGUMENT is also returned for a bad message, so only tokens FCM says are gone are pruned
+		if messaging.IsRegistrationTokenNotRegistered(err) {
+			invalidTokens = append(invalidTokens, token)
+			continue
+		}
 		logger.Error(errors.New("error sending push notification using Firebase"), logger.LoggerOptions{
 			Key: "error",
 			Data: err,
-		}, logger.LoggerOptions{
-			Key: "device_id",
-			Data: deviceID,
 		})
-		return
+		lastErr = err
 	}
-	logger.Info(fmt.Sprintf("successfully sent push notification to %s using Firebase", deviceID))
-}
\ No newline at end of file
+	logger.Info(fmt.Sprintf("push notification sent to %d of %d devices using Firebase", delivered, len(tokens)))
+	if delivered == 0 && lastErr != nil {
+		return invalidTokens, lastErr
+	}
+	return invalidTokens, nil
+}
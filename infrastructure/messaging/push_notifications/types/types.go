@@ -1,5 +1,7 @@
 package types
 
 type PushNotificationServiceType interface{
-	PushOne(deviceID string, header string,  body string)
-}
\ No newline at end of file
+	// PushMany sends the notification to every token and returns the tokens the provider no longer recognises
+	// so they can be removed. An error is only returned if no token could be reached for another reason.
+	PushMany(tokens []string, header string, body string) (invalidTokens []string, err error)
+}
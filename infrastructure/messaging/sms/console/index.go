@@ -0,0 +1,40 @@
+package console_sms
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"kego.com/infrastructure/logger"
+)
+
+// ConsoleSMSService stands in for an SMS provider during local development.
+// Messages are appended to FilePath if it is set. Otherwise only that a message was sent is logged,
+// never its body, since messages carry one-time passwords.
+type ConsoleSMSService struct {
+	FilePath string
+	mu       sync.Mutex
+}
+
+func (c *ConsoleSMSService) SendSMS(phoneNumber string, message string) error {
+	if c.FilePath == "" {
+		logger.Info("sms not delivered, set SMS_OUTPUT_FILE to read it", logger.LoggerOptions{
+			Key: "to",
+			Data: phoneNumber,
+		}, logger.LoggerOptions{
+			Key: "length",
+			Data: len(message),
+		})
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	file, err := os.OpenFile(c.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phoneNumber, message)
+	return err
+}
@@ -0,0 +1,41 @@
+package sms
+
+import (
+	"errors"
+	"os"
+
+	console_sms "kego.com/infrastructure/messaging/sms/console"
+	termii_sms "kego.com/infrastructure/messaging/sms/termii"
+	"kego.com/infrastructure/messaging/sms/types"
+	"kego.com/infrastructure/network"
+)
+
+var SMSService types.SMSServiceType
+
+// InitialiseSMSService picks the provider named by SMS_PROVIDER.
+// Anything other than termii writes messages to SMS_OUTPUT_FILE instead of sending them, which is refused in release mode.
+func InitialiseSMSService() error {
+	switch os.Getenv("SMS_PROVIDER") {
+	case "termii":
+		channel := os.Getenv("TERMII_CHANNEL")
+		if channel == "" {
+			channel = "dnd"
+		}
+		SMSService = &termii_sms.TermiiSMSService{
+			Network: &network.NetworkController{
+				BaseUrl: os.Getenv("TERMII_BASE_URL"),
+			},
+			API_KEY: os.Getenv("TERMII_API_KEY"),
+			SenderID: os.Getenv("TERMII_SENDER_ID"),
+			Channel: channel,
+		}
+	default:
+		if os.Getenv("GIN_MODE") == "release" {
+			return errors.New("SMS_PROVIDER must be set to a real provider in release mode")
+		}
+		SMSService = &console_sms.ConsoleSMSService{
+			FilePath: os.Getenv("SMS_OUTPUT_FILE"),
+		}
+	}
+	return nil
+}
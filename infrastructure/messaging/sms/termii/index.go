@@ -0,0 +1,55 @@
+package termii_sms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"kego.com/infrastructure/logger"
+	"kego.com/infrastructure/network"
+)
+
+type TermiiSMSService struct {
+	Network  *network.NetworkController
+	API_KEY  string
+	SenderID string
+	// "dnd" reaches numbers on the do-not-disturb list and is the channel Termii expects for OTPs and alerts
+	Channel string
+}
+
+func (t *TermiiSMSService) SendSMS(phoneNumber string, message string) error {
+	response, statusCode, err := t.Network.Post("/api/sms/send", nil, TermiiSendSMSPayload{
+		APIKey: t.API_KEY,
+		// termii expects the number without the leading +
+		To: strings.TrimPrefix(phoneNumber, "+"),
+		From: t.SenderID,
+		SMS: message,
+		Type: "plain",
+		Channel: t.Channel,
+	}, nil)
+	if err != nil {
+		logger.Error(errors.New("error sending sms through termii"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		return err
+	}
+	var termiiResponse TermiiSendSMSResponse
+	json.Unmarshal(*response, &termiiResponse)
+	if *statusCode != 200 || termiiResponse.Code != "ok" {
+		logger.Error(errors.New("termii could not send sms"), logger.LoggerOptions{
+			Key: "statusCode",
+			Data: *statusCode,
+		}, logger.LoggerOptions{
+			Key: "response",
+			Data: string(*response),
+		})
+		return fmt.Errorf("termii could not send sms: %s", termiiResponse.Message)
+	}
+	logger.Info("sms sent through termii", logger.LoggerOptions{
+		Key: "messageID",
+		Data: termiiResponse.MessageID,
+	})
+	return nil
+}
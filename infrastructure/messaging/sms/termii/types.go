@@ -0,0 +1,18 @@
+package termii_sms
+
+type TermiiSendSMSPayload struct {
+	APIKey  string `json:"api_key"`
+	To      string `json:"to"`
+	From    string `json:"from"`
+	SMS     string `json:"sms"`
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+}
+
+type TermiiSendSMSResponse struct {
+	Code      string  `json:"code"`
+	MessageID string  `json:"message_id"`
+	Message   string  `json:"message"`
+	Balance   float64 `json:"balance"`
+	User      string  `json:"user"`
+}
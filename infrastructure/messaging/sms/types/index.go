@@ -0,0 +1,6 @@
+package types
+
+type SMSServiceType interface {
+	// SendSMS sends a plain text message to a phone number in international format, e.g. +2348012345678.
+	SendSMS(phoneNumber string, message string) error
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"kego.com/application/interfaces"
+	"kego.com/application/middlewares"
+	"kego.com/entities"
+)
+
+// APIKeyMiddleware is used on the server-to-server routes in place of UserAgentMiddleware and AuthenticationMiddleware.
+// The routes must take a :businessID.
+func APIKeyMiddleware(permission entities.BusinessPermission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		appContext, next := middlewares.APIKeyMiddleware(&interfaces.ApplicationContext[any]{
+			Ctx:    ctx,
+			Keys:   ctx.Keys,
+			Header: ctx.Request.Header,
+		}, ctx.Param("businessID"), apiKeyClientIP(ctx), permission)
+		if next {
+			ctx.Set("AppContext", appContext)
+			ctx.Next()
+		}
+	}
+}
+
+// apiKeyClientIP is the address checked against a key's allowlist, which stands in for a pin. Forwarded
+// addresses are only used when TRUSTED_PROXIES says which proxies may set them, otherwise it is the address
+// of the connection, so a caller cannot claim to be an allowed server with X-Forwarded-For.
+func apiKeyClientIP(ctx *gin.Context) string {
+	if os.Getenv("TRUSTED_PROXIES") == "" {
+		return ctx.RemoteIP()
+	}
+	return ctx.ClientIP()
+}
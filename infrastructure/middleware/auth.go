@@ -12,7 +12,7 @@ func AuthenticationMiddleware(admin_route bool) gin.HandlerFunc {
 			Ctx:    ctx,
 			Keys:   ctx.Keys,
 			Header: ctx.Request.Header,
-		})
+		}, admin_route)
 		if next {
 			ctx.Set("AppContext", appContext)
 			ctx.Next()
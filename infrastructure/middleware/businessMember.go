@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"kego.com/application/interfaces"
+	"kego.com/application/middlewares"
+	"kego.com/entities"
+)
+
+// BusinessMemberMiddleware must come after AuthenticationMiddleware on routes that take a :businessID.
+func BusinessMemberMiddleware(permission entities.BusinessPermission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+		appContext, next := middlewares.BusinessMemberMiddleware(appContextAny, ctx.Param("businessID"), permission)
+		if next {
+			ctx.Set("AppContext", appContext)
+			ctx.Next()
+		}
+	}
+}
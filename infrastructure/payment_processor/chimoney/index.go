@@ -5,11 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"kego.com/entities"
 	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/network"
+	"kego.com/infrastructure/payment_processor/types"
 )
 
 
@@ -29,20 +30,20 @@ func (chimoneyPP *ChimoneyPaymentProcessor) InitialisePaymentProcessor() {
 }
 
 
-func (chimoneyPP *ChimoneyPaymentProcessor)GetExchangeRates(currency any, amount any) (*map[string]float32, int, error){
+func (chimoneyPP *ChimoneyPaymentProcessor)FetchExchangeRates() (*entities.ExchangeRates, int, error){
 	response, statusCode, err := chimoneyPP.Network.Get("/info/exchange-rates", &map[string]string{
 		"X-API-KEY": chimoneyPP.AuthToken,
 		"Content-Type": "application/json",
 	}, nil)
-	var chimoneyResponse ChimoneyExchangeRateDTO
-	json.Unmarshal(*response, &chimoneyResponse)
 	if err != nil {
 		logger.Error(errors.New("an error occured while fetching exchange rates on chimoney"), logger.LoggerOptions{
 			Key: "error",
 			Data: err,
 		})
-		return nil, *statusCode, errors.New("an error occured while fetching exchange rates on chimoney")
+		return nil, 0, errors.New("an error occured while fetching exchange rates on chimoney")
 	}
+	var chimoneyResponse ChimoneyExchangeRateDTO
+	json.Unmarshal(*response, &chimoneyResponse)
 	if *statusCode != 200 {
 		err = errors.New("failed to fetch exchange rates")
 		logger.Error(err, logger.LoggerOptions{
@@ -52,31 +53,9 @@ func (chimoneyPP *ChimoneyPaymentProcessor)GetExchangeRates(currency any, amount
 			Key: "body",
 			Data: chimoneyResponse,
 		})
-		return nil, *statusCode, nil
+		return nil, *statusCode, err
 	}
-	rateResponse := map[string]float32{}
-	formattedRates := chimoneyResponse.Data.FormatAllRates()
-	if currency != "" {
-		for key, rate := range formattedRates {
-			if strings.Contains(key, currency.(string)) {
-				rateResponse = map[string]float32{
-					"rate": rate,
-				}
-				break
-			}
-		}
-		if rateResponse["rate"] == 0 {
-			return nil, *statusCode, fmt.Errorf("Currency %s is not supported", currency)
-		}
-
-		if amount != "" {
-				rateResponse["convertToUSD"] = (rateResponse["rate"] * float32(amount.(uint64))) / chimoneyResponse.Data.USDNGN
-				rateResponse["convertedValue"] = rateResponse["rate"] * float32(amount.(uint64))
-		}
-	}else {
-		rateResponse = formattedRates
-	}
-	return &rateResponse, *statusCode, nil
+	return &chimoneyResponse.Data, *statusCode, nil
 }
 
 func (chimoneyPP *ChimoneyPaymentProcessor)GetSupportedInternationalBanks(countryCode string) (*[]entities.Bank,  int, error) {
@@ -142,4 +121,43 @@ func (chimoneyPP *ChimoneyPaymentProcessor)InitiateInternationalPayment(payload
 		return &chimoneyResponse.Data, *statusCode, nil
 	}
 	return &chimoneyResponse.Data, *statusCode, nil
-}
\ No newline at end of file
+}
+
+// FetchPayouts returns the payouts on the account issued between the two dates. Values are in dollars.
+func (chimoneyPP *ChimoneyPaymentProcessor)FetchPayouts(from time.Time, to time.Time) (*[]types.TransferRecord, error) {
+	response, statusCode, err := chimoneyPP.Network.Post("/accounts/transactions", &map[string]string{
+		"X-API-KEY": chimoneyPP.AuthToken,
+		"Content-Type": "application/json",
+	}, map[string]any{}, nil)
+	if err != nil {
+		logger.Error(errors.New("an error occured while fetching payouts on chimoney"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		return nil, errors.New("an error occured while fetching payouts on chimoney")
+	}
+	var chimoneyResponse ChimoneyTransactionsDTO
+	json.Unmarshal(*response, &chimoneyResponse)
+	if *statusCode != 200 {
+		err = errors.New("failed to fetch payouts")
+		logger.Error(err, logger.LoggerOptions{
+			Key: "body",
+			Data: chimoneyResponse,
+		})
+		return nil, err
+	}
+	records := []types.TransferRecord{}
+	for _, transaction := range chimoneyResponse.Data {
+		if transaction.ChiRef == "" || transaction.IssueDate.Before(from) || transaction.IssueDate.After(to) {
+			continue
+		}
+		records = append(records, types.TransferRecord{
+			Reference: transaction.ChiRef,
+			Amount: transaction.ValueInUSD.String(),
+			Currency: "USD",
+			Status: transaction.Status,
+			CreatedAt: transaction.IssueDate,
+		})
+	}
+	return &records, nil
+}
@@ -1,6 +1,11 @@
 package chimoney_international_payment_processor
 
-import "kego.com/entities"
+import (
+	"encoding/json"
+	"time"
+
+	"kego.com/entities"
+)
 
 
 type ChimoneyExchangeRateDTO struct {
@@ -25,7 +30,7 @@ type InternationalPaymentRequestPayload struct {
 	DestinationCountry string `json:"countryToSend"`
 	BankCode string `json:"account_bank"`
 	AccountNumber string `json:"account_number"`
-	ValueInUSD float32 `json:"valueInUSD"`
+	ValueInUSD json.Number `json:"valueInUSD"` // dollars, e.g. 120.45
 }
 
 type InternationalPaymentRequestResponseDataPayload struct {
@@ -39,10 +44,25 @@ type InternationalPaymentRequestResponseDataChimoneyPayload struct {
 	Type string `json:"type"`
 	ChiRef string `json:"chiRef"`
 	Status string `json:"status"`
-	ValueInUSD float32 `json:"valueInUSD"`
+	ValueInUSD json.Number `json:"valueInUSD"`
 	CountrySentTo string `json:"countryToSend"`
-	Fee float32 `json:"fee"`
+	Fee json.Number `json:"fee"`
 	IssueDate string `json:"issueDate"`
 	Issuer string `json:"issuer"`
 	IssueID string `json:"issueID"`
 }
+
+type ChimoneyTransactionsDTO struct {
+	Error 	string	   			    `json:"error"`
+	Status 	string					`json:"status"`
+	Data	[]ChimoneyTransaction	`json:"data"`
+}
+
+type ChimoneyTransaction struct {
+	ID 			string 		`json:"id"`
+	ChiRef 		string 		`json:"chiRef"`
+	Type 		string 		`json:"type"`
+	Status 		string 		`json:"status"`
+	ValueInUSD 	json.Number `json:"valueInUSD"`
+	IssueDate 	time.Time 	`json:"issueDate"`
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/network"
@@ -117,3 +118,53 @@ func (fpp *FlutterwavePaymentProcessor) NameVerification(accountNumber string, b
 	}
 	return &flwResponse.Data, statusCode, nil
 }
+
+
+// FetchTransfers pages through every transfer made between the two dates.
+func (fpp *FlutterwavePaymentProcessor) FetchTransfers(from time.Time, to time.Time) (*[]types.TransferRecord, error) {
+	records := []types.TransferRecord{}
+	for page := 1; ; page++ {
+		response, statusCode, err := fpp.Network.Get("/transfers", &map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", fpp.AuthToken),
+			"Content-Type": "application/json",
+		}, &map[string]string{
+			"from": from.Format("2006-01-02"),
+			"to": to.Format("2006-01-02"),
+			"page": fmt.Sprintf("%d", page),
+		})
+		if err != nil {
+			logger.Error(errors.New("an error occured while fetching transfers on flutterwave"), logger.LoggerOptions{
+				Key: "error",
+				Data: err,
+			})
+			return nil, errors.New("an error occured while fetching transfers")
+		}
+		var flwResponse FetchTransfersResponse
+		json.Unmarshal(*response, &flwResponse)
+		if *statusCode != 200 {
+			err = errors.New("failed to fetch transfers")
+			logger.Error(err, logger.LoggerOptions{
+				Key: "body",
+				Data: flwResponse,
+			})
+			return nil, err
+		}
+		for _, transfer := range flwResponse.Data {
+			// the API filters by day so the ends of the period are trimmed here
+			if transfer.CreatedAt.Before(from) || transfer.CreatedAt.After(to) {
+				continue
+			}
+			records = append(records, types.TransferRecord{
+				Reference: transfer.Reference,
+				Amount: transfer.Amount.String(),
+				Currency: transfer.Currency,
+				Status: transfer.Status,
+				CreatedAt: transfer.CreatedAt,
+			})
+		}
+		if page >= flwResponse.Meta.PageInfo.TotalPages {
+			break
+		}
+	}
+	return &records, nil
+}
@@ -1 +1,31 @@
 package flutterwave_local_payment_processor
+
+import (
+	"encoding/json"
+	"time"
+)
+
+
+type FetchTransfersResponse struct {
+	Status 	string 				`json:"status"`
+	Message string 				`json:"message"`
+	Meta 	FetchTransfersMeta 	`json:"meta"`
+	Data 	[]Transfer 			`json:"data"`
+}
+
+type FetchTransfersMeta struct {
+	PageInfo struct {
+		Total 		int `json:"total"`
+		CurrentPage int `json:"current_page"`
+		TotalPages 	int `json:"total_pages"`
+	} `json:"page_info"`
+}
+
+type Transfer struct {
+	ID 			int64 		`json:"id"`
+	Reference 	string 		`json:"reference"`
+	Amount 		json.Number `json:"amount"`
+	Currency 	string 		`json:"currency"`
+	Status 		string 		`json:"status"`
+	CreatedAt 	time.Time 	`json:"created_at"`
+}
@@ -1,10 +1,25 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type LocalPaymentProcessorType interface {
 	InitialisePaymentProcessor()
 	NameVerification(accountNumber string, bankCode string) (*NameVerificationResponseField, *int, error)
 	InitiateLocalTransfer(payload *InitiateLocalTransferPayload) (*InitiateLocalTransferDataField, *int, error)
 	GenerateDVA(payload *CreateVirtualAccountPayload) (*VirtualAccountPayload, error)
+	FetchTransfers(from time.Time, to time.Time) (*[]TransferRecord, error)
+}
+
+// A transfer as the processor recorded it. Amount is in major units as the processor reports it.
+type TransferRecord struct {
+	Reference 	string 		`json:"reference"`
+	Amount 		string 		`json:"amount"`
+	Currency 	string 		`json:"currency"`
+	Status 		string 		`json:"status"`
+	CreatedAt 	time.Time 	`json:"createdAt"`
 }
 
 type NameVerificationResponseDTO struct {
@@ -22,7 +37,7 @@ type NameVerificationResponseField struct {
 type InitiateLocalTransferPayload struct {
 	AccountBank 	string		`json:"account_bank"`
 	AccountNumber 	string		`json:"account_number"`
-	Amount 			uint64		`json:"amount"`
+	Amount 			json.Number	`json:"amount"` // major units, e.g. 1500.50
 	Narration 		string		`json:"narration"`
 	Currency 		string		`json:"currency"`
 	Reference 		string		`json:"reference"`
@@ -39,7 +54,7 @@ type InitiateLocalTransferPayloadResponse struct {
 type InitiateLocalTransferDataField struct {
 	BankName 	string			`json:"bank_name"`
 	FullName 	string			`json:"full_name"`
-	Fee			float32			`json:"fee"`
+	Fee			json.Number		`json:"fee"`
 }
 
 type CreateVirtualAccountPayload struct {
@@ -67,7 +82,7 @@ type VirtualAccountPayload struct {
 	BankName 				string							`json:"bank_name"`
 	Status 					string							`json:"status"`
 	Message 				string							`json:"message"`
-	Amount 					float32							`json:"amount"`
+	Amount 					json.Number						`json:"amount"`
 	Note 					string							`json:"note"`
 	CreatedAt 				float32							`json:"created_at"`
 	ExpiryDate 				float32							`json:"1703031769350"`
@@ -0,0 +1,210 @@
+package authroutev1
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	middlewares "kego.com/infrastructure/middleware"
+)
+
+
+func AdminRouter(router *gin.RouterGroup) {
+	adminRouter := router.Group("/admin")
+	{
+		adminRouter.POST("/pricing-plans", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.PricingPlanDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.PricingPlanDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.CreatePricingPlan(&appContext)
+		})
+
+		adminRouter.GET("/pricing-plans", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"code": ctx.Query("code"),
+				},
+			}
+			controllers.FetchPricingPlans(&appContext)
+		})
+
+		adminRouter.PATCH("/business/:businessID/pricing-plan", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.AssignPricingPlanDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.AssignPricingPlanDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.AssignBusinessPricingPlan(&appContext)
+		})
+
+		adminRouter.POST("/reconciliations", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.ReconciliationDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.ReconciliationDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.StartReconciliation(&appContext)
+		})
+
+		adminRouter.POST("/reconciliations/upload", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			file, err := ctx.FormFile("file")
+			if err != nil || file == nil {
+				apperrors.ClientError(ctx, "upload the processor's report as file", nil)
+				return
+			}
+			from, err := time.Parse(time.RFC3339, ctx.PostForm("from"))
+			if err != nil {
+				apperrors.ClientError(ctx, "pass in from as a date in the RFC3339 format", nil)
+				return
+			}
+			to, err := time.Parse(time.RFC3339, ctx.PostForm("to"))
+			if err != nil {
+				apperrors.ClientError(ctx, "pass in to as a date in the RFC3339 format", nil)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.ReconciliationUploadDTO]{
+				Keys: appContextAny.Keys,
+				Body: &dto.ReconciliationUploadDTO{
+					Provider: ctx.PostForm("provider"),
+					From: from,
+					To: to,
+					File: file,
+				},
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.UploadReconciliationFile(&appContext)
+		})
+
+		adminRouter.GET("/reconciliations", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"provider": ctx.Query("provider"),
+				},
+			}
+			controllers.FetchReconciliationRuns(&appContext)
+		})
+
+		adminRouter.GET("/reconciliations/:runID", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"resolution": ctx.Query("resolution"),
+					"discrepancy": ctx.Query("discrepancy"),
+					"page": ctx.Query("page"),
+				},
+			}
+			appContext.Param = map[string]any{
+				"runID": ctx.Param("runID"),
+			}
+			controllers.FetchReconciliationReport(&appContext)
+		})
+
+		adminRouter.PATCH("/reconciliations/items/:itemID", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.ResolveReconciliationItemDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.ResolveReconciliationItemDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"itemID": ctx.Param("itemID"),
+			}
+			controllers.ResolveReconciliationItem(&appContext)
+		})
+
+		adminRouter.GET("/events/dead-letters", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"consumer": ctx.Query("consumer"),
+					"requeued": ctx.Query("requeued"),
+				},
+			}
+			controllers.FetchDeadLetterEvents(&appContext)
+		})
+
+		adminRouter.POST("/events/dead-letters/:deadLetterID/requeue", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"deadLetterID": ctx.Param("deadLetterID"),
+			}
+			controllers.RequeueDeadLetterEvent(&appContext)
+		})
+
+		adminRouter.GET("/business/kyb", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"status": ctx.Query("status"),
+				},
+			}
+			controllers.FetchBusinessKYBReviews(&appContext)
+		})
+
+		adminRouter.POST("/business/:businessID/kyb/review", middlewares.AuthenticationMiddleware(true), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.ReviewBusinessKYBDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.ReviewBusinessKYBDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.ReviewBusinessKYB(&appContext)
+		})
+	}
+}
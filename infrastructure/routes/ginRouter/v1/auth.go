@@ -1,13 +1,17 @@
 package authroutev1
 
 import (
+	"os"
+
 	"github.com/gin-gonic/gin"
 	apperrors "kego.com/application/appErrors"
 	"kego.com/application/controllers"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
 	"kego.com/application/utils"
+	"kego.com/entities"
 	middlewares "kego.com/infrastructure/middleware"
+	ratelimiter "kego.com/infrastructure/rateLimiter"
 )
 
 
@@ -34,7 +38,7 @@ func AuthRouter(router *gin.RouterGroup) {
 			})
 		})
 
-		authRouter.POST("/account/login", func(ctx *gin.Context) {
+		authRouter.POST("/account/login", ratelimiter.RateLimiter(6, 10, "-login"), func(ctx *gin.Context) {
 			var body dto.LoginDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
 				apperrors.ErrorProcessingPayload(ctx)
@@ -46,6 +50,11 @@ func AuthRouter(router *gin.RouterGroup) {
 				return
 			}
 			body.DeviceID = deviceID
+			body.IPAddress = ctx.ClientIP()
+			body.Location = entities.SessionLocation{
+				Country: ctx.GetHeader(geoHeader("GEOIP_COUNTRY_HEADER", "CF-IPCountry")),
+				City: ctx.GetHeader(geoHeader("GEOIP_CITY_HEADER", "CF-IPCity")),
+			}
 			controllers.LoginUser(&interfaces.ApplicationContext[dto.LoginDTO]{
 				Ctx: ctx,
 				Body: &body,
@@ -53,9 +62,50 @@ func AuthRouter(router *gin.RouterGroup) {
 			})
 		})
 
-		authRouter.GET("/otp/resend", func(ctx *gin.Context) {
+		authRouter.POST("/account/login/2fa", ratelimiter.RateLimiter(6, 10, "-login-2fa"), func(ctx *gin.Context) {
+			var body dto.TwoFactorLoginDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			deviceID := ctx.GetHeader("polymer-device-id")
+			if deviceID == "" {
+				apperrors.AuthenticationError(ctx, "no client id")
+				return
+			}
+			body.DeviceID = deviceID
+			body.IPAddress = ctx.ClientIP()
+			controllers.CompleteTwoFactorLogin(&interfaces.ApplicationContext[dto.TwoFactorLoginDTO]{
+				Ctx: ctx,
+				Body: &body,
+				Header: ctx.Request.Header,
+			})
+		})
+
+		authRouter.POST("/token/refresh", ratelimiter.RateLimiter(6, 10, "-token-refresh"), func(ctx *gin.Context) {
+			var body dto.RefreshTokenDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			deviceID := ctx.GetHeader("polymer-device-id")
+			if deviceID == "" {
+				apperrors.AuthenticationError(ctx, "no client id")
+				return
+			}
+			body.DeviceID = deviceID
+			controllers.RefreshAuthToken(&interfaces.ApplicationContext[dto.RefreshTokenDTO]{
+				Ctx: ctx,
+				Body: &body,
+				Header: ctx.Request.Header,
+			})
+		})
+
+		authRouter.GET("/otp/resend", ratelimiter.RateLimiter(30, 5, "-otp-resend"), func(ctx *gin.Context) {
 			query := map[string]any{
 				"email": ctx.Query("email"),
+				"channel": ctx.Query("channel"),
+				"purpose": ctx.Query("purpose"),
 			}
 			controllers.ResendOTP(&interfaces.ApplicationContext[any]{
 				Ctx: ctx,
@@ -63,19 +113,20 @@ func AuthRouter(router *gin.RouterGroup) {
 			})
 		})
 
-		authRouter.POST("/email/verify", func(ctx *gin.Context) {
+		authRouter.POST("/email/verify", ratelimiter.RateLimiter(6, 10, "-email-verify"), func(ctx *gin.Context) {
 			var body dto.VerifyEmailData
 			if err := ctx.ShouldBindJSON(&body); err != nil {
 				apperrors.ErrorProcessingPayload(ctx)
 				return
 			}
+			body.IPAddress = ctx.ClientIP()
 			controllers.VerifyEmail(&interfaces.ApplicationContext[dto.VerifyEmailData]{
 				Ctx: ctx,
 				Body: &body,
 			})
 		})
 
-		authRouter.GET("/account/exits",  func(ctx *gin.Context) {
+		authRouter.GET("/account/exits", ratelimiter.RateLimiter(6, 10, "-account-exists"), func(ctx *gin.Context) {
 			query := map[string]any{
 				"email": ctx.Query("email"),
 			}
@@ -98,24 +149,51 @@ func AuthRouter(router *gin.RouterGroup) {
 			}
 			body.ProfileImage = file
 			body.Email = ctx.Query("email")
+			body.NIN = ctx.PostForm("nin")
 			controllers.VerifyAccount(&interfaces.ApplicationContext[dto.VerifyAccountData]{
 				Ctx: ctx,
 				Body: &body,
 			})
 		})
 
-		authRouter.POST("/account/password/reset",  func(ctx *gin.Context) {
+		authRouter.POST("/account/password/reset", ratelimiter.RateLimiter(6, 10, "-password-reset"), func(ctx *gin.Context) {
 			var body dto.ResetPasswordDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
 				apperrors.ErrorProcessingPayload(ctx)
 				return
 			}
+			body.IPAddress = ctx.ClientIP()
 			controllers.ResetPassword(&interfaces.ApplicationContext[dto.ResetPasswordDTO]{
 				Ctx: ctx,
 				Body: &body,
 			})
 		})
 
+		authRouter.POST("/account/unlock/otp", ratelimiter.RateLimiter(30, 5, "-unlock-otp"), func(ctx *gin.Context) {
+			var body dto.RequestAccountUnlockDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			controllers.RequestAccountUnlock(&interfaces.ApplicationContext[dto.RequestAccountUnlockDTO]{
+				Ctx: ctx,
+				Body: &body,
+			})
+		})
+
+		authRouter.POST("/account/unlock", ratelimiter.RateLimiter(6, 10, "-unlock"), func(ctx *gin.Context) {
+			var body dto.UnlockAccountDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			controllers.UnlockAccount(&interfaces.ApplicationContext[dto.UnlockAccountDTO]{
+				Ctx: ctx,
+				Body: &body,
+			})
+		})
+
 		authRouter.POST("/account/password/update", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.UpdatePassword
@@ -131,6 +209,46 @@ func AuthRouter(router *gin.RouterGroup) {
 			controllers.UpdatePassword(&appContext)
 		})
 
+		authRouter.POST("/account/pin/update", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.UpdateTransactionPinDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.UpdateTransactionPinDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.UpdateTransactionPin(&appContext)
+		})
+
+		authRouter.POST("/account/pin/reset/otp", ratelimiter.RateLimiter(30, 5, "-pin-reset-otp"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.RequestTransactionPinReset(appContext)
+		})
+
+		authRouter.POST("/account/pin/reset", ratelimiter.RateLimiter(6, 10, "-pin-reset"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.ResetTransactionPinDTO
+			file, err := ctx.FormFile("selfie")
+			if err != nil || file == nil {
+				apperrors.NotFoundError(ctx, "pass in a picture of yourself to reset your pin")
+				return
+			}
+			body.Selfie = file
+			body.Otp = ctx.PostForm("otp")
+			body.NewPin = ctx.PostForm("newPin")
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.ResetTransactionPinDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.ResetTransactionPin(&appContext)
+		})
+
 		authRouter.POST("/account/deactivate", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.ConfirmPin
@@ -147,3 +265,11 @@ func AuthRouter(router *gin.RouterGroup) {
 		})
 	}
 }
+
+// geoHeader is the request header the proxy in front of the api puts the client's location in.
+func geoHeader(env string, fallback string) string {
+	if header := os.Getenv(env); header != "" {
+		return header
+	}
+	return fallback
+}
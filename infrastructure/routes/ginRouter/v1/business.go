@@ -6,7 +6,9 @@ import (
 	"kego.com/application/controllers"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
+	"kego.com/entities"
 	middlewares "kego.com/infrastructure/middleware"
+	ratelimiter "kego.com/infrastructure/rateLimiter"
 )
 
 
@@ -28,7 +30,7 @@ func BusinessRouter(router *gin.RouterGroup) {
 			controllers.CreateBusiness(&appContext)
 		})
 
-		businessRouter.PATCH("/:businessID/update", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		businessRouter.PATCH("/:businessID/update", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageSettings), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.UpdateBusinessDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -55,7 +57,7 @@ func BusinessRouter(router *gin.RouterGroup) {
 			controllers.FetchBusinesses(&appContext)
 		})
 
-		businessRouter.DELETE("/:businessID/delete", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		businessRouter.DELETE("/:businessID/delete", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.CloseBusiness), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			appContext := interfaces.ApplicationContext[any]{
 				Keys: appContextAny.Keys,
@@ -66,5 +68,287 @@ func BusinessRouter(router *gin.RouterGroup) {
 			}
 			controllers.DeleteBusiness(&appContext)
 		})
+
+		businessRouter.PUT("/:businessID/kyb", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageSettings), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.BusinessKYBDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.BusinessKYBDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.SaveBusinessKYBDetails(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/kyb/documents", ratelimiter.RateLimiter(6, 10, "-business-documents"), middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageSettings), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.BusinessDocumentDTO
+			body.Type = entities.BusinessDocumentType(ctx.PostForm("type"))
+			if file, err := ctx.FormFile("document"); err == nil {
+				body.File = file
+			}
+			appContext := interfaces.ApplicationContext[dto.BusinessDocumentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.UploadBusinessDocument(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/kyb/submit", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageSettings), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.SubmitBusinessKYB(&appContext)
+		})
+
+		businessRouter.GET("/:businessID/members", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageMembers), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchBusinessMembers(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/members/invite", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageMembers), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.InviteBusinessMemberDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.InviteBusinessMemberDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.InviteBusinessMember(&appContext)
+		})
+
+		businessRouter.PATCH("/:businessID/members/:memberID", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageMembers), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.UpdateBusinessMemberDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.UpdateBusinessMemberDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+				"memberID": ctx.Param("memberID"),
+			}
+			controllers.UpdateBusinessMember(&appContext)
+		})
+
+		businessRouter.DELETE("/:businessID/members/:memberID", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageMembers), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+				"memberID": ctx.Param("memberID"),
+			}
+			controllers.RemoveBusinessMember(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/leave", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ViewBalances), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.LeaveBusiness(&appContext)
+		})
+
+		businessRouter.GET("/invites", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.FetchBusinessInvites(&appContext)
+		})
+
+		businessRouter.POST("/invites/:inviteID/accept", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"inviteID": ctx.Param("inviteID"),
+			}
+			controllers.AcceptBusinessInvite(&appContext)
+		})
+
+		businessRouter.DELETE("/invites/:inviteID", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"inviteID": ctx.Param("inviteID"),
+			}
+			controllers.DeclineBusinessInvite(&appContext)
+		})
+
+		businessRouter.GET("/:businessID/payout-approval-policy", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ViewBalances), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchPayoutApprovalPolicy(&appContext)
+		})
+
+		businessRouter.PUT("/:businessID/payout-approval-policy", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManagePayoutApprovals), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.PayoutApprovalPolicyDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.PayoutApprovalPolicyDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.SetPayoutApprovalPolicy(&appContext)
+		})
+
+		businessRouter.GET("/:businessID/payout-approvals", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ViewBalances), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"status": ctx.Query("status"),
+				},
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchPayoutApprovals(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/payout-approvals/:approvalID/approve", ratelimiter.RateLimiter(6, 10, "-payout-approve"), middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.PayoutApprovalDecisionDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.PayoutApprovalDecisionDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+				"approvalID": ctx.Param("approvalID"),
+			}
+			controllers.ApprovePayout(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/payout-approvals/:approvalID/reject", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.PayoutApprovalDecisionDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.PayoutApprovalDecisionDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+				"approvalID": ctx.Param("approvalID"),
+			}
+			controllers.RejectPayout(&appContext)
+		})
+
+		businessRouter.GET("/:businessID/api-keys", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageAPIKeys), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchBusinessAPIKeys(&appContext)
+		})
+
+		businessRouter.POST("/:businessID/api-keys", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageAPIKeys), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.CreateBusinessAPIKeyDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.CreateBusinessAPIKeyDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.CreateBusinessAPIKey(&appContext)
+		})
+
+		businessRouter.DELETE("/:businessID/api-keys/:keyID", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ManageAPIKeys), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+				"keyID": ctx.Param("keyID"),
+			}
+			controllers.RevokeBusinessAPIKey(&appContext)
+		})
 	}
 }
@@ -57,5 +57,27 @@ func InfoRouter(router *gin.RouterGroup) {
 				Query: query,
 			})
 		})
+
+		infoRouter.GET("/exchange-rates/at", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			query := map[string]any{
+				"date": ctx.Query("date"),
+			}
+			controllers.FetchExchangeRatesAtDate(&interfaces.ApplicationContext[any]{
+				Ctx: ctx,
+				Query: query,
+			})
+		})
+
+		infoRouter.GET("/exchange-rates/history", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			query := map[string]any{
+				"currency": ctx.Query("currency"),
+				"from": ctx.Query("from"),
+				"to": ctx.Query("to"),
+			}
+			controllers.FetchExchangeRateHistory(&interfaces.ApplicationContext[any]{
+				Ctx: ctx,
+				Query: query,
+			})
+		})
 	}
 }
@@ -0,0 +1,50 @@
+package authroutev1
+
+import (
+	"github.com/gin-gonic/gin"
+	"kego.com/application/controllers"
+	"kego.com/application/interfaces"
+	middlewares "kego.com/infrastructure/middleware"
+)
+
+
+func NotificationRouter(router *gin.RouterGroup) {
+	notificationRouter := router.Group("/notifications")
+	{
+		notificationRouter.GET("", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+				Query: map[string]any{
+					"page": ctx.Query("page"),
+					"limit": ctx.Query("limit"),
+					"unread": ctx.Query("unread"),
+				},
+			}
+			controllers.FetchNotifications(&appContext)
+		})
+
+		notificationRouter.GET("/unread-count", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.CountUnreadNotifications(appContext)
+		})
+
+		notificationRouter.PATCH("/read-all", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.MarkAllNotificationsRead(appContext)
+		})
+
+		notificationRouter.PATCH("/:notificationID/read", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"notificationID": ctx.Param("notificationID"),
+			}
+			controllers.MarkNotificationRead(&appContext)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package authroutev1
+
+import (
+	"github.com/gin-gonic/gin"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/controllers"
+	"kego.com/application/controllers/dto"
+	"kego.com/application/interfaces"
+	"kego.com/entities"
+	middlewares "kego.com/infrastructure/middleware"
+)
+
+// S2SRouter has the wallet routes a business's own systems can call with an API key. The key's scopes stand in
+// for a member's role.
+func S2SRouter(router *gin.RouterGroup) {
+	walletRouter := router.Group("/wallet")
+	{
+		walletRouter.POST("/:businessID/payment/international/send", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.SendPaymentDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.SendPaymentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.InitiateBusinessInternationalPayment(&appContext)
+		})
+
+		walletRouter.POST("/:businessID/payment/local/send", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.SendPaymentDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.SendPaymentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.InitiateBusinessLocalPayment(&appContext)
+		})
+
+		walletRouter.POST("/:businessID/payment/local/fee", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.SendPaymentDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.SendPaymentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.BusinessLocalPaymentFee(&appContext)
+		})
+
+		walletRouter.POST("/:businessID/payment/international/fee", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.SendPaymentDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			body.IPAddress = ctx.ClientIP()
+			appContext := interfaces.ApplicationContext[dto.SendPaymentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.BusinessInternationalPaymentFee(&appContext)
+		})
+		
+		walletRouter.POST("/:businessID/payment/international/quote", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.InternationalPaymentQuoteDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.InternationalPaymentQuoteDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.BusinessInternationalPaymentQuote(&appContext)
+		})
+
+		walletRouter.POST("/:businessID/payment/local/verify-name", middlewares.APIKeyMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.NameVerificationDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.NameVerificationDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.VerifyLocalAccountName(&appContext)
+		})
+
+		walletRouter.GET("/:businessID", middlewares.APIKeyMiddleware(entities.ViewBalances), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchBusinessWallet(&appContext)
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"kego.com/application/controllers"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
+	"kego.com/entities"
 	middlewares "kego.com/infrastructure/middleware"
+	ratelimiter "kego.com/infrastructure/rateLimiter"
 )
 
 
@@ -47,5 +49,226 @@ func UserRouter(router *gin.RouterGroup) {
 			}
 			controllers.SetPaymentTag(&appContext)
 		})
+
+		userRouter.GET("/notification-preferences", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchNotificationPreferences(appContext)
+		})
+
+		userRouter.PATCH("/notification-preferences", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.NotificationPreferencesDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.NotificationPreferencesDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.UpdateNotificationPreferences(&appContext)
+		})
+
+		userRouter.GET("/devices", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchDevices(appContext)
+		})
+
+		userRouter.PATCH("/devices/push-token", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.UpdatePushTokenDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.UpdatePushTokenDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.UpdateDevicePushToken(&appContext)
+		})
+
+		userRouter.DELETE("/devices/:deviceID", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"deviceID": ctx.Param("deviceID"),
+			}
+			controllers.RemoveDevice(&appContext)
+		})
+
+		userRouter.GET("/sessions", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchActiveSessions(appContext)
+		})
+
+		userRouter.DELETE("/sessions", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.RevokeOtherSessions(appContext)
+		})
+
+		userRouter.DELETE("/sessions/:sessionID", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"sessionID": ctx.Param("sessionID"),
+			}
+			controllers.RevokeSession(&appContext)
+		})
+		userRouter.GET("/2fa", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchTwoFactorStatus(appContext)
+		})
+
+		userRouter.POST("/2fa/enroll", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.EnrollTwoFactor(appContext)
+		})
+
+		userRouter.POST("/2fa/confirm", ratelimiter.RateLimiter(6, 10, "-2fa-confirm"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.TwoFactorCodeDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.TwoFactorCodeDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.ConfirmTwoFactor(&appContext)
+		})
+
+		userRouter.DELETE("/2fa", ratelimiter.RateLimiter(6, 10, "-2fa-disable"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.DisableTwoFactorDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.DisableTwoFactorDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.DisableTwoFactor(&appContext)
+		})
+
+		userRouter.POST("/2fa/recovery-codes", ratelimiter.RateLimiter(6, 10, "-2fa-recovery-codes"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.TwoFactorCodeDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.TwoFactorCodeDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.RegenerateRecoveryCodes(&appContext)
+		})
+
+		userRouter.PATCH("/2fa/payout-threshold", ratelimiter.RateLimiter(6, 10, "-2fa-payout-threshold"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.PayoutTwoFactorThresholdDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.PayoutTwoFactorThresholdDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.SetPayoutTwoFactorThreshold(&appContext)
+		})
+		userRouter.POST("/data-exports", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.VerifyPassword
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.VerifyPassword]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.RequestDataExport(&appContext)
+		})
+
+		userRouter.GET("/data-exports/:requestID/download", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"requestID": ctx.Param("requestID"),
+			}
+			controllers.DownloadDataExport(&appContext)
+		})
+
+		userRouter.POST("/erasure", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.VerifyPassword
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.VerifyPassword]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.RequestAccountErasure(&appContext)
+		})
+
+		userRouter.GET("/data-requests", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchDataRequests(appContext)
+		})
+
+		userRouter.DELETE("/data-requests/:requestID", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"requestID": ctx.Param("requestID"),
+			}
+			controllers.CancelDataRequest(&appContext)
+		})
+		userRouter.POST("/identity-documents", ratelimiter.RateLimiter(6, 10, "-identity-documents"), middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.VerifyIdentityDocumentDTO
+			body.DocumentType = entities.IdentityDocumentType(ctx.PostForm("type"))
+			body.Number = ctx.PostForm("number")
+			if file, err := ctx.FormFile("document"); err == nil {
+				body.Image = file
+			}
+			appContext := interfaces.ApplicationContext[dto.VerifyIdentityDocumentDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			controllers.VerifyIdentityDocument(&appContext)
+		})
+
+		userRouter.GET("/identity-documents", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+			appContext, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			controllers.FetchIdentityVerifications(appContext)
+		})
 	}
 }
@@ -6,13 +6,14 @@ import (
 	"kego.com/application/controllers"
 	"kego.com/application/controllers/dto"
 	"kego.com/application/interfaces"
+	"kego.com/entities"
 	middlewares "kego.com/infrastructure/middleware"
 )
 
 func WalletRouter(router *gin.RouterGroup) {
 	walletRouter := router.Group("/wallet")
 	{
-		walletRouter.POST("/:businessID/payment/international/send", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		walletRouter.POST("/:businessID/payment/international/send", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.SendPaymentDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -31,7 +32,7 @@ func WalletRouter(router *gin.RouterGroup) {
 			controllers.InitiateBusinessInternationalPayment(&appContext)
 		})
 
-		walletRouter.POST("/:businessID/payment/local/send", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		walletRouter.POST("/:businessID/payment/local/send", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.SendPaymentDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -50,7 +51,7 @@ func WalletRouter(router *gin.RouterGroup) {
 			controllers.InitiateBusinessLocalPayment(&appContext)
 		})
 
-		walletRouter.POST("/:businessID/payment/local/fee", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		walletRouter.POST("/:businessID/payment/local/fee", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.SendPaymentDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -69,7 +70,7 @@ func WalletRouter(router *gin.RouterGroup) {
 			controllers.BusinessLocalPaymentFee(&appContext)
 		})
 
-		walletRouter.POST("/:businessID/payment/international/fee", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		walletRouter.POST("/:businessID/payment/international/fee", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.SendPaymentDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -88,7 +89,25 @@ func WalletRouter(router *gin.RouterGroup) {
 			controllers.BusinessInternationalPaymentFee(&appContext)
 		})
 		
-		walletRouter.POST("/:businessID/payment/local/verify-name", middlewares.AuthenticationMiddleware(false), func(ctx *gin.Context) {
+		walletRouter.POST("/:businessID/payment/international/quote", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			var body dto.InternationalPaymentQuoteDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			appContext := interfaces.ApplicationContext[dto.InternationalPaymentQuoteDTO]{
+				Keys: appContextAny.Keys,
+				Body: &body,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.BusinessInternationalPaymentQuote(&appContext)
+		})
+
+		walletRouter.POST("/:businessID/payment/local/verify-name", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.SendPayouts), func(ctx *gin.Context) {
 			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
 			var body dto.NameVerificationDTO
 			if err := ctx.ShouldBindJSON(&body); err != nil {
@@ -102,5 +121,17 @@ func WalletRouter(router *gin.RouterGroup) {
 			}
 			controllers.VerifyLocalAccountName(&appContext)
 		})
+
+		walletRouter.GET("/:businessID", middlewares.AuthenticationMiddleware(false), middlewares.BusinessMemberMiddleware(entities.ViewBalances), func(ctx *gin.Context) {
+			appContextAny, _ := ctx.MustGet("AppContext").(*interfaces.ApplicationContext[any])
+			appContext := interfaces.ApplicationContext[any]{
+				Keys: appContextAny.Keys,
+				Ctx: appContextAny.Ctx,
+			}
+			appContext.Param = map[string]any{
+				"businessID": ctx.Param("businessID"),
+			}
+			controllers.FetchBusinessWallet(&appContext)
+		})
 	}
 }
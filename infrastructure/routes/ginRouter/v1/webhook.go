@@ -0,0 +1,27 @@
+package authroutev1
+
+import (
+	"github.com/gin-gonic/gin"
+	apperrors "kego.com/application/appErrors"
+	"kego.com/application/interfaces"
+	"kego.com/application/webhook"
+)
+
+// WebhookRouter has the routes payment processors call to tell us how a payout ended.
+func WebhookRouter(router *gin.RouterGroup) {
+	webhookRouter := router.Group("/webhooks")
+	{
+		webhookRouter.POST("/flutterwave/transfers", func(ctx *gin.Context) {
+			var body webhook.TransferWebhookDTO
+			if err := ctx.ShouldBindJSON(&body); err != nil {
+				apperrors.ErrorProcessingPayload(ctx)
+				return
+			}
+			webhook.FlutterwaveTransferWebhook(&interfaces.ApplicationContext[webhook.TransferWebhookDTO]{
+				Ctx: ctx,
+				Body: &body,
+				Header: ctx.Request.Header,
+			})
+		})
+	}
+}
@@ -34,3 +34,16 @@ func (gr ginResponder)Respond(ctx interface{}, code int, message string, payload
 		}(),
 	})
 }
+
+func (gr ginResponder)RespondWithFile(ctx interface{}, path string, fileName string) {
+	ginCtx, ok := (ctx).(*gin.Context)
+    if !ok {
+		logger.Error(errors.New("could not transform *interface{} to gin.Context in serverResponse package"), logger.LoggerOptions{
+			Key: "payload",
+			Data: ctx,
+		})
+        return
+    }
+	ginCtx.Abort()
+	ginCtx.FileAttachment(path, fileName)
+}
@@ -3,4 +3,6 @@ package server_response
 type serverResponder interface{
 	// Used to send a JSON response to the client.
 	Respond(ctx interface{}, code int, message string, payload interface{}, errs []error)
+	// Used to send a file for the client to download.
+	RespondWithFile(ctx interface{}, path string, fileName string)
 }
\ No newline at end of file
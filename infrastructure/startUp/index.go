@@ -1,13 +1,20 @@
 package startup
 
 import (
+	"errors"
+
+	"kego.com/application/events"
+	"kego.com/application/services"
+	"kego.com/infrastructure/auth"
 	"kego.com/infrastructure/database"
 	"kego.com/infrastructure/database/connection/datastore"
 	fileupload "kego.com/infrastructure/file_upload"
 	identityverification "kego.com/infrastructure/identity_verification"
 	"kego.com/infrastructure/logger"
 	"kego.com/infrastructure/logger/metrics"
+	"kego.com/infrastructure/messaging/emails"
 	pushnotification "kego.com/infrastructure/messaging/push_notifications"
+	"kego.com/infrastructure/messaging/sms"
 	paymentprocessor "kego.com/infrastructure/payment_processor"
 )
 
@@ -17,13 +24,52 @@ func StartServices(){
 	logger.InitializeLogger()
 	// set up databases
 	database.SetUpDatabase()
+	// load the keys access tokens are signed with
+	if err := auth.InitialiseKeySet(); err != nil {
+		logger.Error(errors.New("could not load jwt keys"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		panic(err)
+	}
 	
 	metrics.MetricMonitor.Init()
 	fileupload.InitialiseFileUploader()
+	if err := emails.InitialiseEmailService(); err != nil {
+		logger.Error(errors.New("could not load email templates"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		panic(err)
+	}
 	pushnotification.InitialisePushNotificationService()
-	identityverification.InitialiseIdentityVerifier()
+	if err := sms.InitialiseSMSService(); err != nil {
+		logger.Error(errors.New("could not start sms service"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		panic(err)
+	}
+	if err := identityverification.InitialiseIdentityVerifier(); err != nil {
+		logger.Error(errors.New("could not start identity verification"), logger.LoggerOptions{
+			Key: "error",
+			Data: err,
+		})
+		panic(err)
+	}
 	paymentprocessor.LocalPaymentProcessor.InitialisePaymentProcessor()
 	paymentprocessor.InternationalPaymentProcessor.InitialisePaymentProcessor()
+	services.SeedDefaultPricingPlan()
+	// keep exchange rates cached and recorded in the background
+	services.StartExchangeRateRefresher()
+	// deliver notifications and other outbox events to their consumers
+	events.StartWorker()
+	// carry out data exports and erasures users have asked for
+	services.StartDataRequestWorker()
+	// cancel business payouts that were not approved in time
+	services.StartPayoutApprovalWorker()
+	// settle payouts whose processor callback never arrived
+	services.StartTransactionSettlementWorker()
 }
 
 // Used to clean up after services that have been shutdown.